@@ -1,8 +1,23 @@
 package main
 
-import "github.com/fire9900/forum/internal/app"
+import (
+	"github.com/fire9900/forum/internal/app"
+	"os"
+)
 
 func main() {
-	app.RunLogger(true)
-	app.RunMain()
+	args := os.Args[1:]
+	if len(args) > 0 {
+		switch args[0] {
+		case "integrity":
+			app.RunIntegrity(args[1:])
+			return
+		case "config":
+			app.RunConfig(args[1:])
+			return
+		}
+	}
+
+	cfg := app.LoadConfig(args)
+	app.RunMain(cfg, app.NewLogger(cfg.Log))
 }
@@ -1,34 +1,161 @@
 package app
 
 import (
+	"context"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/internal/metrics"
 	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/tracing"
 	"github.com/fire9900/forum/internal/transport/gin"
 	"github.com/fire9900/forum/internal/usecase"
 	"github.com/fire9900/forum/pkg/database"
-	"github.com/fire9900/forum/pkg/logger"
 	"github.com/fire9900/forum/pkg/wsserver"
 	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
-func RunMain() {
-	db, err := database.NewSQLiteConnection()
+// RunMain запускает HTTP-сервер форума с конфигурацией cfg и работает до
+// SIGINT или SIGTERM. После сигнала /readyz начинает отвечать 503, сервер
+// перестает принимать соединения и дожидается текущих запросов, затем
+// закрываются WebSocket-соединения, останавливаются фоновые задачи
+// и закрывается база. Остановка укладывается в http.shutdown_timeout.
+func RunMain(cfg config.Config, log *zap.Logger) {
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	lc := lifecycle{log: log}
+	// Провайдер трассировок останавливается последним и отправляет спаны,
+	// записанные во время остановки остальных компонентов.
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatal("Ошибка настройки трассировки",
+			zap.Error(err),
+			zap.String("component", "tracing"))
+	}
+	lc.onStop("tracing", shutdownTracing)
+
+	db, err := database.NewSQLiteConnection(cfg.Database.Path, cfg.Database.MaxOpenConns)
+	if err != nil {
+		log.Fatal("Ошибка подключения к базе данных",
+			zap.Error(err),
+			zap.String("component", "database"))
+	}
+	log.Info("Подключение к базе данных прошло успешно")
+	lc.onStop("database", func(context.Context) error {
+		return db.Close()
+	})
+
+	if err := database.RunMigrations(db); err != nil {
+		log.Fatal("Ошибка применения миграций",
+			zap.Error(err),
+			zap.String("component", "database"))
+	}
+	latestMigration, err := database.LatestMigration()
 	if err != nil {
-		logger.Logger.Fatal("Ошибка подключения к базе данных",
+		log.Fatal("Ошибка чтения миграций",
 			zap.Error(err),
 			zap.String("component", "database"))
 	}
-	logger.Logger.Info("Подключение к базе данных прошло успешно")
 
-	forumRepo := repository.NewForumRepository(db, logger.Logger)
-	p := usecase.NewPostUseCase(forumRepo)
-	t := usecase.NewThreadUseCase(forumRepo)
-	hub := wsserver.NewHub(p, logger.Logger)
+	forumMetrics := metrics.New()
+	forumRepo := repository.NewInstrumentedRepository(repository.NewForumRepository(db, log), forumMetrics)
+	p := usecase.NewPostUseCase(forumRepo, log)
+	t := usecase.NewThreadUseCase(forumRepo, log)
+	m := usecase.TraceModerationUseCase(usecase.NewModerationUseCase(forumRepo, log))
+	r := usecase.NewReportUseCase(forumRepo, log)
+	b := usecase.TraceBanUseCase(usecase.NewBanUseCase(forumRepo, log))
+	engine := newAutomod(&lc, log, cfg.Automod.RulesFile)
+	p.SetAutomod(engine)
+	t.SetAutomod(engine)
+	detector := newDedup(cfg.Dedup)
+	p.SetDedup(detector)
+	t.SetDedup(detector)
+	a := usecase.TraceAutomodUseCase(usecase.NewAutomodUseCase(forumRepo, engine, detector, log))
+	classifier := newSpamClassifier(ctx, log, forumRepo, cfg.Spam)
+	p.SetSpamClassifier(classifier)
+	r.SetSpamClassifier(classifier)
+	s := usecase.TraceSpamUseCase(usecase.NewSpamUseCase(forumRepo, classifier, log))
+	p.SetTrustThreshold(cfg.Premod.TrustThreshold)
+	t.SetTrustThreshold(cfg.Premod.TrustThreshold)
+	q := usecase.TracePremodUseCase(usecase.NewPremodUseCase(forumRepo, log))
+	l := usecase.TraceAuditUseCase(usecase.NewAuditUseCase(forumRepo, log))
+	d := usecase.TraceTrashUseCase(usecase.NewTrashUseCase(forumRepo, cfg.Trash.Retention(), log))
+	u := usecase.TracePreferenceUseCase(usecase.NewPreferenceUseCase(forumRepo, log))
+	posts := usecase.InstrumentPostUseCase(usecase.TracePostUseCase(p), forumMetrics)
+	threads := usecase.InstrumentThreadUseCase(usecase.TraceThreadUseCase(t), forumMetrics)
+	reports := usecase.TraceReportUseCase(r)
+	hub := wsserver.NewHub(posts, log)
+	hub.SetMetrics(forumMetrics)
+	limiter := newRateLimiter(&lc, log, cfg.Redis.Addr, cfg.RateLimit)
+	hub.SetRateLimiter(limiter)
+
+	lc.goroutine("ban-expiry", func(ctx context.Context) {
+		runBanExpiry(ctx, log, b, banExpiryInterval)
+	})
+	lc.goroutine("fingerprint-expiry", func(ctx context.Context) {
+		runFingerprintExpiry(ctx, log, a, fingerprintExpiryInterval)
+	})
+	lc.goroutine("trash-purge", func(ctx context.Context) {
+		runTrashPurge(ctx, log, d, trashPurgeInterval)
+	})
+
+	authClient := ClientStart(cfg.Auth.Addr, log)
+	lc.onStop("auth-client", func(context.Context) error {
+		authClient.Close()
+		return nil
+	})
 
-	router := gin.SetupRouter(p, t, ClientStart(), hub)
-	logger.Logger.Info("Сервер стартует на порту :7777")
-	if err := router.Run(":7777"); err != nil {
-		logger.Logger.Fatal("Ошибка запуска сервера",
+	// Хаб останавливается после HTTP-сервера: обработчики, которые еще
+	// выполняются, рассылают через него события.
+	lc.goroutine("websocket-hub", hub.Run)
+
+	checker := newHealthChecker(db, latestMigration, cfg.Auth.Addr, hub)
+	router := gin.SetupRouter(cfg.HTTP, cfg.I18n, posts, threads, m, reports, b, a, q, s, l, d, u, authClient, hub, limiter, checker, forumMetrics, log)
+	server := &http.Server{Addr: cfg.HTTP.Addr, Handler: router}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	lc.onStop("http-server", server.Shutdown)
+	// Первой при остановке /readyz начинает отвечать 503, и сервер еще
+	// http.shutdown_delay обслуживает запросы, пока балансировщик выводит
+	// экземпляр из ротации.
+	lc.onStop("readiness", func(ctx context.Context) error {
+		checker.SetShuttingDown()
+		select {
+		case <-time.After(cfg.HTTP.ShutdownDelay):
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+	log.Info("Сервер стартует", zap.String("addr", cfg.HTTP.Addr))
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Info("Получен сигнал остановки, завершение работы",
+			zap.Duration("timeout", cfg.HTTP.ShutdownTimeout))
+	case err := <-serverErr:
+		log.Error("Ошибка запуска сервера",
 			zap.Error(err),
 			zap.String("component", "http-server"))
+		exitCode = 1
+	}
+	// Повторный сигнал прерывает остановку.
+	stopSignals()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	defer cancel()
+	lc.stop(shutdownCtx)
+	log.Info("Сервер остановлен")
+	log.Sync()
+
+	if exitCode != 0 {
+		os.Exit(exitCode)
 	}
 }
@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/automod"
+	"go.uber.org/zap"
+	"time"
+)
+
+// automodReloadInterval - как часто проверяется изменение файла правил.
+const automodReloadInterval = 10 * time.Second
+
+// newAutomod создает движок автомодерации. Если задан path, правила читаются
+// из этого JSON-файла и перечитываются при его изменении, иначе движок
+// стартует без правил и настраивается через /admin/automod.
+func newAutomod(lc *lifecycle, log *zap.Logger, path string) *automod.Engine {
+	engine, _ := automod.NewEngine(automod.Config{})
+
+	if path == "" {
+		return engine
+	}
+
+	cfg, err := automod.LoadFile(path)
+	if err == nil {
+		err = engine.Load(cfg)
+	}
+	if err != nil {
+		log.Error("Ошибка загрузки правил автомодерации",
+			zap.String("path", path),
+			zap.Error(err))
+	} else {
+		log.Info("Правила автомодерации загружены",
+			zap.String("path", path),
+			zap.Int("rules", len(cfg.Rules)),
+			zap.Bool("dryRun", cfg.DryRun))
+	}
+
+	lc.goroutine("automod-watcher", func(ctx context.Context) {
+		engine.WatchFile(ctx, log, path, automodReloadInterval)
+	})
+	return engine
+}
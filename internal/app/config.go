@@ -0,0 +1,35 @@
+package app
+
+import (
+	"flag"
+	"fmt"
+	"github.com/fire9900/forum/internal/config"
+	"os"
+)
+
+// LoadConfig собирает конфигурацию сервера из args. При ошибке печатает ее
+// и завершает процесс с кодом 2, как при неверных флагах.
+func LoadConfig(args []string) config.Config {
+	cfg, err := config.Load(flag.NewFlagSet("forum", flag.ExitOnError), args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка конфигурации:", err)
+		os.Exit(2)
+	}
+	return cfg
+}
+
+// RunConfig выполняет команду config. Подкоманда print выводит итоговую
+// конфигурацию с учетом файла, переменных окружения и флагов.
+func RunConfig(args []string) {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Использование: forum config print [-config файл] [флаги]")
+		os.Exit(2)
+	}
+
+	out, err := LoadConfig(args[1:]).YAML()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка вывода конфигурации:", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(out)
+}
@@ -0,0 +1,19 @@
+package app
+
+import (
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/internal/dedup"
+)
+
+// newDedup создает детектор повторов с параметрами из конфигурации.
+func newDedup(cfg config.DedupConfig) *dedup.Detector {
+	return dedup.NewDetector(dedup.Config{
+		UserWindow:   cfg.UserWindow,
+		GlobalWindow: cfg.GlobalWindow,
+		MaxDistance:  cfg.MaxDistance,
+		MinWords:     cfg.MinWords,
+		UserAction:   cfg.UserAction,
+		GlobalAction: cfg.GlobalAction,
+		GlobalUsers:  cfg.GlobalUsers,
+	})
+}
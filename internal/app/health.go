@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/fire9900/forum/pkg/database"
+	"github.com/fire9900/forum/pkg/health"
+	"github.com/fire9900/forum/pkg/wsserver"
+	"net"
+	"time"
+)
+
+// healthCheckTimeout ограничивает каждую проверку пробы готовности.
+const healthCheckTimeout = 2 * time.Second
+
+// newHealthChecker собирает проверки готовности: связь с базой, версия ее
+// схемы, доступность сервиса авторизации и работа хаба WebSocket.
+func newHealthChecker(db *sql.DB, latestMigration uint, authAddr string, hub *wsserver.Hub) *health.Checker {
+	checker := health.NewChecker(healthCheckTimeout)
+	checker.Add("database", db.PingContext)
+	checker.Add("migrations", func(ctx context.Context) error {
+		return database.CheckMigrations(ctx, db, latestMigration)
+	})
+	// Клиент gRPC подключается лениво и не сообщает о состоянии соединения,
+	// поэтому доступность сервиса проверяется TCP-подключением.
+	checker.Add("auth", func(ctx context.Context) error {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "tcp", authAddr)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+	checker.Add("websocket-hub", func(context.Context) error {
+		if !hub.Running() {
+			return errors.New("хаб не запущен")
+		}
+		return nil
+	})
+	return checker
+}
@@ -0,0 +1,67 @@
+package app
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/database"
+	"go.uber.org/zap"
+	"os"
+)
+
+// RunIntegrity выполняет команду integrity: ищет посты и строки чата,
+// ссылающиеся на удаленные треды и посты, а с флагом -repair удаляет их.
+// Если висячие ссылки найдены и не исправлены, процесс завершается с кодом 1.
+func RunIntegrity(args []string) {
+	flags := flag.NewFlagSet("integrity", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "удалить найденные висячие ссылки")
+	cfg, err := config.Load(flags, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка конфигурации:", err)
+		os.Exit(2)
+	}
+	log := NewLogger(cfg.Log)
+	defer log.Sync()
+
+	db, err := database.NewSQLiteConnection(cfg.Database.Path, cfg.Database.MaxOpenConns)
+	if err != nil {
+		log.Fatal("Ошибка подключения к базе данных",
+			zap.Error(err),
+			zap.String("component", "database"))
+	}
+	defer db.Close()
+
+	if err := database.RunMigrations(db); err != nil {
+		log.Fatal("Ошибка применения миграций",
+			zap.Error(err),
+			zap.String("component", "database"))
+	}
+
+	forumRepo := repository.NewForumRepository(db, log)
+	check := forumRepo.CheckIntegrity
+	if *repair {
+		check = forumRepo.RepairIntegrity
+	}
+	report, err := check(context.Background())
+	if err != nil {
+		log.Fatal("Ошибка проверки целостности", zap.Error(err))
+	}
+
+	var found int64
+	for _, orphans := range report {
+		fmt.Printf("%s.%s -> %s: %d\n", orphans.Table, orphans.Column, orphans.Parent, orphans.Count)
+		found += orphans.Count
+	}
+	switch {
+	case found == 0:
+		fmt.Println("Висячих ссылок нет")
+	case *repair:
+		fmt.Printf("Удалено строк: %d\n", found)
+	default:
+		fmt.Printf("Найдено висячих ссылок: %d, для удаления запустите integrity -repair\n", found)
+		db.Close()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/usecase"
+	"go.uber.org/zap"
+	"time"
+)
+
+// runPeriodic вызывает fn раз в interval, пока не отменен ctx. Ошибки fn
+// пишутся в лог с сообщением name и не прерывают задачу.
+func runPeriodic(ctx context.Context, log *zap.Logger, interval time.Duration, name string, fn func(context.Context) error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := fn(ctx); err != nil {
+			log.Error(name, zap.Error(err))
+		}
+	}
+}
+
+// banExpiryInterval - период удаления истекших блокировок.
+const banExpiryInterval = time.Minute
+
+// runBanExpiry периодически удаляет истекшие блокировки, пока не отменен ctx.
+// Проверки доступа сами учитывают срок блокировки, задача лишь очищает таблицу.
+func runBanExpiry(ctx context.Context, log *zap.Logger, b usecase.BanUseCase, interval time.Duration) {
+	runPeriodic(ctx, log, interval, "Ошибка удаления истекших блокировок", func(ctx context.Context) error {
+		_, err := b.ExpireBans(ctx)
+		return err
+	})
+}
+
+// fingerprintExpiryInterval - период удаления устаревших отпечатков сообщений.
+const fingerprintExpiryInterval = time.Hour
+
+// runFingerprintExpiry периодически удаляет отпечатки, вышедшие за окна
+// поиска повторов.
+func runFingerprintExpiry(ctx context.Context, log *zap.Logger, a usecase.AutomodUseCase, interval time.Duration) {
+	runPeriodic(ctx, log, interval, "Ошибка удаления устаревших отпечатков", func(ctx context.Context) error {
+		_, err := a.ExpireFingerprints(ctx)
+		return err
+	})
+}
+
+// trashPurgeInterval - период очистки корзины.
+const trashPurgeInterval = time.Hour
+
+// runTrashPurge периодически стирает сообщения, срок хранения которых
+// в корзине истек.
+func runTrashPurge(ctx context.Context, log *zap.Logger, d usecase.TrashUseCase, interval time.Duration) {
+	runPeriodic(ctx, log, interval, "Ошибка очистки корзины", func(ctx context.Context) error {
+		_, err := d.PurgeTrash(ctx)
+		return err
+	})
+}
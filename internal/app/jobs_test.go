@@ -0,0 +1,42 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func TestRunPeriodic(t *testing.T) {
+	core, logs := observer.New(zapcore.ErrorLevel)
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		runPeriodic(ctx, zap.New(core), time.Millisecond, "Ошибка задачи", func(context.Context) error {
+			calls++
+			if calls == 3 {
+				cancel()
+			}
+			return errors.New("ошибка")
+		})
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("задача не остановилась после отмены контекста")
+	}
+
+	if calls < 3 {
+		t.Errorf("ожидалось не меньше 3 вызовов, получено %d", calls)
+	}
+	if n := logs.FilterMessage("Ошибка задачи").Len(); n != calls {
+		t.Errorf("ожидалось %d записей об ошибке, получено %d", calls, n)
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"context"
+	"go.uber.org/zap"
+)
+
+// component - запущенная часть приложения и способ ее остановить.
+type component struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+// lifecycle хранит запущенные компоненты и останавливает их в обратном
+// порядке: сначала перестает приниматься работа, затем завершаются фоновые
+// задачи и в последнюю очередь закрываются ресурсы, которыми они пользуются.
+type lifecycle struct {
+	log        *zap.Logger
+	components []component
+}
+
+// onStop регистрирует остановку уже запущенного компонента.
+func (l *lifecycle) onStop(name string, stop func(ctx context.Context) error) {
+	l.components = append(l.components, component{name: name, stop: stop})
+}
+
+// goroutine запускает фоновую задачу run. При остановке ее контекст
+// отменяется и lifecycle ждет возврата из run.
+func (l *lifecycle) goroutine(name string, run func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(ctx)
+	}()
+
+	l.onStop(name, func(stopCtx context.Context) error {
+		cancel()
+		select {
+		case <-done:
+			return nil
+		case <-stopCtx.Done():
+			return stopCtx.Err()
+		}
+	})
+}
+
+// stop останавливает компоненты в порядке, обратном запуску. Ошибка одного
+// компонента не мешает остановке остальных. После истечения ctx оставшиеся
+// компоненты получают уже отмененный контекст и должны завершиться сразу.
+func (l *lifecycle) stop(ctx context.Context) {
+	for i := len(l.components) - 1; i >= 0; i-- {
+		c := l.components[i]
+		if err := c.stop(ctx); err != nil {
+			l.log.Error("Ошибка остановки компонента",
+				zap.String("component", c.name),
+				zap.Error(err))
+			continue
+		}
+		l.log.Info("Компонент остановлен", zap.String("component", c.name))
+	}
+	l.components = nil
+}
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLifecycleStopsInReverseOrder(t *testing.T) {
+	lc := lifecycle{log: zap.NewNop()}
+	var stopped []string
+	record := func(name string) {
+		lc.onStop(name, func(context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		})
+	}
+	record("database")
+	lc.goroutine("job", func(ctx context.Context) {
+		<-ctx.Done()
+		stopped = append(stopped, "job")
+	})
+	lc.onStop("failing", func(context.Context) error {
+		return errors.New("ошибка")
+	})
+	record("http-server")
+
+	lc.stop(context.Background())
+
+	if want := []string{"http-server", "job", "database"}; !reflect.DeepEqual(stopped, want) {
+		t.Errorf("ожидался порядок остановки %v, получено %v", want, stopped)
+	}
+}
+
+func TestLifecycleGoroutineTimeout(t *testing.T) {
+	lc := lifecycle{log: zap.NewNop()}
+	release := make(chan struct{})
+	defer close(release)
+	lc.goroutine("stuck", func(context.Context) {
+		<-release
+	})
+	dbClosed := false
+	lc.onStop("database", func(context.Context) error {
+		dbClosed = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	lc.stop(ctx)
+
+	if !dbClosed {
+		t.Error("зависшая задача не должна мешать остановке остальных компонентов")
+	}
+}
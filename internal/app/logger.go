@@ -1,12 +1,32 @@
 package app
 
-import "github.com/fire9900/forum/pkg/logger"
+import (
+	"fmt"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"os"
+)
 
-func RunLogger(choose bool) {
-	if err := logger.InitLogger(choose); err != nil {
-		panic(err)
+// NewLogger создает логгер сервиса по cfg. Без логгера сообщать об ошибках
+// некуда, поэтому ошибка печатается в stderr и процесс завершается с кодом 2.
+// Отправить буферизованные записи (Sync) должен вызывающий перед выходом.
+func NewLogger(cfg config.LogConfig) *zap.Logger {
+	log, err := logger.InitLogger(logger.Config{
+		Level:      cfg.Level,
+		Format:     cfg.Format,
+		Language:   cfg.Language,
+		File:       cfg.File,
+		ErrorFile:  cfg.ErrorFile,
+		MaxSizeMB:  cfg.MaxSizeMB,
+		MaxBackups: cfg.MaxBackups,
+		MaxAgeDays: cfg.MaxAgeDays,
+		Compress:   cfg.Compress,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Ошибка настройки логгера:", err)
+		os.Exit(2)
 	}
-	defer logger.Logger.Sync()
-
-	logger.Logger.Info("Логгер запущен на микросервисе forum-client")
+	log.Info("Логгер запущен на микросервисе forum-client")
+	return log
 }
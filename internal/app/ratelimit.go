@@ -0,0 +1,31 @@
+package app
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/pkg/ratelimit"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"time"
+)
+
+// newRateLimiter создает ограничитель частоты запросов с лимитами из cfg. Если
+// задан адрес addr, лимиты хранятся в Redis и общие для всех экземпляров
+// сервиса, иначе - в памяти.
+func newRateLimiter(lc *lifecycle, log *zap.Logger, addr string, cfg config.RateLimitConfig) *ratelimit.Limiter {
+	limits := ratelimit.Config{Endpoints: cfg.Endpoints}
+	if addr != "" {
+		client := redis.NewClient(&redis.Options{Addr: addr})
+		lc.onStop("redis", func(context.Context) error {
+			return client.Close()
+		})
+		log.Info("Лимиты запросов хранятся в Redis", zap.String("addr", addr))
+		return ratelimit.NewLimiter(ratelimit.NewRedisStore(client, "forum:ratelimit:"), limits)
+	}
+
+	store := ratelimit.NewMemoryStore()
+	lc.goroutine("ratelimit-cleanup", func(ctx context.Context) {
+		store.RunCleanup(ctx, 5*time.Minute, 10*time.Minute)
+	})
+	return ratelimit.NewLimiter(store, limits)
+}
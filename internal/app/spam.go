@@ -0,0 +1,30 @@
+package app
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/spam"
+	"go.uber.org/zap"
+)
+
+// newSpamClassifier создает классификатор спама из накопленной в базе
+// статистики с порогами из конфигурации.
+func newSpamClassifier(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, cfg config.SpamConfig) *spam.Classifier {
+	classifierCfg := spam.DefaultConfig()
+	classifierCfg.FlagThreshold = cfg.FlagThreshold
+	classifierCfg.HoldThreshold = cfg.HoldThreshold
+
+	model, err := repo.GetSpamModel(ctx)
+	if err != nil {
+		log.Error("Ошибка загрузки классификатора спама, обучение начнется заново", zap.Error(err))
+		model = models.SpamModel{}
+	}
+
+	log.Info("Классификатор спама загружен",
+		zap.Int("spam", model.Docs.Spam),
+		zap.Int("ham", model.Docs.Ham),
+		zap.Int("tokens", len(model.Tokens)))
+	return spam.NewClassifier(classifierCfg, model)
+}
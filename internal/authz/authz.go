@@ -0,0 +1,123 @@
+package authz
+
+import (
+	"github.com/fire9900/forum/internal/models"
+)
+
+type Permission string
+
+const (
+	PostDeleteOwn   Permission = "post.delete.own"
+	PostDeleteAny   Permission = "post.delete.any"
+	ThreadEditOwn   Permission = "thread.edit.own"
+	ThreadEditAny   Permission = "thread.edit.any"
+	ThreadDeleteOwn Permission = "thread.delete.own"
+	ThreadDeleteAny Permission = "thread.delete.any"
+	ThreadLock      Permission = "thread.lock"
+	ThreadMerge     Permission = "thread.merge"
+	ThreadSplit     Permission = "thread.split"
+	ThreadMove      Permission = "thread.move"
+	CategoryManage  Permission = "category.manage"
+	ReportManage    Permission = "report.manage"
+	UserBan         Permission = "user.ban"
+	AutomodManage   Permission = "automod.manage"
+	ContentApprove  Permission = "content.approve"
+	SpamTrain       Permission = "spam.train"
+	AuditView       Permission = "audit.view"
+)
+
+// Resource описывает объект, над которым выполняется действие.
+// Нулевые поля означают, что у объекта нет владельца или категории.
+type Resource struct {
+	OwnerID    int
+	CategoryID int
+}
+
+// ownPermissions сопоставляет право на любой объект с правом на собственный.
+var ownPermissions = map[Permission]Permission{
+	PostDeleteAny:   PostDeleteOwn,
+	ThreadEditAny:   ThreadEditOwn,
+	ThreadDeleteAny: ThreadDeleteOwn,
+}
+
+var userPermissions = []Permission{
+	PostDeleteOwn,
+	ThreadEditOwn,
+	ThreadDeleteOwn,
+}
+
+// categoryPermissions выдаются модератору категории в пределах этой категории.
+var categoryPermissions = []Permission{
+	PostDeleteAny,
+	ThreadEditAny,
+	ThreadDeleteAny,
+	ThreadLock,
+	ThreadSplit,
+	UserBan,
+	ContentApprove,
+	SpamTrain,
+}
+
+var moderatorPermissions = append(append([]Permission{}, userPermissions...),
+	PostDeleteAny,
+	ThreadEditAny,
+	ThreadDeleteAny,
+	ThreadLock,
+	ThreadMerge,
+	ThreadSplit,
+	ThreadMove,
+	ReportManage,
+	UserBan,
+	ContentApprove,
+	SpamTrain,
+)
+
+var adminPermissions = append(append([]Permission{}, moderatorPermissions...),
+	CategoryManage,
+	AutomodManage,
+	AuditView,
+)
+
+var rolePermissions = map[string][]Permission{
+	models.RoleUser:      userPermissions,
+	models.RoleModerator: moderatorPermissions,
+	models.RoleAdmin:     adminPermissions,
+}
+
+// HasPermission сообщает, выдано ли право роли.
+func HasPermission(role string, permission Permission) bool {
+	return contains(rolePermissions[role], permission)
+}
+
+// Authorize проверяет, может ли actor выполнить action над resource.
+// action задается правом на любой объект: если оно не выдано, для владельца
+// ресурса проверяется соответствующее право .own, а для модератора категории —
+// права модератора в этой категории. При отказе возвращает models.ErrorForbidden.
+func Authorize(actor models.Actor, action Permission, resource Resource) error {
+	if HasPermission(actor.Role, action) {
+		return nil
+	}
+
+	if own, ok := ownPermissions[action]; ok &&
+		actor.ID != 0 && actor.ID == resource.OwnerID &&
+		HasPermission(actor.Role, own) {
+		return nil
+	}
+
+	if resource.CategoryID != 0 &&
+		contains(categoryPermissions, action) &&
+		actor.Moderates(resource.CategoryID) {
+		return nil
+	}
+
+	return models.ErrorForbidden
+}
+
+func contains(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
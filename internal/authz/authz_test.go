@@ -0,0 +1,84 @@
+package authz
+
+import (
+	"errors"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	admin := models.Actor{ID: 1, Role: models.RoleAdmin}
+	moderator := models.Actor{ID: 2, Role: models.RoleModerator}
+	user := models.Actor{ID: 3, Role: models.RoleUser}
+	categoryMod := models.Actor{ID: 4, Role: models.RoleUser, Categories: []int{10}}
+	unknown := models.Actor{ID: 5, Role: "guest"}
+
+	own := Resource{OwnerID: 3, CategoryID: 10}
+	foreign := Resource{OwnerID: 9, CategoryID: 10}
+	otherCategory := Resource{OwnerID: 9, CategoryID: 20}
+	global := Resource{}
+
+	tests := []struct {
+		name     string
+		actor    models.Actor
+		action   Permission
+		resource Resource
+		allowed  bool
+	}{
+		{"admin deletes foreign post", admin, PostDeleteAny, foreign, true},
+		{"admin manages categories", admin, CategoryManage, global, true},
+		{"admin manages automod", admin, AutomodManage, global, true},
+		{"admin merges threads", admin, ThreadMerge, global, true},
+		{"admin views audit log", admin, AuditView, global, true},
+
+		{"moderator deletes foreign post", moderator, PostDeleteAny, foreign, true},
+		{"moderator locks thread", moderator, ThreadLock, otherCategory, true},
+		{"moderator moves thread", moderator, ThreadMove, global, true},
+		{"moderator manages reports", moderator, ReportManage, global, true},
+		{"moderator bans forum-wide", moderator, UserBan, global, true},
+		{"moderator approves content", moderator, ContentApprove, otherCategory, true},
+		{"moderator cannot manage categories", moderator, CategoryManage, global, false},
+		{"moderator cannot manage automod", moderator, AutomodManage, global, false},
+		{"moderator cannot view audit log", moderator, AuditView, global, false},
+
+		{"user deletes own post", user, PostDeleteAny, own, true},
+		{"user edits own thread", user, ThreadEditAny, own, true},
+		{"user deletes own thread", user, ThreadDeleteAny, own, true},
+		{"user cannot delete foreign post", user, PostDeleteAny, foreign, false},
+		{"user cannot edit foreign thread", user, ThreadEditAny, foreign, false},
+		{"user cannot lock own thread", user, ThreadLock, own, false},
+		{"user cannot merge threads", user, ThreadMerge, global, false},
+		{"user cannot manage reports", user, ReportManage, global, false},
+		{"user cannot approve own content", user, ContentApprove, own, false},
+		{"user cannot train spam filter", user, SpamTrain, own, false},
+
+		{"category moderator deletes post in category", categoryMod, PostDeleteAny, foreign, true},
+		{"category moderator locks thread in category", categoryMod, ThreadLock, foreign, true},
+		{"category moderator splits thread in category", categoryMod, ThreadSplit, foreign, true},
+		{"category moderator cannot act in other category", categoryMod, PostDeleteAny, otherCategory, false},
+		{"category moderator cannot move threads", categoryMod, ThreadMove, foreign, false},
+		{"category moderator cannot merge threads", categoryMod, ThreadMerge, foreign, false},
+		{"category moderator bans in category", categoryMod, UserBan, Resource{CategoryID: 10}, true},
+		{"category moderator cannot ban forum-wide", categoryMod, UserBan, global, false},
+		{"user cannot ban", user, UserBan, Resource{CategoryID: 10}, false},
+		{"category moderator cannot manage reports", categoryMod, ReportManage, global, false},
+		{"category moderator approves content in category", categoryMod, ContentApprove, foreign, true},
+		{"category moderator cannot approve in other category", categoryMod, ContentApprove, otherCategory, false},
+		{"category moderator trains spam filter in category", categoryMod, SpamTrain, foreign, true},
+
+		{"unknown role denied", unknown, PostDeleteAny, Resource{OwnerID: 5}, false},
+		{"zero actor is not owner of unowned resource", models.Actor{Role: models.RoleUser}, PostDeleteAny, global, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Authorize(tt.actor, tt.action, tt.resource)
+			if tt.allowed && err != nil {
+				t.Errorf("ожидался доступ, получено %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, models.ErrorForbidden) {
+				t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorForbidden, err)
+			}
+		})
+	}
+}
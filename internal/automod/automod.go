@@ -0,0 +1,186 @@
+// Package automod проверяет новые посты и треды настраиваемыми правилами:
+// списками слов и выражений, лимитом ссылок для новых пользователей,
+// капсом и повторами символов.
+package automod
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+// Content - проверяемое сообщение. AuthorPosts - число опубликованных
+// сообщений автора, нужно только правилам links с NewAccountPosts.
+type Content struct {
+	Text        string
+	AuthorPosts int
+}
+
+// Match - сработавшее правило.
+type Match struct {
+	Rule   string `json:"rule"`
+	Kind   Kind   `json:"kind"`
+	Action Action `json:"action"`
+}
+
+// Decision - итог проверки. Action пуст, если ни одно правило не сработало.
+// Text содержит сообщение после замен. В режиме DryRun Action и Text
+// заполняются так же, но применять их не нужно.
+type Decision struct {
+	Action  Action
+	Text    string
+	Matches []Match
+	DryRun  bool
+}
+
+// Enforced сообщает, нужно ли применять решение.
+func (d Decision) Enforced() bool {
+	return d.Action != "" && !d.DryRun
+}
+
+// Stronger возвращает более строгое из двух действий.
+func Stronger(a, b Action) Action {
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
+
+// Engine хранит действующий набор правил. Правила можно заменить на лету
+// через Load, проверки при этом не блокируются дольше замены указателя.
+type Engine struct {
+	mu     sync.RWMutex
+	config Config
+	rules  []compiledRule
+}
+
+// NewEngine создает движок с правилами cfg.
+func NewEngine(cfg Config) (*Engine, error) {
+	e := &Engine{}
+	if err := e.Load(cfg); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Load проверяет и применяет новый набор правил. При ошибке продолжают
+// действовать прежние правила.
+func (e *Engine) Load(cfg Config) error {
+	rules := make([]compiledRule, 0, len(cfg.Rules))
+	names := make(map[string]struct{}, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		c, err := compile(rule)
+		if err != nil {
+			return err
+		}
+		if _, dup := names[rule.Name]; dup {
+			return fmt.Errorf("%w: %q: имя правила повторяется", models.ErrorInvalidAutomodRule, rule.Name)
+		}
+		names[rule.Name] = struct{}{}
+		rules = append(rules, c)
+	}
+
+	e.mu.Lock()
+	e.config = cfg
+	e.rules = rules
+	e.mu.Unlock()
+	return nil
+}
+
+// Config возвращает действующий набор правил.
+func (e *Engine) Config() Config {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.config
+}
+
+// NeedsAuthorPosts сообщает, нужно ли заполнять Content.AuthorPosts.
+func (e *Engine) NeedsAuthorPosts() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, r := range e.rules {
+		if r.Kind == KindLinks && r.NewAccountPosts > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Check применяет к сообщению все правила.
+func (e *Engine) Check(content Content) Decision {
+	e.mu.RLock()
+	rules, dryRun := e.rules, e.config.DryRun
+	e.mu.RUnlock()
+
+	d := Decision{Text: content.Text, DryRun: dryRun}
+	for _, r := range rules {
+		replaced, ok := r.match(d.Text, content.AuthorPosts)
+		if !ok {
+			continue
+		}
+		d.Matches = append(d.Matches, Match{Rule: r.Name, Kind: r.Kind, Action: r.Action})
+		if r.Action == ActionReplace {
+			d.Text = replaced
+		}
+		d.Action = Stronger(d.Action, r.Action)
+	}
+	return d
+}
+
+// LoadFile читает набор правил из JSON-файла.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("Ошибка чтения правил автомодерации: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("%w: %v", models.ErrorInvalidAutomodRule, err)
+	}
+	return cfg, nil
+}
+
+// WatchFile перечитывает правила из path каждые interval, если файл
+// изменился. Некорректный файл не применяется, ошибка логируется в log.
+func (e *Engine) WatchFile(ctx context.Context, log *zap.Logger, path string, interval time.Duration) {
+	var modTime time.Time
+	if info, err := os.Stat(path); err == nil {
+		modTime = info.ModTime()
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().After(modTime) {
+			continue
+		}
+		modTime = info.ModTime()
+
+		cfg, err := LoadFile(path)
+		if err == nil {
+			err = e.Load(cfg)
+		}
+		if err != nil {
+			log.Error("Ошибка перезагрузки правил автомодерации",
+				zap.String("path", path),
+				zap.Error(err))
+			continue
+		}
+		log.Info("Правила автомодерации перезагружены",
+			zap.String("path", path),
+			zap.Int("rules", len(cfg.Rules)),
+			zap.Bool("dryRun", cfg.DryRun))
+	}
+}
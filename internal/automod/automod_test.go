@@ -0,0 +1,137 @@
+package automod
+
+import (
+	"errors"
+	"github.com/fire9900/forum/internal/models"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		rule       Rule
+		content    Content
+		wantAction Action
+		wantText   string
+	}{
+		{"word matches case-insensitive", Rule{Kind: KindWords, Action: ActionReject, Words: []string{"спам"}},
+			Content{Text: "Это СПАМ!"}, ActionReject, "Это СПАМ!"},
+		{"word inside another word", Rule{Kind: KindWords, Action: ActionReject, Words: []string{"спам"}},
+			Content{Text: "антиспам"}, "", "антиспам"},
+		{"word replaced", Rule{Kind: KindWords, Action: ActionReplace, Words: []string{"дурак"}},
+			Content{Text: "сам дурак"}, ActionReplace, "сам ***"},
+		{"regex", Rule{Kind: KindRegex, Action: ActionHold, Pattern: `(?i)казино`},
+			Content{Text: "Лучшее КАЗИНО"}, ActionHold, "Лучшее КАЗИНО"},
+		{"links over limit for new account", Rule{Kind: KindLinks, Action: ActionFlag, MaxLinks: 1, NewAccountPosts: 5},
+			Content{Text: "https://a.io www.b.io", AuthorPosts: 2}, ActionFlag, "https://a.io www.b.io"},
+		{"links for trusted account", Rule{Kind: KindLinks, Action: ActionFlag, MaxLinks: 1, NewAccountPosts: 5},
+			Content{Text: "https://a.io www.b.io", AuthorPosts: 5}, "", "https://a.io www.b.io"},
+		{"links within limit", Rule{Kind: KindLinks, Action: ActionFlag, MaxLinks: 1},
+			Content{Text: "https://a.io"}, "", "https://a.io"},
+		{"caps lowered", Rule{Kind: KindCaps, Action: ActionReplace},
+			Content{Text: "ПОЧЕМУ НИКТО НЕ ОТВЕЧАЕТ"}, ActionReplace, "почему никто не отвечает"},
+		{"short caps ignored", Rule{Kind: KindCaps, Action: ActionReplace},
+			Content{Text: "OK NASA"}, "", "OK NASA"},
+		{"repeat collapsed", Rule{Kind: KindRepeat, Action: ActionReplace, MaxRepeat: 3},
+			Content{Text: "ураааааа!!!!!"}, ActionReplace, "урааа!!!"},
+		{"repeated spaces ignored", Rule{Kind: KindRepeat, Action: ActionReplace, MaxRepeat: 3},
+			Content{Text: "a      b"}, "", "a      b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Name = "rule"
+			e, err := NewEngine(Config{Rules: []Rule{tt.rule}})
+			if err != nil {
+				t.Fatalf("NewEngine() error = %v", err)
+			}
+
+			d := e.Check(tt.content)
+			if d.Action != tt.wantAction || d.Text != tt.wantText {
+				t.Errorf("Check() = %q, %q, want %q, %q", d.Action, d.Text, tt.wantAction, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestCheckStrongestAction(t *testing.T) {
+	e, err := NewEngine(Config{Rules: []Rule{
+		{Name: "replace", Kind: KindWords, Action: ActionReplace, Words: []string{"дурак"}},
+		{Name: "hold", Kind: KindRegex, Action: ActionHold, Pattern: "казино"},
+		{Name: "flag", Kind: KindLinks, Action: ActionFlag},
+	}})
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+
+	d := e.Check(Content{Text: "дурак, иди в казино"})
+	if d.Action != ActionHold || d.Text != "***, иди в казино" || len(d.Matches) != 2 {
+		t.Errorf("Check() = %+v", d)
+	}
+	if !d.Enforced() {
+		t.Error("Enforced() = false, want true")
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	e, _ := NewEngine(Config{DryRun: true, Rules: []Rule{
+		{Name: "bad", Kind: KindWords, Action: ActionReject, Words: []string{"спам"}},
+	}})
+
+	d := e.Check(Content{Text: "спам"})
+	if d.Action != ActionReject || d.Enforced() {
+		t.Errorf("Check() = %+v, want reject not enforced", d)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	valid := Rule{Name: "bad", Kind: KindWords, Action: ActionReject, Words: []string{"спам"}}
+	e, _ := NewEngine(Config{Rules: []Rule{valid}})
+
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"no name", Rule{Kind: KindWords, Action: ActionReject, Words: []string{"a"}}},
+		{"unknown kind", Rule{Name: "x", Kind: "magic", Action: ActionReject}},
+		{"unknown action", Rule{Name: "x", Kind: KindLinks, Action: "ban"}},
+		{"empty words", Rule{Name: "x", Kind: KindWords, Action: ActionReject}},
+		{"bad regex", Rule{Name: "x", Kind: KindRegex, Action: ActionReject, Pattern: "("}},
+		{"bad caps ratio", Rule{Name: "x", Kind: KindCaps, Action: ActionReject, CapsRatio: 2}},
+		{"duplicate name", valid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Load(Config{Rules: []Rule{valid, tt.rule}})
+			if !errors.Is(err, models.ErrorInvalidAutomodRule) {
+				t.Errorf("Load() error = %v, want %v", err, models.ErrorInvalidAutomodRule)
+			}
+			if len(e.Config().Rules) != 1 {
+				t.Error("прежние правила должны остаться в силе")
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "automod.json")
+	if err := os.WriteFile(path, []byte(`{"dry_run": true, "rules": [{"name": "caps", "kind": "caps", "action": "flag"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if !cfg.DryRun || len(cfg.Rules) != 1 || cfg.Rules[0].Kind != KindCaps {
+		t.Errorf("LoadFile() = %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte(`{`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFile(path); !errors.Is(err, models.ErrorInvalidAutomodRule) {
+		t.Errorf("LoadFile() error = %v, want %v", err, models.ErrorInvalidAutomodRule)
+	}
+}
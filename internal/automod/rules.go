@@ -0,0 +1,236 @@
+package automod
+
+import (
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Action - действие над сообщением, подпавшим под правило.
+type Action string
+
+const (
+	// ActionReplace заменяет найденный фрагмент и пропускает сообщение.
+	ActionReplace Action = "replace"
+	// ActionFlag публикует сообщение и отправляет его в очередь модерации.
+	ActionFlag Action = "flag"
+	// ActionHold скрывает сообщение от всех, кроме автора, до проверки модератором.
+	ActionHold Action = "hold"
+	// ActionReject отклоняет сообщение.
+	ActionReject Action = "reject"
+)
+
+// severity упорядочивает действия: при срабатывании нескольких правил
+// применяется самое строгое.
+var severity = map[Action]int{
+	ActionReplace: 1,
+	ActionFlag:    2,
+	ActionHold:    3,
+	ActionReject:  4,
+}
+
+// Kind - тип проверки правила.
+type Kind string
+
+const (
+	KindWords  Kind = "words"
+	KindRegex  Kind = "regex"
+	KindLinks  Kind = "links"
+	KindCaps   Kind = "caps"
+	KindRepeat Kind = "repeat"
+)
+
+// Значения по умолчанию для незаданных параметров правил.
+const (
+	DefaultReplacement = "***"
+	DefaultCapsRatio   = 0.7
+	DefaultCapsLetters = 10
+	DefaultMaxRepeat   = 4
+)
+
+// Rule описывает одно правило автомодерации. Набор используемых полей
+// зависит от Kind:
+//   - words: Words - запрещенные слова без учета регистра;
+//   - regex: Pattern - регулярное выражение;
+//   - links: MaxLinks - допустимое число ссылок, NewAccountPosts - правило
+//     действует, только пока у автора меньше указанного числа сообщений (0 - всегда);
+//   - caps: CapsRatio - доля заглавных букв, MinLetters - минимальная длина текста в буквах;
+//   - repeat: MaxRepeat - сколько раз подряд допустим один символ.
+//
+// Replacement используется действием replace.
+type Rule struct {
+	Name            string   `json:"name"`
+	Kind            Kind     `json:"kind"`
+	Action          Action   `json:"action"`
+	Words           []string `json:"words,omitempty"`
+	Pattern         string   `json:"pattern,omitempty"`
+	MaxLinks        int      `json:"max_links,omitempty"`
+	NewAccountPosts int      `json:"new_account_posts,omitempty"`
+	CapsRatio       float64  `json:"caps_ratio,omitempty"`
+	MinLetters      int      `json:"min_letters,omitempty"`
+	MaxRepeat       int      `json:"max_repeat,omitempty"`
+	Replacement     string   `json:"replacement,omitempty"`
+}
+
+// Config - набор правил. В режиме DryRun решения только логируются.
+type Config struct {
+	DryRun bool   `json:"dry_run"`
+	Rules  []Rule `json:"rules"`
+}
+
+var (
+	wordPattern = regexp.MustCompile(`[\p{L}\p{N}]+`)
+	linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)[^\s<>"]+`)
+)
+
+// compiledRule - правило с подготовленными для проверки данными.
+type compiledRule struct {
+	Rule
+	words   map[string]struct{}
+	pattern *regexp.Regexp
+}
+
+func compile(rule Rule) (compiledRule, error) {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %q: %s", models.ErrorInvalidAutomodRule, rule.Name, fmt.Sprintf(format, args...))
+	}
+
+	if rule.Name == "" {
+		return compiledRule{}, fmt.Errorf("%w: не задано имя", models.ErrorInvalidAutomodRule)
+	}
+	if _, ok := severity[rule.Action]; !ok {
+		return compiledRule{}, invalid("неизвестное действие %q", rule.Action)
+	}
+	if rule.Replacement == "" {
+		rule.Replacement = DefaultReplacement
+	}
+
+	c := compiledRule{Rule: rule}
+	switch rule.Kind {
+	case KindWords:
+		if len(rule.Words) == 0 {
+			return compiledRule{}, invalid("пустой список слов")
+		}
+		c.words = make(map[string]struct{}, len(rule.Words))
+		for _, w := range rule.Words {
+			c.words[strings.ToLower(w)] = struct{}{}
+		}
+	case KindRegex:
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil || rule.Pattern == "" {
+			return compiledRule{}, invalid("некорректное выражение %q", rule.Pattern)
+		}
+		c.pattern = re
+	case KindLinks:
+		if rule.MaxLinks < 0 || rule.NewAccountPosts < 0 {
+			return compiledRule{}, invalid("отрицательный лимит ссылок")
+		}
+	case KindCaps:
+		if rule.CapsRatio == 0 {
+			c.CapsRatio = DefaultCapsRatio
+		}
+		if rule.MinLetters == 0 {
+			c.MinLetters = DefaultCapsLetters
+		}
+		if c.CapsRatio < 0 || c.CapsRatio > 1 || c.MinLetters < 0 {
+			return compiledRule{}, invalid("доля заглавных должна быть от 0 до 1")
+		}
+	case KindRepeat:
+		if rule.MaxRepeat == 0 {
+			c.MaxRepeat = DefaultMaxRepeat
+		}
+		if c.MaxRepeat < 1 {
+			return compiledRule{}, invalid("max_repeat должен быть положительным")
+		}
+	default:
+		return compiledRule{}, invalid("неизвестный тип %q", rule.Kind)
+	}
+	return c, nil
+}
+
+// match проверяет текст и при срабатывании возвращает текст с заменой
+// для действия replace.
+func (r compiledRule) match(text string, authorPosts int) (string, bool) {
+	switch r.Kind {
+	case KindWords:
+		return r.matchWords(text)
+	case KindRegex:
+		if !r.pattern.MatchString(text) {
+			return text, false
+		}
+		return r.pattern.ReplaceAllLiteralString(text, r.Replacement), true
+	case KindLinks:
+		if r.NewAccountPosts > 0 && authorPosts >= r.NewAccountPosts {
+			return text, false
+		}
+		if len(linkPattern.FindAllStringIndex(text, -1)) <= r.MaxLinks {
+			return text, false
+		}
+		return linkPattern.ReplaceAllLiteralString(text, r.Replacement), true
+	case KindCaps:
+		return r.matchCaps(text)
+	case KindRepeat:
+		return r.matchRepeat(text)
+	}
+	return text, false
+}
+
+func (r compiledRule) matchWords(text string) (string, bool) {
+	var b strings.Builder
+	matched := false
+	last := 0
+	for _, loc := range wordPattern.FindAllStringIndex(text, -1) {
+		if _, ok := r.words[strings.ToLower(text[loc[0]:loc[1]])]; !ok {
+			continue
+		}
+		matched = true
+		b.WriteString(text[last:loc[0]])
+		b.WriteString(r.Replacement)
+		last = loc[1]
+	}
+	if !matched {
+		return text, false
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+func (r compiledRule) matchCaps(text string) (string, bool) {
+	letters, upper := 0, 0
+	for _, ch := range text {
+		if !unicode.IsLetter(ch) {
+			continue
+		}
+		letters++
+		if unicode.IsUpper(ch) {
+			upper++
+		}
+	}
+	if letters < r.MinLetters || letters == 0 || float64(upper)/float64(letters) < r.CapsRatio {
+		return text, false
+	}
+	return strings.ToLower(text), true
+}
+
+func (r compiledRule) matchRepeat(text string) (string, bool) {
+	var b strings.Builder
+	matched := false
+	var prev rune
+	run := 0
+	for i, ch := range text {
+		if i > 0 && ch == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = ch
+		if run > r.MaxRepeat && !unicode.IsSpace(ch) {
+			matched = true
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	return b.String(), matched
+}
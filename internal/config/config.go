@@ -0,0 +1,399 @@
+// Package config собирает настройки сервиса из значений по умолчанию,
+// YAML-файла, переменных окружения и флагов командной строки. Каждый
+// следующий источник переопределяет предыдущий.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/dedup"
+	"github.com/fire9900/forum/internal/spam"
+	"github.com/fire9900/forum/pkg/i18n"
+	"github.com/fire9900/forum/pkg/ratelimit"
+	"gopkg.in/yaml.v3"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PathEnv - переменная окружения с путем к файлу конфигурации,
+// если он не передан флагом -config.
+const PathEnv = "FORUM_CONFIG"
+
+// Config - полная конфигурация сервиса. Ключ параметра в файле и имя флага
+// берутся из тегов yaml (например, http.addr), переменная окружения - из тега env.
+type Config struct {
+	HTTP      HTTPConfig      `yaml:"http"`
+	Database  DatabaseConfig  `yaml:"database"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Log       LogConfig       `yaml:"log"`
+	I18n      I18nConfig      `yaml:"i18n"`
+	Redis     RedisConfig     `yaml:"redis"`
+	RateLimit RateLimitConfig `yaml:"ratelimit"`
+	Automod   AutomodConfig   `yaml:"automod"`
+	Premod    PremodConfig    `yaml:"premod"`
+	Dedup     DedupConfig     `yaml:"dedup"`
+	Spam      SpamConfig      `yaml:"spam"`
+	Trash     TrashConfig     `yaml:"trash"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+}
+
+type HTTPConfig struct {
+	Addr           string        `yaml:"addr" env:"FORUM_HTTP_ADDR"`
+	RequestTimeout time.Duration `yaml:"request_timeout" env:"FORUM_REQUEST_TIMEOUT"`
+	CORSOrigins    []string      `yaml:"cors_origins" env:"FORUM_CORS_ORIGINS"`
+	// ShutdownTimeout - сколько ждать завершения текущих запросов и фоновых
+	// задач после сигнала остановки.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"FORUM_SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay - сколько после сигнала остановки /readyz отвечает 503
+	// до закрытия HTTP-сервера. Входит в ShutdownTimeout.
+	ShutdownDelay time.Duration `yaml:"shutdown_delay" env:"FORUM_SHUTDOWN_DELAY"`
+}
+
+type DatabaseConfig struct {
+	Path         string `yaml:"path" env:"FORUM_DB_PATH"`
+	MaxOpenConns int    `yaml:"max_open_conns" env:"FORUM_DB_MAX_OPEN_CONNS"`
+}
+
+type AuthConfig struct {
+	Addr string `yaml:"addr" env:"FORUM_AUTH_ADDR"`
+}
+
+// LogConfig - уровень, формат и язык логов и файлы журналов в дополнение
+// к stdout и stderr. Пустой путь отключает запись в файл. Файл ротируется,
+// когда превышает max_size_mb; хранится max_backups старых файлов не дольше
+// max_age_days дней (0 - без ограничения).
+type LogConfig struct {
+	Level      string `yaml:"level" env:"FORUM_LOG_LEVEL"`
+	Format     string `yaml:"format" env:"FORUM_LOG_FORMAT"`
+	Language   string `yaml:"language" env:"FORUM_LOG_LANGUAGE"`
+	File       string `yaml:"file" env:"FORUM_LOG_FILE"`
+	ErrorFile  string `yaml:"error_file" env:"FORUM_LOG_ERROR_FILE"`
+	MaxSizeMB  int    `yaml:"max_size_mb" env:"FORUM_LOG_MAX_SIZE_MB"`
+	MaxBackups int    `yaml:"max_backups" env:"FORUM_LOG_MAX_BACKUPS"`
+	MaxAgeDays int    `yaml:"max_age_days" env:"FORUM_LOG_MAX_AGE_DAYS"`
+	Compress   bool   `yaml:"compress" env:"FORUM_LOG_COMPRESS"`
+}
+
+// I18nConfig - язык ответов API, если клиент не выбрал его в настройках
+// и не прислал подходящий Accept-Language.
+type I18nConfig struct {
+	DefaultLanguage string `yaml:"default_language" env:"FORUM_DEFAULT_LANGUAGE"`
+}
+
+// RedisConfig - хранилище лимитов запросов. Без адреса лимиты хранятся в памяти.
+type RedisConfig struct {
+	Addr string `yaml:"addr" env:"FORUM_REDIS_ADDR"`
+}
+
+// RateLimitConfig - лимиты частоты запросов по конечным точкам и измерениям
+// (user, ip, thread): rate - токенов в секунду, burst - запросов подряд.
+// Задаются только в файле; конечная точка из файла заменяет свои лимиты
+// по умолчанию целиком, измерение без лимита не ограничивается.
+type RateLimitConfig struct {
+	Endpoints map[string]ratelimit.Rules `yaml:"endpoints"`
+}
+
+// AutomodConfig - JSON-файл правил автомодерации. Без него движок стартует
+// без правил и настраивается через /admin/automod.
+type AutomodConfig struct {
+	RulesFile string `yaml:"rules_file" env:"FORUM_AUTOMOD_CONFIG"`
+}
+
+// PremodConfig - сколько опубликованных сообщений нужно пользователю, чтобы
+// писать без премодерации. 0 отключает премодерацию.
+type PremodConfig struct {
+	TrustThreshold int `yaml:"trust_threshold" env:"FORUM_PREMOD_THRESHOLD"`
+}
+
+// DedupConfig - поиск повторов сообщений, поля соответствуют dedup.Config.
+// Пустое действие отключает проверку, max_distance не больше 3: дальние
+// почти повторы не находятся по индексам полос отпечатка.
+type DedupConfig struct {
+	UserWindow   time.Duration  `yaml:"user_window" env:"DEDUP_USER_WINDOW"`
+	GlobalWindow time.Duration  `yaml:"global_window" env:"DEDUP_GLOBAL_WINDOW"`
+	MaxDistance  int            `yaml:"max_distance" env:"DEDUP_MAX_DISTANCE"`
+	MinWords     int            `yaml:"min_words" env:"DEDUP_MIN_WORDS"`
+	UserAction   automod.Action `yaml:"user_action" env:"DEDUP_USER_ACTION"`
+	GlobalAction automod.Action `yaml:"global_action" env:"DEDUP_GLOBAL_ACTION"`
+	GlobalUsers  int            `yaml:"global_users" env:"DEDUP_GLOBAL_USERS"`
+}
+
+// SpamConfig - пороги вероятности спама, 0 отключает действие.
+type SpamConfig struct {
+	FlagThreshold float64 `yaml:"flag_threshold" env:"SPAM_FLAG_THRESHOLD"`
+	HoldThreshold float64 `yaml:"hold_threshold" env:"SPAM_HOLD_THRESHOLD"`
+}
+
+type TrashConfig struct {
+	RetentionDays int `yaml:"retention_days" env:"TRASH_RETENTION_DAYS"`
+}
+
+// TracingConfig - экспорт трассировок OpenTelemetry. Exporter: none - спаны
+// не записываются, но контекст трассировки передается дальше; stdout - печать
+// спанов для локальной отладки; otlp - отправка в коллектор по gRPC.
+type TracingConfig struct {
+	Exporter    string  `yaml:"exporter" env:"OTEL_TRACES_EXPORTER"`
+	Endpoint    string  `yaml:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	Insecure    bool    `yaml:"insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	ServiceName string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"FORUM_TRACE_SAMPLE_RATIO"`
+}
+
+// Retention - срок хранения удаленных сообщений в корзине.
+func (c TrashConfig) Retention() time.Duration {
+	return time.Duration(c.RetentionDays) * 24 * time.Hour
+}
+
+// Default возвращает конфигурацию для локального запуска.
+func Default() Config {
+	dedupCfg := dedup.DefaultConfig()
+	spamCfg := spam.DefaultConfig()
+	return Config{
+		HTTP: HTTPConfig{
+			Addr:            ":7777",
+			RequestTimeout:  10 * time.Second,
+			CORSOrigins:     []string{"http://localhost:3000"},
+			ShutdownTimeout: 15 * time.Second,
+		},
+		Database: DatabaseConfig{Path: "../data.db", MaxOpenConns: 1000},
+		Auth:     AuthConfig{Addr: "localhost:50051"},
+		Log: LogConfig{
+			Level:      "info",
+			Format:     "json",
+			Language:   "ru",
+			File:       "./forum.log",
+			ErrorFile:  "./forum-error.log",
+			MaxSizeMB:  100,
+			MaxBackups: 5,
+			MaxAgeDays: 30,
+		},
+		I18n:      I18nConfig{DefaultLanguage: "ru"},
+		RateLimit: RateLimitConfig{Endpoints: ratelimit.DefaultConfig().Endpoints},
+		Premod:    PremodConfig{TrustThreshold: 3},
+		Dedup: DedupConfig{
+			UserWindow:   dedupCfg.UserWindow,
+			GlobalWindow: dedupCfg.GlobalWindow,
+			MaxDistance:  dedupCfg.MaxDistance,
+			MinWords:     dedupCfg.MinWords,
+			UserAction:   dedupCfg.UserAction,
+			GlobalAction: dedupCfg.GlobalAction,
+			GlobalUsers:  dedupCfg.GlobalUsers,
+		},
+		Spam:  SpamConfig{FlagThreshold: spamCfg.FlagThreshold, HoldThreshold: spamCfg.HoldThreshold},
+		Trash: TrashConfig{RetentionDays: 30},
+		Tracing: TracingConfig{
+			Exporter:    "none",
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			ServiceName: "forum",
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Load регистрирует в fs флаг -config и по флагу на каждый параметр,
+// разбирает args и собирает конфигурацию: значения по умолчанию, затем файл,
+// переменные окружения и флаги. fs может заранее содержать флаги команды.
+func Load(fs *flag.FlagSet, args []string) (Config, error) {
+	path := fs.String("config", os.Getenv(PathEnv), "путь к YAML-файлу конфигурации (или "+PathEnv+")")
+
+	overrides := make(map[string]string)
+	for _, f := range fields(&Config{}) {
+		key := f.key
+		fs.Func(key, fmt.Sprintf("переопределяет %s (или %s)", key, f.env), func(value string) error {
+			overrides[key] = value
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if *path != "" {
+		if err := cfg.loadFile(*path); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, f := range fields(&cfg) {
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := set(f.value, value); err != nil {
+				return Config{}, fmt.Errorf("переменная %s: %w", f.env, err)
+			}
+		}
+		if value, ok := overrides[f.key]; ok {
+			if err := set(f.value, value); err != nil {
+				return Config{}, fmt.Errorf("флаг -%s: %w", f.key, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) loadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, err)
+	}
+	return nil
+}
+
+// dedupActions - действия, допустимые для повторов: заменять в повторе нечего.
+var dedupActions = []automod.Action{"", automod.ActionFlag, automod.ActionHold, automod.ActionReject}
+
+// Validate проверяет, что параметры заданы и находятся в допустимых пределах.
+// Возвращает все найденные ошибки сразу.
+func (c Config) Validate() error {
+	var errs []error
+	check := func(ok bool, key, msg string) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: %s", key, msg))
+		}
+	}
+	languagesMsg := "допустимы " + strings.Join(i18n.Languages(), ", ")
+
+	check(c.HTTP.Addr != "", "http.addr", "адрес не задан")
+	check(c.HTTP.RequestTimeout > 0, "http.request_timeout", "таймаут должен быть положительным")
+	check(c.HTTP.ShutdownTimeout > 0, "http.shutdown_timeout", "таймаут должен быть положительным")
+	check(c.HTTP.ShutdownDelay >= 0 && c.HTTP.ShutdownDelay < c.HTTP.ShutdownTimeout, "http.shutdown_delay", "задержка должна быть меньше http.shutdown_timeout")
+	check(c.Database.Path != "", "database.path", "путь к базе не задан")
+	check(c.Database.MaxOpenConns > 0, "database.max_open_conns", "число соединений должно быть положительным")
+	check(c.Auth.Addr != "", "auth.addr", "адрес сервиса авторизации не задан")
+	check(slices.Contains([]string{"debug", "info", "warn", "error"}, c.Log.Level), "log.level", "допустимы debug, info, warn и error")
+	check(c.Log.Format == "json" || c.Log.Format == "console", "log.format", "допустимы json и console")
+	check(i18n.Supported(c.Log.Language), "log.language", languagesMsg)
+	check(c.Log.MaxSizeMB > 0, "log.max_size_mb", "размер должен быть положительным")
+	check(c.Log.MaxBackups >= 0, "log.max_backups", "число файлов не может быть отрицательным")
+	check(c.Log.MaxAgeDays >= 0, "log.max_age_days", "срок не может быть отрицательным")
+	check(i18n.Supported(c.I18n.DefaultLanguage), "i18n.default_language", languagesMsg)
+	for endpoint, rules := range c.RateLimit.Endpoints {
+		key := "ratelimit.endpoints." + endpoint
+		check(slices.Contains(ratelimit.Endpoints(), endpoint), key, "допустимы "+strings.Join(ratelimit.Endpoints(), ", "))
+		for scope, limit := range rules {
+			check(slices.Contains(ratelimit.Scopes(), scope), key+"."+string(scope), "допустимы user, ip и thread")
+			check(limit.Rate > 0 && limit.Burst > 0, key+"."+string(scope), "rate и burst должны быть положительными")
+		}
+	}
+	check(c.Premod.TrustThreshold >= 0, "premod.trust_threshold", "порог не может быть отрицательным")
+	check(c.Dedup.UserWindow > 0, "dedup.user_window", "окно должно быть положительным")
+	check(c.Dedup.GlobalWindow > 0, "dedup.global_window", "окно должно быть положительным")
+	check(c.Dedup.MaxDistance >= 0 && c.Dedup.MaxDistance <= dedup.MaxIndexedDistance, "dedup.max_distance", fmt.Sprintf("расстояние должно быть от 0 до %d", dedup.MaxIndexedDistance))
+	check(c.Dedup.MinWords >= 0, "dedup.min_words", "число слов не может быть отрицательным")
+	check(slices.Contains(dedupActions, c.Dedup.UserAction), "dedup.user_action", "допустимы flag, hold, reject и пустое значение")
+	check(slices.Contains(dedupActions, c.Dedup.GlobalAction), "dedup.global_action", "допустимы flag, hold, reject и пустое значение")
+	check(c.Dedup.GlobalUsers > 0, "dedup.global_users", "число авторов должно быть положительным")
+	check(c.Spam.FlagThreshold >= 0 && c.Spam.FlagThreshold <= 1, "spam.flag_threshold", "порог должен быть от 0 до 1")
+	check(c.Spam.HoldThreshold >= 0 && c.Spam.HoldThreshold <= 1, "spam.hold_threshold", "порог должен быть от 0 до 1")
+	check(c.Trash.RetentionDays > 0, "trash.retention_days", "срок хранения должен быть положительным")
+	check(c.Tracing.Exporter == "none" || c.Tracing.Exporter == "stdout" || c.Tracing.Exporter == "otlp", "tracing.exporter", "допустимы none, stdout и otlp")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.Endpoint != "", "tracing.endpoint", "адрес коллектора не задан")
+	check(c.Tracing.ServiceName != "", "tracing.service_name", "имя сервиса не задано")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio", "доля должна быть от 0 до 1")
+	return errors.Join(errs...)
+}
+
+// YAML возвращает конфигурацию в формате файла конфигурации.
+func (c Config) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// field - один параметр конфигурации: ключ вида section.name, переменная
+// окружения и ссылка на поле структуры.
+type field struct {
+	key   string
+	env   string
+	value reflect.Value
+}
+
+func fields(cfg *Config) []field {
+	var result []field
+	root := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < root.NumField(); i++ {
+		section := root.Field(i)
+		prefix := root.Type().Field(i).Tag.Get("yaml")
+		for j := 0; j < section.NumField(); j++ {
+			tag := section.Type().Field(j).Tag
+			// Параметры без переменной окружения задаются только в файле.
+			if tag.Get("env") == "" {
+				continue
+			}
+			result = append(result, field{
+				key:   prefix + "." + tag.Get("yaml"),
+				env:   tag.Get("env"),
+				value: section.Field(j),
+			})
+		}
+	}
+	return result
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// set разбирает строковое значение из окружения или флага по типу поля.
+// Списки задаются через запятую.
+func set(v reflect.Value, value string) error {
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("некорректная длительность %q", value)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(value)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("некорректное целое число %q", value)
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("некорректное логическое значение %q", value)
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("некорректное число %q", value)
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", v.Type())
+	}
+	return nil
+}
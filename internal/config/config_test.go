@@ -0,0 +1,179 @@
+package config
+
+import (
+	"flag"
+	"github.com/fire9900/forum/pkg/ratelimit"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func load(t *testing.T, args ...string) (Config, error) {
+	t.Helper()
+	fs := flag.NewFlagSet("forum", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	return Load(fs, args)
+}
+
+func writeFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "forum.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv(PathEnv, "")
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() вернул ошибку: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("без источников ожидались значения по умолчанию, получено %+v", cfg)
+	}
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, `
+http:
+  addr: ":8000"
+  request_timeout: 5s
+  cors_origins: ["https://forum.example"]
+database:
+  path: /var/lib/forum.db
+premod:
+  trust_threshold: 7
+`)
+	t.Setenv("FORUM_HTTP_ADDR", ":8001")
+	t.Setenv("FORUM_PREMOD_THRESHOLD", "5")
+	t.Setenv("FORUM_CORS_ORIGINS", "https://a.example, https://b.example")
+
+	cfg, err := load(t, "-config", path, "-premod.trust_threshold", "0")
+	if err != nil {
+		t.Fatalf("Load() вернул ошибку: %v", err)
+	}
+
+	if cfg.HTTP.Addr != ":8001" {
+		t.Errorf("переменная окружения должна перекрывать файл, получено %q", cfg.HTTP.Addr)
+	}
+	if cfg.Premod.TrustThreshold != 0 {
+		t.Errorf("флаг должен перекрывать переменную окружения, получено %d", cfg.Premod.TrustThreshold)
+	}
+	if cfg.HTTP.RequestTimeout != 5*time.Second || cfg.Database.Path != "/var/lib/forum.db" {
+		t.Errorf("значения из файла не применены: %+v", cfg)
+	}
+	if want := []string{"https://a.example", "https://b.example"}; !reflect.DeepEqual(cfg.HTTP.CORSOrigins, want) {
+		t.Errorf("список из окружения разобран неверно: %v", cfg.HTTP.CORSOrigins)
+	}
+	if cfg.Auth.Addr != Default().Auth.Addr {
+		t.Errorf("незаданные параметры должны сохранять значения по умолчанию, получено %q", cfg.Auth.Addr)
+	}
+}
+
+func TestLoadConfigPathFromEnv(t *testing.T) {
+	t.Setenv(PathEnv, writeFile(t, "trash:\n  retention_days: 3\n"))
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() вернул ошибку: %v", err)
+	}
+	if cfg.Trash.Retention() != 72*time.Hour {
+		t.Errorf("ожидался срок хранения 72h, получено %v", cfg.Trash.Retention())
+	}
+}
+
+func TestLoadRateLimits(t *testing.T) {
+	t.Setenv(PathEnv, writeFile(t, `
+ratelimit:
+  endpoints:
+    post.create:
+      user: {rate: 2, burst: 10}
+`))
+
+	cfg, err := load(t)
+	if err != nil {
+		t.Fatalf("Load() вернул ошибку: %v", err)
+	}
+	want := ratelimit.Rules{ratelimit.ScopeUser: {Rate: 2, Burst: 10}}
+	if got := cfg.RateLimit.Endpoints[ratelimit.EndpointPostCreate]; !reflect.DeepEqual(got, want) {
+		t.Errorf("лимиты конечной точки из файла должны заменить значения по умолчанию, получено %v", got)
+	}
+	if got, want := cfg.RateLimit.Endpoints[ratelimit.EndpointThreadCreate], ratelimit.DefaultConfig().Endpoints[ratelimit.EndpointThreadCreate]; !reflect.DeepEqual(got, want) {
+		t.Errorf("незаданные конечные точки должны сохранять лимиты по умолчанию, получено %v", got)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	t.Setenv(PathEnv, "")
+
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		args []string
+		want string
+	}{
+		{name: "unknown key", file: "http:\n  port: 80\n", want: "port"},
+		{name: "bad env", env: map[string]string{"DEDUP_USER_WINDOW": "сутки"}, want: "DEDUP_USER_WINDOW"},
+		{name: "bad flag", args: []string{"-database.max_open_conns", "много"}, want: "-database.max_open_conns"},
+		{name: "invalid value", args: []string{"-spam.hold_threshold", "1.5", "-http.addr", ""}, want: "spam.hold_threshold"},
+		{name: "unknown exporter", env: map[string]string{"OTEL_TRACES_EXPORTER": "jaeger"}, want: "tracing.exporter"},
+		{name: "unknown language", env: map[string]string{"FORUM_DEFAULT_LANGUAGE": "de"}, want: "i18n.default_language"},
+		{name: "unknown dedup action", env: map[string]string{"DEDUP_USER_ACTION": "replace"}, want: "dedup.user_action"},
+		{name: "unindexed dedup distance", args: []string{"-dedup.max_distance", "8"}, want: "dedup.max_distance"},
+		{name: "unknown rate limit scope", file: "ratelimit:\n  endpoints:\n    post.create:\n      session: {rate: 1, burst: 1}\n", want: "ratelimit.endpoints.post.create.session"},
+		{name: "zero rate limit", file: "ratelimit:\n  endpoints:\n    ws.post:\n      user: {rate: 0, burst: 1}\n", want: "ratelimit.endpoints.ws.post.user"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file)}, args...)
+			}
+
+			_, err := load(t, args...)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ожидалась ошибка с %q, получено %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateReportsAllErrors(t *testing.T) {
+	cfg := Default()
+	cfg.HTTP.Addr = ""
+	cfg.Trash.RetentionDays = 0
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "http.addr") || !strings.Contains(err.Error(), "trash.retention_days") {
+		t.Errorf("ожидались ошибки по обоим параметрам, получено %v", err)
+	}
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	t.Setenv(PathEnv, "")
+	out, err := Default().YAML()
+	if err != nil {
+		t.Fatalf("YAML() вернул ошибку: %v", err)
+	}
+	if !strings.Contains(string(out), "request_timeout: 10s") {
+		t.Errorf("длительности должны печататься в читаемом виде:\n%s", out)
+	}
+
+	cfg, err := load(t, "-config", writeFile(t, string(out)))
+	if err != nil {
+		t.Fatalf("напечатанная конфигурация не читается: %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Default()) {
+		t.Errorf("конфигурация изменилась после печати и чтения: %+v", cfg)
+	}
+}
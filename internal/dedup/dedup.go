@@ -0,0 +1,216 @@
+// Package dedup находит повторно опубликованные тексты: точные копии и
+// почти совпадающие сообщения, сравнивая их simhash-отпечатки.
+package dedup
+
+import (
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"sort"
+	"time"
+)
+
+// Имена правил в жалобах и логах.
+const (
+	RuleUser   = "duplicate.user"
+	RuleGlobal = "duplicate.global"
+)
+
+// Config задает окна и действия поиска повторов:
+//   - UserWindow - за какой срок ищутся повторы собственных сообщений автора;
+//   - GlobalWindow - за какой срок ищутся такие же сообщения других авторов;
+//   - MaxDistance - сколько бит могут различаться у почти совпадающих текстов (0 - только точные копии);
+//   - MinWords - более короткие тексты не проверяются: «спасибо» и «+1» повторяются законно;
+//   - GlobalUsers - сколько других авторов должны написать тот же текст, чтобы сработало GlobalAction.
+//
+// Пустое действие отключает соответствующую проверку.
+type Config struct {
+	UserWindow   time.Duration
+	GlobalWindow time.Duration
+	MaxDistance  int
+	MinWords     int
+	UserAction   automod.Action
+	GlobalAction automod.Action
+	GlobalUsers  int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		UserWindow:   24 * time.Hour,
+		GlobalWindow: 24 * time.Hour,
+		MaxDistance:  3,
+		MinWords:     5,
+		UserAction:   automod.ActionReject,
+		GlobalAction: automod.ActionFlag,
+		GlobalUsers:  2,
+	}
+}
+
+// Result - итог проверки текста. Checked ложно, если текст слишком короткий:
+// такой отпечаток не нужно сохранять.
+type Result struct {
+	Hash       uint64
+	Checked    bool
+	Exact      bool
+	Action     automod.Action
+	Matches    []automod.Match
+	Duplicates []models.Fingerprint
+}
+
+type Detector struct {
+	config Config
+}
+
+func NewDetector(cfg Config) *Detector {
+	return &Detector{config: cfg}
+}
+
+func (d *Detector) Config() Config {
+	return d.config
+}
+
+// Since возвращает начало самого длинного окна: отпечатки старше
+// для проверки не нужны.
+func (d *Detector) Since(now time.Time) time.Time {
+	window := d.config.UserWindow
+	if d.config.GlobalWindow > window {
+		window = d.config.GlobalWindow
+	}
+	return now.Add(-window)
+}
+
+// Prepare вычисляет отпечаток текста. ok ложно для текстов короче MinWords.
+func (d *Detector) Prepare(text string) (hash uint64, ok bool) {
+	words := Words(text)
+	if len(words) == 0 || len(words) < d.config.MinWords {
+		return 0, false
+	}
+	return Fingerprint(words), true
+}
+
+// Check сравнивает отпечаток нового сообщения автора userID с недавними.
+func (d *Detector) Check(hash uint64, userID int, now time.Time, recent []models.Fingerprint) Result {
+	result := Result{Hash: hash, Checked: true}
+
+	var own bool
+	others := make(map[int]struct{})
+	for _, fp := range recent {
+		dist := Distance(hash, fp.Hash)
+		if dist > d.config.MaxDistance {
+			continue
+		}
+		age := now.Sub(fp.CreateAt)
+		if fp.UserID == userID {
+			if d.config.UserAction == "" || age > d.config.UserWindow {
+				continue
+			}
+			own = true
+		} else {
+			if d.config.GlobalAction == "" || age > d.config.GlobalWindow {
+				continue
+			}
+			others[fp.UserID] = struct{}{}
+		}
+		result.Exact = result.Exact || dist == 0
+		result.Duplicates = append(result.Duplicates, fp)
+	}
+
+	if own {
+		result.Action = d.config.UserAction
+		result.Matches = append(result.Matches, automod.Match{Rule: RuleUser, Action: d.config.UserAction})
+	}
+	if len(others) > 0 && len(others) >= d.config.GlobalUsers {
+		result.Action = automod.Stronger(result.Action, d.config.GlobalAction)
+		result.Matches = append(result.Matches, automod.Match{Rule: RuleGlobal, Action: d.config.GlobalAction})
+	}
+	return result
+}
+
+// Clusters группирует отпечатки, связанные цепочками совпадений с
+// расстоянием не больше maxDistance. Одиночные сообщения не возвращаются,
+// крупные группы идут первыми. При maxDistance до MaxIndexedDistance
+// сравниваются только отпечатки с общей полосой.
+func Clusters(fps []models.Fingerprint, maxDistance int) []models.DuplicateCluster {
+	parent := make([]int, len(fps))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	union := func(i, j int) {
+		if Distance(fps[i].Hash, fps[j].Hash) <= maxDistance {
+			parent[find(j)] = find(i)
+		}
+	}
+
+	if maxDistance > MaxIndexedDistance {
+		for i := range fps {
+			for j := i + 1; j < len(fps); j++ {
+				union(i, j)
+			}
+		}
+	} else {
+		// Одинаковые отпечатки сразу объединяются с первым из них, поэтому
+		// попарно сравниваются только разные отпечатки с общей полосой.
+		first := make(map[uint64]int)
+		var distinct []int
+		for i, fp := range fps {
+			if j, ok := first[fp.Hash]; ok {
+				parent[find(i)] = find(j)
+				continue
+			}
+			first[fp.Hash] = i
+			distinct = append(distinct, i)
+		}
+		for band := 0; band < Bands; band++ {
+			buckets := make(map[int64][]int)
+			for _, i := range distinct {
+				value := BandsOf(fps[i].Hash)[band]
+				buckets[value] = append(buckets[value], i)
+			}
+			for _, bucket := range buckets {
+				for x := range bucket {
+					for y := x + 1; y < len(bucket); y++ {
+						union(bucket[x], bucket[y])
+					}
+				}
+			}
+		}
+	}
+
+	groups := make(map[int][]models.Fingerprint)
+	var roots []int
+	for i, fp := range fps {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], fp)
+	}
+
+	clusters := []models.DuplicateCluster{}
+	for _, root := range roots {
+		items := groups[root]
+		if len(items) < 2 {
+			continue
+		}
+		users := make(map[int]struct{})
+		for _, fp := range items {
+			users[fp.UserID] = struct{}{}
+		}
+		clusters = append(clusters, models.DuplicateCluster{
+			Hash:  items[0].Hash,
+			Users: len(users),
+			Items: items,
+		})
+	}
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Items) > len(clusters[j].Items)
+	})
+	return clusters
+}
@@ -0,0 +1,100 @@
+package dedup
+
+import (
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+const spam = "Лучшие ставки на спорт только у нас, переходите по ссылке в профиле и получите бонус на первый депозит"
+
+func TestFingerprint(t *testing.T) {
+	base := Fingerprint(Words(spam))
+
+	if d := Distance(base, Fingerprint(Words("ЛУЧШИЕ ставки на спорт только у нас!!! Переходите по ссылке в профиле и получите бонус на первый депозит"))); d != 0 {
+		t.Errorf("регистр и пунктуация не должны влиять на отпечаток, расстояние %d", d)
+	}
+	near := Distance(base, Fingerprint(Words(spam+" сегодня")))
+	other := Distance(base, Fingerprint(Words("Подскажите, как настроить роутер, чтобы гостевая сеть не видела устройства в основной сети")))
+	if near >= other {
+		t.Errorf("почти повтор должен быть ближе постороннего текста: %d >= %d", near, other)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Now()
+	d := NewDetector(DefaultConfig())
+	hash, ok := d.Prepare(spam)
+	if !ok {
+		t.Fatal("Prepare() не принял длинный текст")
+	}
+	if _, ok := d.Prepare("спасибо, помогло"); ok {
+		t.Error("Prepare() принял текст короче MinWords")
+	}
+
+	tests := []struct {
+		name       string
+		recent     []models.Fingerprint
+		wantAction automod.Action
+	}{
+		{"no duplicates", nil, ""},
+		{"own repost", []models.Fingerprint{{UserID: 1, Hash: hash, CreateAt: now.Add(-time.Hour)}}, automod.ActionReject},
+		{"own repost outside window", []models.Fingerprint{{UserID: 1, Hash: hash, CreateAt: now.Add(-48 * time.Hour)}}, ""},
+		{"one other user", []models.Fingerprint{{UserID: 2, Hash: hash, CreateAt: now}}, ""},
+		{"two other users", []models.Fingerprint{
+			{UserID: 2, Hash: hash, CreateAt: now},
+			{UserID: 3, Hash: hash ^ 1, CreateAt: now},
+		}, automod.ActionFlag},
+		{"different text", []models.Fingerprint{{UserID: 1, Hash: ^hash, CreateAt: now}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := d.Check(hash, 1, now, tt.recent)
+			if r.Action != tt.wantAction {
+				t.Errorf("Check() action = %q, want %q", r.Action, tt.wantAction)
+			}
+		})
+	}
+}
+
+func TestClusters(t *testing.T) {
+	fps := []models.Fingerprint{
+		{TargetID: 1, UserID: 1, Hash: 0b0000},
+		{TargetID: 2, UserID: 2, Hash: 0b0001},
+		{TargetID: 3, UserID: 3, Hash: 0b0011},
+		{TargetID: 4, UserID: 1, Hash: ^uint64(0)},
+	}
+
+	clusters := Clusters(fps, 1)
+	if len(clusters) != 1 {
+		t.Fatalf("ожидалась 1 группа, получено %d", len(clusters))
+	}
+	if len(clusters[0].Items) != 3 || clusters[0].Users != 3 {
+		t.Errorf("неожиданная группа: %+v", clusters[0])
+	}
+}
+
+func TestClustersAcrossBands(t *testing.T) {
+	base := uint64(0x0123456789abcdef)
+	fps := []models.Fingerprint{
+		{TargetID: 1, UserID: 1, Hash: base},
+		{TargetID: 2, UserID: 2, Hash: base ^ (1<<5 | 1<<20 | 1<<40)},
+		{TargetID: 3, UserID: 3, Hash: base},
+		{TargetID: 4, UserID: 4, Hash: base ^ (1<<1 | 1<<17 | 1<<33 | 1<<49)},
+	}
+
+	clusters := Clusters(fps, MaxIndexedDistance)
+	if len(clusters) != 1 || len(clusters[0].Items) != 3 {
+		t.Fatalf("ожидалась группа из копий и почти повтора с общей полосой, получено %+v", clusters)
+	}
+	for _, fp := range clusters[0].Items {
+		if fp.TargetID == 4 {
+			t.Error("отпечаток, отличающийся во всех полосах, не должен попасть в группу")
+		}
+	}
+
+	if bands := BandsOf(base); bands != [Bands]int64{0xcdef, 0x89ab, 0x4567, 0x0123} {
+		t.Errorf("BandsOf() = %x", bands)
+	}
+}
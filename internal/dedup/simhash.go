@@ -0,0 +1,79 @@
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// shingleSize - число слов в шингле. Шинглы чувствительнее к порядку слов,
+// чем отдельные слова, и меньше реагируют на мелкие правки.
+const shingleSize = 3
+
+// Words разбивает текст на слова в нижнем регистре без пунктуации.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Fingerprint вычисляет 64-битный simhash текста по шинглам из слов.
+// У похожих текстов отпечатки отличаются в небольшом числе бит.
+func Fingerprint(words []string) uint64 {
+	var weights [64]int
+	for _, shingle := range shingles(words) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fp uint64
+	for bit, w := range weights {
+		if w > 0 {
+			fp |= 1 << bit
+		}
+	}
+	return fp
+}
+
+// Distance возвращает расстояние Хэмминга между отпечатками.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Bands - на сколько полос по 16 бит делится отпечаток. Отпечатки,
+// различающиеся не больше чем в Bands-1 битах, совпадают хотя бы в одной
+// полосе, поэтому кандидатов в почти повторы ищут по индексам полос.
+const Bands = 4
+
+// MaxIndexedDistance - наибольшее расстояние, при котором поиск по полосам
+// находит все почти повторы.
+const MaxIndexedDistance = Bands - 1
+
+// BandsOf делит отпечаток на полосы, младшие биты - в первой.
+func BandsOf(hash uint64) [Bands]int64 {
+	var bands [Bands]int64
+	for i := range bands {
+		bands[i] = int64(hash >> (16 * i) & 0xffff)
+	}
+	return bands
+}
+
+func shingles(words []string) []string {
+	if len(words) < shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	result := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		result = append(result, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return result
+}
@@ -0,0 +1,166 @@
+// Package metrics описывает метрики Prometheus сервиса. Методы Metrics
+// безопасно вызывать у nil: компоненты без метрик просто ничего не пишут.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const namespace = "forum"
+
+// Metrics хранит коллекторы сервиса и реестр, из которого их отдает /metrics.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpDuration   *prometheus.HistogramVec
+	queryDuration  *prometheus.HistogramVec
+	wsConnections  *prometheus.GaugeVec
+	droppedClients prometheus.Counter
+	threadsCreated prometheus.Counter
+	postsCreated   *prometheus.CounterVec
+}
+
+// New создает и регистрирует метрики сервиса, а также стандартные метрики
+// рантайма Go и процесса.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Время обработки HTTP-запроса по маршруту и коду ответа.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Время выполнения метода репозитория.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"method", "status"}),
+		wsConnections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "ws",
+			Name:      "connections",
+			Help:      "Открытые WebSocket-соединения по тредам.",
+		}, []string{"thread"}),
+		droppedClients: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "hub",
+			Name:      "dropped_clients_total",
+			Help:      "Клиенты, отключенные из-за переполнения очереди отправки.",
+		}),
+		threadsCreated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "threads_created_total",
+			Help:      "Созданные треды.",
+		}),
+		postsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "posts_created_total",
+			Help:      "Созданные посты; hidden - на премодерации или под теневой блокировкой.",
+		}, []string{"visibility"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpDuration,
+		m.queryDuration,
+		m.wsConnections,
+		m.droppedClients,
+		m.threadsCreated,
+		m.postsCreated,
+	)
+	return m
+}
+
+// Handler отдает метрики в формате Prometheus.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveHTTP учитывает обработанный запрос. route - шаблон маршрута,
+// а не фактический путь, чтобы число серий не зависело от ID в URL.
+func (m *Metrics) ObserveHTTP(method, route string, status int, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.httpDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(d.Seconds())
+}
+
+// ObserveQuery учитывает вызов метода репозитория.
+func (m *Metrics) ObserveQuery(method string, err error, d time.Duration) {
+	if m == nil {
+		return
+	}
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	m.queryDuration.WithLabelValues(method, status).Observe(d.Seconds())
+}
+
+// SetWSConnections задает число WebSocket-соединений с тредом. Серия
+// треда удаляется, когда соединений не остается.
+func (m *Metrics) SetWSConnections(threadID, count int) {
+	if m == nil {
+		return
+	}
+	label := strconv.Itoa(threadID)
+	if count == 0 {
+		m.wsConnections.DeleteLabelValues(label)
+		return
+	}
+	m.wsConnections.WithLabelValues(label).Set(float64(count))
+}
+
+// ClientDropped учитывает клиента, отключенного из-за медленного чтения.
+func (m *Metrics) ClientDropped() {
+	if m == nil {
+		return
+	}
+	m.droppedClients.Inc()
+}
+
+// RegisterHubQueue регистрирует глубину очереди рассылки хаба. depth
+// вызывается при каждом чтении метрик.
+func (m *Metrics) RegisterHubQueue(depth func() int) {
+	if m == nil {
+		return
+	}
+	m.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "hub",
+		Name:      "queue_depth",
+		Help:      "Сообщения в очередях отправки клиентов хаба.",
+	}, func() float64 {
+		return float64(depth())
+	}))
+}
+
+// ThreadCreated учитывает созданный тред.
+func (m *Metrics) ThreadCreated() {
+	if m == nil {
+		return
+	}
+	m.threadsCreated.Inc()
+}
+
+// PostCreated учитывает созданный пост.
+func (m *Metrics) PostCreated(hidden bool) {
+	if m == nil {
+		return
+	}
+	visibility := "public"
+	if hidden {
+		visibility = "hidden"
+	}
+	m.postsCreated.WithLabelValues(visibility).Inc()
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.ObserveHTTP("GET", "/threads", 200, time.Millisecond)
+	m.ObserveQuery("GetAllThreads", nil, time.Millisecond)
+	m.SetWSConnections(1, 1)
+	m.ClientDropped()
+	m.RegisterHubQueue(func() int { return 0 })
+	m.ThreadCreated()
+	m.PostCreated(true)
+}
+
+func TestObserveQuery(t *testing.T) {
+	m := New()
+	m.ObserveQuery("GetThreadByID", nil, time.Millisecond)
+	m.ObserveQuery("GetThreadByID", errors.New("нет треда"), time.Millisecond)
+	m.ObserveQuery("GetThreadByID", nil, time.Millisecond)
+
+	if n := testutil.CollectAndCount(m.queryDuration); n != 2 {
+		t.Errorf("ожидались серии ok и error, получено %d", n)
+	}
+}
+
+func TestSetWSConnections(t *testing.T) {
+	m := New()
+	m.SetWSConnections(1, 2)
+	m.SetWSConnections(2, 1)
+	if got := testutil.ToFloat64(m.wsConnections.WithLabelValues("1")); got != 2 {
+		t.Errorf("ожидалось 2 соединения с тредом 1, получено %v", got)
+	}
+
+	m.SetWSConnections(2, 0)
+	if n := testutil.CollectAndCount(m.wsConnections); n != 1 {
+		t.Errorf("серия треда без соединений должна удаляться, серий: %d", n)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	m := New()
+	m.PostCreated(false)
+	m.PostCreated(true)
+	m.PostCreated(true)
+	m.RegisterHubQueue(func() int { return 5 })
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`forum_posts_created_total{visibility="hidden"} 2`,
+		`forum_posts_created_total{visibility="public"} 1`,
+		`forum_hub_queue_depth 5`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("в ответе /metrics нет %q", want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия, сохраняемые в журнале аудита, помимо объявленных рядом
+// с соответствующими моделями.
+const (
+	AuditPostDelete      = "post.delete"
+	AuditThreadDelete    = "thread.delete"
+	AuditThreadEdit      = "thread.edit"
+	AuditThreadState     = "thread.state"
+	AuditReportResolve   = "report.resolve"
+	AuditCategoryCreate  = "category.create"
+	AuditModeratorAdd    = "moderator.add"
+	AuditModeratorRemove = "moderator.remove"
+	AuditAutomodUpdate   = "automod.update"
+	AuditSpamLabel       = "spam.label"
+
+	TargetReport   = "report"
+	TargetCategory = "category"
+	TargetAutomod  = "automod"
+)
+
+// Ограничения размера выборки журнала аудита.
+const (
+	DefaultAuditLimit = 100
+	MaxAuditLimit     = 10000
+)
+
+// AuditEntry - запись журнала действий модераторов. Before и After хранят
+// JSON-снимки объекта до и после изменения, если они есть.
+type AuditEntry struct {
+	ID         int             `json:"id"`
+	ActorID    int             `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int             `json:"target_id"`
+	Reason     string          `json:"reason,omitempty"`
+	Details    string          `json:"details"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	CreateAt   time.Time       `json:"create_at"`
+}
+
+// AuditFilter - условия выборки журнала аудита. Нулевые поля не фильтруют.
+type AuditFilter struct {
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   int
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// Snapshot сериализует объект для журнала аудита.
+func Snapshot(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
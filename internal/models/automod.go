@@ -0,0 +1,9 @@
+package models
+
+var (
+	ErrorContentRejected    = Validation("rejected", "Сообщение отклонено автомодерацией")
+	ErrorInvalidAutomodRule = Validation("invalid_automod_rule", "Некорректное правило автомодерации")
+)
+
+// AutomodReporterID - автор жалоб, которые создает автомодерация.
+const AutomodReporterID = 0
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+var (
+	ErrorUserBanned  = Forbidden("banned", "Пользователь заблокирован")
+	ErrorNotFoundBan = NotFound("ban_not_found", "Блокировка не найдена")
+	ErrorInvalidBan  = Validation("invalid_ban", "Неверные параметры блокировки")
+)
+
+const (
+	AuditUserBan   = "user.ban"
+	AuditUserUnban = "user.unban"
+
+	TargetUser = "user"
+)
+
+// Ban - блокировка пользователя. CategoryID = 0 означает блокировку на всем
+// форуме, ExpiresAt = nil - бессрочную блокировку. При теневой блокировке
+// пользователь может писать, но его посты видны только ему самому.
+type Ban struct {
+	ID          int        `json:"id"`
+	UserID      int        `json:"user_id"`
+	CategoryID  int        `json:"category_id"`
+	Shadow      bool       `json:"shadow"`
+	Reason      string     `json:"reason"`
+	ModeratorID int        `json:"moderator_id"`
+	CreateAt    time.Time  `json:"create_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// Applies сообщает, действует ли блокировка в категории categoryID.
+func (b Ban) Applies(categoryID int) bool {
+	return b.CategoryID == 0 || b.CategoryID == categoryID
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+var ErrorDuplicateContent = Conflict("duplicate", "Сообщение повторяет недавно опубликованное")
+
+// Fingerprint - отпечаток текста поста или треда для поиска повторов.
+type Fingerprint struct {
+	ID         int       `json:"id"`
+	TargetType string    `json:"target_type"`
+	TargetID   int       `json:"target_id"`
+	UserID     int       `json:"user_id"`
+	Hash       uint64    `json:"hash,string"`
+	CreateAt   time.Time `json:"create_at"`
+}
+
+// DuplicateCluster - группа совпадающих или почти совпадающих сообщений.
+type DuplicateCluster struct {
+	Hash  uint64        `json:"hash,string"`
+	Users int           `json:"users"`
+	Items []Fingerprint `json:"items"`
+}
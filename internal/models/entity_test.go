@@ -1,6 +1,7 @@
 package models
 
 import (
+	"errors"
 	"testing"
 	"time"
 )
@@ -38,3 +39,73 @@ func TestThisByUser_OK(t *testing.T) {
 		t.Errorf("thread.USER_ID() = %d, post.USER_ID() = %d, chat.USER_ID() = %d", id1, id2, id3)
 	}
 }
+
+func TestThreadCanReply(t *testing.T) {
+	tests := []struct {
+		name   string
+		thread Thread
+		want   error
+	}{
+		{"open", Thread{}, nil},
+		{"pinned", Thread{Pinned: true}, nil},
+		{"locked", Thread{Locked: true}, ErrorThreadLocked},
+		{"archived", Thread{Archived: true}, ErrorThreadArchived},
+		{"locked and archived", Thread{Locked: true, Archived: true}, ErrorThreadArchived},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.thread.CanReply(); got != tt.want {
+				t.Errorf("CanReply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreadApply(t *testing.T) {
+	pinned, locked := true, false
+	th := Thread{Locked: true, Archived: true}
+
+	th.Apply(ThreadState{Pinned: &pinned, Locked: &locked})
+
+	if !th.Pinned || th.Locked || !th.Archived {
+		t.Errorf("Apply() = pinned %v, locked %v, archived %v", th.Pinned, th.Locked, th.Archived)
+	}
+}
+
+func TestThreadStateValidate(t *testing.T) {
+	valid, negative, tooLong := 30, -1, MaxSlowMode+1
+
+	tests := []struct {
+		name    string
+		state   ThreadState
+		wantErr bool
+	}{
+		{"no slow mode", ThreadState{}, false},
+		{"valid slow mode", ThreadState{SlowMode: &valid}, false},
+		{"negative slow mode", ThreadState{SlowMode: &negative}, true},
+		{"too long slow mode", ThreadState{SlowMode: &tooLong}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.state.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrorInvalidSlowMode) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrorInvalidSlowMode)
+			}
+		})
+	}
+}
+
+func TestSlowModeError(t *testing.T) {
+	err := error(&SlowModeError{Wait: 1500 * time.Millisecond})
+
+	if !errors.Is(err, ErrorSlowMode) {
+		t.Errorf("errors.Is(%v, ErrorSlowMode) = false", err)
+	}
+	if got := err.(*SlowModeError).RetryAfterSeconds(); got != 2 {
+		t.Errorf("RetryAfterSeconds() = %d, want 2", got)
+	}
+}
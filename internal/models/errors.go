@@ -0,0 +1,89 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ErrorKind - класс доменной ошибки. По нему транспорт выбирает код ответа.
+type ErrorKind string
+
+const (
+	KindNotFound     ErrorKind = "not_found"
+	KindForbidden    ErrorKind = "forbidden"
+	KindValidation   ErrorKind = "validation"
+	KindConflict     ErrorKind = "conflict"
+	KindRateLimited  ErrorKind = "rate_limited"
+	KindUnauthorized ErrorKind = "unauthorized"
+)
+
+// Error - доменная ошибка с машиночитаемым кодом. Ошибки сравниваются
+// по коду, поэтому копия с деталями из WithField остается равной исходной
+// для errors.Is.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	// Fields - ошибки проверки по полям запроса.
+	Fields map[string]string
+}
+
+func newError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func NotFound(code, message string) *Error {
+	return newError(KindNotFound, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return newError(KindForbidden, code, message)
+}
+
+func Validation(code, message string) *Error {
+	return newError(KindValidation, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return newError(KindConflict, code, message)
+}
+
+func RateLimited(code, message string) *Error {
+	return newError(KindRateLimited, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return newError(KindUnauthorized, code, message)
+}
+
+func (e *Error) Error() string {
+	if len(e.Fields) == 0 {
+		return e.Message
+	}
+
+	fields := make([]string, 0, len(e.Fields))
+	for field, msg := range e.Fields {
+		fields = append(fields, field+": "+msg)
+	}
+	sort.Strings(fields)
+	return e.Message + " (" + strings.Join(fields, "; ") + ")"
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithField возвращает копию ошибки с описанием проблемы в поле field.
+// Исходная ошибка, обычно общая переменная пакета, не меняется.
+func (e *Error) WithField(field, message string) *Error {
+	fields := make(map[string]string, len(e.Fields)+1)
+	for k, v := range e.Fields {
+		fields[k] = v
+	}
+	fields[field] = message
+
+	copied := *e
+	copied.Fields = fields
+	return &copied
+}
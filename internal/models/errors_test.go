@@ -0,0 +1,51 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestErrorWithField(t *testing.T) {
+	err := ErrorInvalidBan.WithField("reason", "длиннее 1000 символов").WithField("user_id", "не указан пользователь")
+
+	if !errors.Is(err, ErrorInvalidBan) {
+		t.Errorf("errors.Is(%v, ErrorInvalidBan) = false, want true", err)
+	}
+	if errors.Is(err, ErrorInvalidReport) {
+		t.Errorf("errors.Is(%v, ErrorInvalidReport) = true, want false", err)
+	}
+	if len(ErrorInvalidBan.Fields) != 0 {
+		t.Errorf("WithField изменил исходную ошибку: %v", ErrorInvalidBan.Fields)
+	}
+	want := "Неверные параметры блокировки (reason: длиннее 1000 символов; user_id: не указан пользователь)"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestErrorAs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind ErrorKind
+		code string
+	}{
+		{"wrapped", fmt.Errorf("%w: роль не найдена", ErrorForbidden), KindForbidden, "forbidden"},
+		{"slow mode", &SlowModeError{Wait: time.Second}, KindRateLimited, "slow_mode"},
+		{"not found", ErrorNotFoundThread, KindNotFound, "thread_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var domain *Error
+			if !errors.As(tt.err, &domain) {
+				t.Fatalf("errors.As(%v) = false, want true", tt.err)
+			}
+			if domain.Kind != tt.kind || domain.Code != tt.code {
+				t.Errorf("kind, code = %s, %s, want %s, %s", domain.Kind, domain.Code, tt.kind, tt.code)
+			}
+		})
+	}
+}
@@ -1,26 +1,89 @@
 package models
 
 import (
-	"errors"
+	"fmt"
 	"time"
 )
 
 var (
-	ErrorNotFoundThread = errors.New("Тред не найден")
-	ErrorNotFoundPost   = errors.New("Пост не найден")
-	ErrorNotFoundUser   = errors.New("Пользователь не найден")
+	ErrorNotFoundThread  = NotFound("thread_not_found", "Тред не найден")
+	ErrorNotFoundPost    = NotFound("post_not_found", "Пост не найден")
+	ErrorNotFoundUser    = NotFound("user_not_found", "Пользователь не найден")
+	ErrorThreadLocked    = Forbidden("thread_locked", "Тред закрыт для ответов")
+	ErrorThreadArchived  = Forbidden("thread_archived", "Тред находится в архиве")
+	ErrorSlowMode        = RateLimited("slow_mode", "В треде включен медленный режим")
+	ErrorInvalidSlowMode = Validation("invalid_slow_mode", "Недопустимый интервал медленного режима")
+	ErrorInvalidThread   = Validation("invalid_thread", "Некорректный тред")
+	ErrorInvalidPost     = Validation("invalid_post", "Некорректный пост")
 )
 
+// MaxSlowMode — наибольший интервал медленного режима в секундах.
+const MaxSlowMode = 6 * 60 * 60
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+	RoleUser      = "user"
+)
+
+// Actor — пользователь, выполняющий действие: его роль и категории,
+// в которых он назначен модератором.
+type Actor struct {
+	ID         int
+	Role       string
+	Categories []int
+}
+
 type User interface {
 	USER_ID() int
 }
 
 type Thread struct {
-	ID       int       `json:"id"`
-	Title    string    `json:"title"`
-	Content  string    `json:"content"`
-	CreateAt time.Time `json:"create_at"`
-	UserID   int       `json:"user_ID"`
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	CreateAt   time.Time `json:"create_at"`
+	UserID     int       `json:"user_ID"`
+	Pinned     bool      `json:"pinned"`
+	Locked     bool      `json:"locked"`
+	Archived   bool      `json:"archived"`
+	CategoryID int       `json:"category_id"`
+	// SlowMode — минимальный интервал в секундах между постами одного
+	// пользователя в треде. 0 отключает медленный режим.
+	SlowMode int `json:"slow_mode"`
+	// Pending выставляется для треда, ожидающего проверки модератором.
+	Pending bool `json:"pending"`
+	// DeletedAt и DeletedBy заполнены у треда в корзине.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy int        `json:"deleted_by,omitempty"`
+}
+
+// ThreadState описывает изменение состояния треда.
+// Поля со значением nil остаются без изменений.
+type ThreadState struct {
+	Pinned   *bool `json:"pinned"`
+	Locked   *bool `json:"locked"`
+	Archived *bool `json:"archived"`
+	SlowMode *int  `json:"slow_mode"`
+}
+
+// SlowModeError возвращается, если пользователь пишет в тред с медленным
+// режимом раньше, чем истек интервал.
+type SlowModeError struct {
+	Wait time.Duration
+}
+
+func (e *SlowModeError) Error() string {
+	return fmt.Sprintf("%s: следующий пост можно отправить через %d с", ErrorSlowMode, e.RetryAfterSeconds())
+}
+
+func (e *SlowModeError) Unwrap() error {
+	return ErrorSlowMode
+}
+
+// RetryAfterSeconds округляет время ожидания вверх до целых секунд.
+func (e *SlowModeError) RetryAfterSeconds() int {
+	return int((e.Wait + time.Second - 1) / time.Second)
 }
 
 type Post struct {
@@ -29,6 +92,14 @@ type Post struct {
 	CreateAt time.Time `json:"create_at"`
 	ThreadID int       `json:"thread_id"`
 	UserID   int       `json:"user_id"`
+	// Pending выставляется для поста, ожидающего проверки модератором.
+	Pending bool `json:"pending"`
+	// DeletedAt и DeletedBy заполнены у удаленного поста.
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy int        `json:"deleted_by,omitempty"`
+	// Hidden выставляется для поста, который видит только его автор:
+	// под теневой блокировкой или на проверке.
+	Hidden bool `json:"-"`
 }
 
 type Chat struct {
@@ -37,6 +108,51 @@ type Chat struct {
 	PostID   int `json:"post_id"`
 }
 
+// Apply переносит заданные в state флаги на тред.
+func (t *Thread) Apply(state ThreadState) {
+	if state.Pinned != nil {
+		t.Pinned = *state.Pinned
+	}
+	if state.Locked != nil {
+		t.Locked = *state.Locked
+	}
+	if state.Archived != nil {
+		t.Archived = *state.Archived
+	}
+	if state.SlowMode != nil {
+		t.SlowMode = *state.SlowMode
+	}
+}
+
+// Validate проверяет значения, которые нельзя применить к треду.
+func (s ThreadState) Validate() error {
+	if s.SlowMode != nil && (*s.SlowMode < 0 || *s.SlowMode > MaxSlowMode) {
+		return ErrorInvalidSlowMode.WithField("slow_mode", fmt.Sprintf("допустимо от 0 до %d секунд", MaxSlowMode))
+	}
+	return nil
+}
+
+// CanReply возвращает ошибку, если в тред нельзя писать новые сообщения.
+func (t Thread) CanReply() error {
+	if t.Archived {
+		return ErrorThreadArchived
+	}
+	if t.Locked {
+		return ErrorThreadLocked
+	}
+	return nil
+}
+
+// Moderates сообщает, назначен ли пользователь модератором категории.
+func (a Actor) Moderates(categoryID int) bool {
+	for _, id := range a.Categories {
+		if id == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
 func (t Thread) USER_ID() int {
 	return t.UserID
 }
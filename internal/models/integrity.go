@@ -0,0 +1,10 @@
+package models
+
+// Orphans - строки таблицы Table, чья колонка Column ссылается на
+// отсутствующую запись в таблице Parent.
+type Orphans struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Parent string `json:"parent"`
+	Count  int64  `json:"count"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+var (
+	ErrorNotFoundCategory  = NotFound("category_not_found", "Категория не найдена")
+	ErrorNotFoundModerator = NotFound("moderator_not_found", "Модератор категории не найден")
+	ErrorForbidden         = Forbidden("forbidden", "Нет прав доступа")
+	ErrorSameThread        = Validation("same_thread", "Нельзя объединить тред с самим собой")
+	ErrorInvalidPostRange  = Validation("invalid_post_range", "Неверный диапазон постов")
+	ErrorInvalidCategory   = Validation("invalid_category", "Некорректная категория")
+)
+
+// Действия модераторов, сохраняемые в журнале аудита.
+const (
+	AuditThreadMerge = "thread.merge"
+	AuditThreadSplit = "thread.split"
+	AuditThreadMove  = "thread.move"
+)
+
+// Типы объектов, над которыми выполняются действия.
+const (
+	TargetThread = "thread"
+	TargetPost   = "post"
+)
+
+type Category struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreateAt    time.Time `json:"create_at"`
+}
+
+// SplitRequest описывает выделение постов треда в новый тред.
+// В новый тред переносятся посты с ID от FromPostID до ToPostID включительно.
+type SplitRequest struct {
+	ThreadID   int    `json:"-"`
+	FromPostID int    `json:"from_post_id"`
+	ToPostID   int    `json:"to_post_id"`
+	Title      string `json:"title"`
+	Content    string `json:"content"`
+}
@@ -0,0 +1,9 @@
+package models
+
+var ErrorInvalidLanguage = Validation("invalid_language", "Неподдерживаемый язык")
+
+// LanguagePreference - язык ответов API, выбранный пользователем. Пустой
+// язык означает, что язык определяется по заголовку Accept-Language.
+type LanguagePreference struct {
+	Language string `json:"language"`
+}
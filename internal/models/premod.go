@@ -0,0 +1,30 @@
+package models
+
+var ErrorNotPending = Conflict("not_pending", "Сообщение не ожидает проверки")
+
+// Решения по очереди премодерации, сохраняемые в журнале аудита.
+const (
+	AuditPostApprove   = "post.approve"
+	AuditPostReject    = "post.reject"
+	AuditThreadApprove = "thread.approve"
+	AuditThreadReject  = "thread.reject"
+)
+
+// Viewer - читатель ленты. Ожидающие проверки посты видят их автор
+// и модераторы.
+type Viewer struct {
+	ID        int
+	Moderator bool
+}
+
+// PendingPost - пост в очереди премодерации вместе с категорией его треда.
+type PendingPost struct {
+	Post
+	CategoryID int `json:"category_id"`
+}
+
+// PendingQueue - треды и посты, ожидающие проверки модератором.
+type PendingQueue struct {
+	Threads []Thread      `json:"threads"`
+	Posts   []PendingPost `json:"posts"`
+}
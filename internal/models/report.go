@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+var (
+	ErrorNotFoundReport       = NotFound("report_not_found", "Жалоба не найдена")
+	ErrorInvalidReport        = Validation("invalid_report", "Некорректная жалоба")
+	ErrorReportClosed         = Conflict("report_closed", "Жалоба уже рассмотрена")
+	ErrorInvalidReportAction  = Validation("invalid_report_action", "Неизвестное действие над жалобой")
+	ErrorNotFoundNotification = NotFound("notification_not_found", "Уведомление не найдено")
+)
+
+// Статусы жалобы. Жалоба создается открытой и может быть закрыта
+// один раз: с принятием мер или отклонением.
+const (
+	ReportOpen      = "open"
+	ReportActioned  = "actioned"
+	ReportDismissed = "dismissed"
+)
+
+// Массовые действия над жалобами.
+const (
+	ReportActionDelete  = "delete"
+	ReportActionDismiss = "dismiss"
+)
+
+type Report struct {
+	ID          int        `json:"id"`
+	ReporterID  int        `json:"reporter_id"`
+	TargetType  string     `json:"target_type"`
+	TargetID    int        `json:"target_id"`
+	Reason      string     `json:"reason"`
+	Status      string     `json:"status"`
+	ModeratorID int        `json:"moderator_id"`
+	Resolution  string     `json:"resolution"`
+	CreateAt    time.Time  `json:"create_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReportFilter задает выборку жалоб для очереди модерации.
+// Пустые поля не участвуют в фильтрации.
+type ReportFilter struct {
+	Status     string
+	TargetType string
+	ReporterID int
+	Limit      int
+	Offset     int
+}
+
+// BulkReportResult - результат массового действия для одной жалобы.
+type BulkReportResult struct {
+	ReportID int    `json:"report_id"`
+	Status   string `json:"status,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+type Notification struct {
+	ID       int       `json:"id"`
+	UserID   int       `json:"user_id"`
+	Message  string    `json:"message"`
+	Read     bool      `json:"read"`
+	CreateAt time.Time `json:"create_at"`
+}
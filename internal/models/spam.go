@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+var ErrorInvalidSpamLabel = Validation("invalid_spam_label", "Неизвестная метка спама")
+
+// Метки обучающих примеров классификатора спама.
+const (
+	SpamLabelSpam = "spam"
+	SpamLabelHam  = "ham"
+)
+
+// SpamFeedback - пост, размеченный модератором или по итогам жалобы.
+type SpamFeedback struct {
+	ID          int       `json:"id"`
+	PostID      int       `json:"post_id"`
+	Label       string    `json:"label"`
+	ModeratorID int       `json:"moderator_id"`
+	CreateAt    time.Time `json:"create_at"`
+}
+
+// SpamCounts - число примеров или вхождений слова по классам.
+type SpamCounts struct {
+	Spam int `json:"spam"`
+	Ham  int `json:"ham"`
+}
+
+// SpamModel - накопленная статистика классификатора: число размеченных
+// постов и вхождений каждого слова в спам и не спам.
+type SpamModel struct {
+	Docs   SpamCounts
+	Tokens map[string]SpamCounts
+}
+
+// SpamScore - оценка поста классификатором: вероятность того, что это спам.
+type SpamScore struct {
+	PostID   int       `json:"post_id"`
+	Score    float64   `json:"score"`
+	CreateAt time.Time `json:"create_at"`
+}
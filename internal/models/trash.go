@@ -0,0 +1,36 @@
+package models
+
+// DeletedContent заменяет текст удаленного поста в ленте треда, чтобы
+// ответы на него не теряли контекст.
+const DeletedContent = "[deleted]"
+
+// Восстановление из корзины, сохраняемое в журнале аудита.
+const (
+	AuditPostRestore   = "post.restore"
+	AuditThreadRestore = "thread.restore"
+)
+
+// Trash - удаленные треды и посты, которые еще можно восстановить.
+type Trash struct {
+	Threads []Thread `json:"threads"`
+	Posts   []Post   `json:"posts"`
+}
+
+// TrashFilter ограничивает корзину собственными сообщениями пользователя
+// и сообщениями в категориях, которые он модерирует. All снимает
+// ограничения для модераторов форума.
+type TrashFilter struct {
+	All        bool
+	OwnerID    int
+	Categories []int
+	Limit      int
+}
+
+// Tombstone скрывает текст и автора удаленного поста.
+func (p *Post) Tombstone() {
+	if p.DeletedAt == nil {
+		return
+	}
+	p.Content = DeletedContent
+	p.UserID = 0
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type AuditRepository interface {
+	AddAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error)
+}
+
+// execer - общее у *sql.DB и *sql.Tx, чтобы писать в журнал как в
+// транзакции изменения, так и отдельно от нее.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func insertAudit(ctx context.Context, tx execer, entry models.AuditEntry) error {
+	query := `INSERT INTO audit_log (actor_id, action, target_type, target_id, reason, details,
+			                         snapshot_before, snapshot_after, create_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	if _, err := tx.ExecContext(ctx, query,
+		entry.ActorID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		entry.Reason,
+		entry.Details,
+		string(entry.Before),
+		string(entry.After),
+		time.Now(),
+	); err != nil {
+		return fmt.Errorf("Ошибка записи в журнал аудита: %w", err)
+	}
+	return nil
+}
+
+// AddAuditEntry записывает действие, которое не меняет данных в базе,
+// например смену правил автомодерации.
+func (f *forumRepository) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	if err := insertAudit(ctx, f.db, entry); err != nil {
+		f.log(ctx).Error("Ошибка записи в журнал аудита",
+			zap.String("action", entry.Action),
+			zap.Int("actorID", entry.ActorID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (f *forumRepository) GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	var conditions []string
+	var args []any
+	if filter.ActorID != 0 {
+		args = append(args, filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conditions = append(conditions, fmt.Sprintf("target_type = $%d", len(args)))
+	}
+	if filter.TargetID != 0 {
+		args = append(args, filter.TargetID)
+		conditions = append(conditions, fmt.Sprintf("target_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		conditions = append(conditions, fmt.Sprintf("create_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		conditions = append(conditions, fmt.Sprintf("create_at < $%d", len(args)))
+	}
+
+	query := `SELECT id, actor_id, action, target_type, target_id, reason, details,
+			         snapshot_before, snapshot_after, create_at
+			  FROM audit_log`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	query += fmt.Sprintf(" ORDER BY id DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения журнала аудита", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения журнала аудита: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []models.AuditEntry
+	for rows.Next() {
+		var entry models.AuditEntry
+		var before, after string
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetID,
+			&entry.Reason,
+			&entry.Details,
+			&before,
+			&after,
+			&entry.CreateAt,
+		); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования записи аудита: %w", err)
+		}
+		if before != "" {
+			entry.Before = []byte(before)
+		}
+		if after != "" {
+			entry.After = []byte(after)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_GetAuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "actor_id", "action", "target_type", "target_id", "reason", "details",
+		"snapshot_before", "snapshot_after", "create_at"}).
+		AddRow(2, 1, models.AuditPostDelete, models.TargetPost, 5, "спам", "", `{"id":5}`, "", time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM audit_log WHERE actor_id = \\$1 AND action = \\$2 AND create_at >= \\$3 ORDER BY id DESC LIMIT \\$4 OFFSET \\$5").
+		WithArgs(1, models.AuditPostDelete, from, 50, 0).
+		WillReturnRows(rows)
+
+	entries, err := repo.GetAuditLog(context.Background(), models.AuditFilter{
+		ActorID: 1,
+		Action:  models.AuditPostDelete,
+		From:    &from,
+		Limit:   50,
+	})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении журнала аудита: %s", err)
+	}
+
+	if len(entries) != 1 || string(entries[0].Before) != `{"id":5}` || entries[0].After != nil {
+		t.Errorf("ожидалась 1 запись со снимком до удаления, получено %+v", entries)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
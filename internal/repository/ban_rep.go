@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"time"
+)
+
+type BanRepository interface {
+	CreateBan(ctx context.Context, ban models.Ban, entry models.AuditEntry) (models.Ban, error)
+	GetActiveBans(ctx context.Context, userID int) ([]models.Ban, error)
+	GetBanByID(ctx context.Context, id int) (models.Ban, error)
+	DeleteBan(ctx context.Context, id int, entry models.AuditEntry) error
+	DeleteExpiredBans(ctx context.Context) (int64, error)
+}
+
+const banColumns = `id, user_id, category_id, shadow, reason, moderator_id, create_at, expires_at`
+
+// activeBan - условие на действующую блокировку, $n - текущее время.
+const activeBan = `(expires_at IS NULL OR expires_at > $%d)`
+
+// shadowHidden возвращает условие, скрывающее посты и треды пользователей
+// под теневой блокировкой от всех, кроме самого автора. viewerArg и nowArg -
+// номера параметров запроса с ID читателя и текущим временем.
+func shadowHidden(column string, viewerArg, nowArg int) string {
+	return fmt.Sprintf(`(%s = $%d OR %s NOT IN (SELECT user_id FROM bans WHERE shadow = 1 AND `+activeBan+`))`,
+		column, viewerArg, column, nowArg)
+}
+
+func banDest(ban *models.Ban, expiresAt *sql.NullTime) []any {
+	return []any{
+		&ban.ID,
+		&ban.UserID,
+		&ban.CategoryID,
+		&ban.Shadow,
+		&ban.Reason,
+		&ban.ModeratorID,
+		&ban.CreateAt,
+		expiresAt,
+	}
+}
+
+func (f *forumRepository) CreateBan(ctx context.Context, ban models.Ban, entry models.AuditEntry) (models.Ban, error) {
+	f.log(ctx).Debug("Блокировка пользователя",
+		zap.Int("userID", ban.UserID),
+		zap.Int("categoryID", ban.CategoryID),
+		zap.Bool("shadow", ban.Shadow))
+
+	var created models.Ban
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO bans (user_id, category_id, shadow, reason, moderator_id, create_at, expires_at)
+				  VALUES ($1, $2, $3, $4, $5, $6, $7)
+				  RETURNING ` + banColumns
+
+		var expiresAt sql.NullTime
+		if err := tx.QueryRowContext(ctx, query,
+			ban.UserID,
+			ban.CategoryID,
+			ban.Shadow,
+			ban.Reason,
+			ban.ModeratorID,
+			time.Now(),
+			ban.ExpiresAt,
+		).Scan(banDest(&created, &expiresAt)...); err != nil {
+			return fmt.Errorf("Ошибка при создании блокировки: %w", err)
+		}
+		if expiresAt.Valid {
+			created.ExpiresAt = &expiresAt.Time
+		}
+
+		entry.TargetID = created.UserID
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при блокировке пользователя",
+			zap.Int("userID", ban.UserID),
+			zap.Error(err))
+		return models.Ban{}, err
+	}
+
+	f.log(ctx).Info("Пользователь заблокирован",
+		zap.Int("id", created.ID),
+		zap.Int("userID", created.UserID),
+		zap.Int("categoryID", created.CategoryID),
+		zap.Bool("shadow", created.Shadow))
+	return created, nil
+}
+
+// GetActiveBans возвращает действующие блокировки пользователя,
+// а при userID = 0 - все действующие блокировки.
+func (f *forumRepository) GetActiveBans(ctx context.Context, userID int) ([]models.Ban, error) {
+	query := `SELECT ` + banColumns + ` FROM bans WHERE ` + fmt.Sprintf(activeBan, 1)
+	args := []any{time.Now()}
+	if userID != 0 {
+		query += ` AND user_id = $2`
+		args = append(args, userID)
+	}
+	query += ` ORDER BY create_at DESC`
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения блокировок",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения блокировок: %w", err)
+	}
+	defer rows.Close()
+
+	var bans []models.Ban
+	for rows.Next() {
+		var ban models.Ban
+		var expiresAt sql.NullTime
+		if err := rows.Scan(banDest(&ban, &expiresAt)...); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования блокировки: %w", err)
+		}
+		if expiresAt.Valid {
+			ban.ExpiresAt = &expiresAt.Time
+		}
+		bans = append(bans, ban)
+	}
+	return bans, nil
+}
+
+func (f *forumRepository) GetBanByID(ctx context.Context, id int) (models.Ban, error) {
+	query := `SELECT ` + banColumns + ` FROM bans WHERE id = $1`
+
+	var ban models.Ban
+	var expiresAt sql.NullTime
+	if err := f.db.QueryRowContext(ctx, query, id).Scan(banDest(&ban, &expiresAt)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Ban{}, models.ErrorNotFoundBan
+		}
+		return models.Ban{}, fmt.Errorf("Ошибка поиска блокировки по id: %w", err)
+	}
+	if expiresAt.Valid {
+		ban.ExpiresAt = &expiresAt.Time
+	}
+	return ban, nil
+}
+
+func (f *forumRepository) DeleteBan(ctx context.Context, id int, entry models.AuditEntry) error {
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM bans WHERE id = $1`, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка снятия блокировки: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrorNotFoundBan
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при снятии блокировки",
+			zap.Int("id", id),
+			zap.Error(err))
+		return err
+	}
+
+	f.log(ctx).Info("Блокировка снята", zap.Int("id", id))
+	return nil
+}
+
+func (f *forumRepository) DeleteExpiredBans(ctx context.Context) (int64, error) {
+	result, err := f.db.ExecContext(ctx, `DELETE FROM bans WHERE expires_at IS NOT NULL AND expires_at <= $1`, time.Now())
+	if err != nil {
+		f.log(ctx).Error("Ошибка удаления истекших блокировок", zap.Error(err))
+		return 0, fmt.Errorf("Ошибка удаления истекших блокировок: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка получения измененных строк: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_GetActiveBans(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	expires := time.Now().Add(time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "category_id", "shadow", "reason", "moderator_id", "create_at", "expires_at"}).
+		AddRow(1, 5, 0, false, "спам", 2, time.Now(), nil).
+		AddRow(2, 5, 3, true, "", 2, time.Now(), expires)
+
+	mock.ExpectQuery("SELECT (.+) FROM bans WHERE \\(expires_at IS NULL OR expires_at > \\$1\\) AND user_id = \\$2").
+		WithArgs(sqlmock.AnyArg(), 5).
+		WillReturnRows(rows)
+
+	bans, err := repo.GetActiveBans(context.Background(), 5)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении блокировок: %s", err)
+	}
+	if len(bans) != 2 || bans[0].ExpiresAt != nil || bans[1].ExpiresAt == nil || !bans[1].Shadow {
+		t.Errorf("неожиданные блокировки: %+v", bans)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_DeleteBan(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM bans WHERE id = \\$1").
+		WithArgs(9).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.DeleteBan(context.Background(), 9, models.AuditEntry{ActorID: 1, Action: models.AuditUserUnban})
+	if err != models.ErrorNotFoundBan {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotFoundBan, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
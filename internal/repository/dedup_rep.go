@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/dedup"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"time"
+)
+
+type DedupRepository interface {
+	SaveFingerprint(ctx context.Context, fp models.Fingerprint) error
+	GetFingerprints(ctx context.Context, since time.Time, limit int) ([]models.Fingerprint, error)
+	GetUserFingerprints(ctx context.Context, userID int, hash uint64, since time.Time) ([]models.Fingerprint, error)
+	GetSimilarFingerprints(ctx context.Context, hash uint64, since time.Time, limit int) ([]models.Fingerprint, error)
+	DeleteFingerprintsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Отпечаток хранится в INTEGER как int64 с тем же набором бит. Рядом
+// хранятся его полосы dedup.BandsOf: по их индексам ищутся почти повторы.
+
+const fingerprintColumns = `id, target_type, target_id, user_id, hash, create_at`
+
+func (f *forumRepository) SaveFingerprint(ctx context.Context, fp models.Fingerprint) error {
+	query := `INSERT INTO fingerprints (target_type, target_id, user_id, hash, create_at, band0, band1, band2, band3)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	bands := dedup.BandsOf(fp.Hash)
+	if _, err := f.db.ExecContext(ctx, query, fp.TargetType, fp.TargetID, fp.UserID, int64(fp.Hash), fp.CreateAt,
+		bands[0], bands[1], bands[2], bands[3]); err != nil {
+		f.log(ctx).Error("Ошибка сохранения отпечатка сообщения",
+			zap.String("targetType", fp.TargetType),
+			zap.Int("targetID", fp.TargetID),
+			zap.Error(err))
+		return fmt.Errorf("Ошибка сохранения отпечатка: %w", err)
+	}
+	return nil
+}
+
+// GetFingerprints возвращает не больше limit последних отпечатков с момента
+// since в порядке создания.
+func (f *forumRepository) GetFingerprints(ctx context.Context, since time.Time, limit int) ([]models.Fingerprint, error) {
+	query := `SELECT * FROM (
+			      SELECT ` + fingerprintColumns + `
+			      FROM fingerprints
+			      WHERE create_at >= $1
+			      ORDER BY create_at DESC
+			      LIMIT $2
+			  ) ORDER BY create_at ASC`
+
+	return f.queryFingerprints(ctx, query, since, limit)
+}
+
+// GetUserFingerprints возвращает точные копии отпечатка hash среди
+// сообщений пользователя userID с момента since.
+func (f *forumRepository) GetUserFingerprints(ctx context.Context, userID int, hash uint64, since time.Time) ([]models.Fingerprint, error) {
+	query := `SELECT ` + fingerprintColumns + `
+			  FROM fingerprints
+			  WHERE user_id = $1 AND hash = $2 AND create_at >= $3
+			  ORDER BY create_at ASC`
+
+	return f.queryFingerprints(ctx, query, userID, int64(hash), since)
+}
+
+// GetSimilarFingerprints возвращает не больше limit последних отпечатков
+// с момента since, совпадающих с hash хотя бы в одной полосе. Среди них
+// все почти повторы на расстоянии до dedup.MaxIndexedDistance.
+func (f *forumRepository) GetSimilarFingerprints(ctx context.Context, hash uint64, since time.Time, limit int) ([]models.Fingerprint, error) {
+	query := `SELECT ` + fingerprintColumns + `
+			  FROM fingerprints
+			  WHERE create_at >= $1 AND (band0 = $2 OR band1 = $3 OR band2 = $4 OR band3 = $5)
+			  ORDER BY create_at DESC
+			  LIMIT $6`
+
+	bands := dedup.BandsOf(hash)
+	return f.queryFingerprints(ctx, query, since, bands[0], bands[1], bands[2], bands[3], limit)
+}
+
+func (f *forumRepository) queryFingerprints(ctx context.Context, query string, args ...any) ([]models.Fingerprint, error) {
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения отпечатков сообщений", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения отпечатков: %w", err)
+	}
+	defer rows.Close()
+
+	var fps []models.Fingerprint
+	for rows.Next() {
+		var fp models.Fingerprint
+		var hash int64
+		if err := rows.Scan(&fp.ID, &fp.TargetType, &fp.TargetID, &fp.UserID, &hash, &fp.CreateAt); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования отпечатка: %w", err)
+		}
+		fp.Hash = uint64(hash)
+		fps = append(fps, fp)
+	}
+	return fps, nil
+}
+
+func (f *forumRepository) DeleteFingerprintsBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := f.db.ExecContext(ctx, `DELETE FROM fingerprints WHERE create_at < $1`, before)
+	if err != nil {
+		f.log(ctx).Error("Ошибка удаления старых отпечатков", zap.Error(err))
+		return 0, fmt.Errorf("Ошибка удаления старых отпечатков: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Ошибка получения измененных строк: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_SaveFingerprint(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	hash := uint64(1<<63 | 5)
+	mock.ExpectExec("INSERT INTO fingerprints").
+		WithArgs(models.TargetPost, 4, 9, int64(hash), sqlmock.AnyArg(), int64(5), int64(0), int64(0), int64(0x8000)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = repo.SaveFingerprint(context.Background(), models.Fingerprint{TargetType: models.TargetPost, TargetID: 4, UserID: 9, Hash: hash, CreateAt: time.Now()})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при сохранении отпечатка: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_GetFingerprints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	since := time.Now().Add(-time.Hour)
+	rows := sqlmock.NewRows([]string{"id", "target_type", "target_id", "user_id", "hash", "create_at"}).
+		AddRow(1, models.TargetPost, 4, 9, int64(-1), time.Now())
+
+	mock.ExpectQuery("SELECT (.+) FROM fingerprints WHERE create_at >= \\$1 ORDER BY create_at DESC LIMIT \\$2").
+		WithArgs(since, 100).
+		WillReturnRows(rows)
+
+	fps, err := repo.GetFingerprints(context.Background(), since, 100)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении отпечатков: %s", err)
+	}
+	if len(fps) != 1 || fps[0].Hash != ^uint64(0) {
+		t.Errorf("неожиданные отпечатки: %+v", fps)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_GetUserFingerprints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	since := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT (.+) FROM fingerprints WHERE user_id = \\$1 AND hash = \\$2 AND create_at >= \\$3").
+		WithArgs(9, int64(-1), since).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "target_type", "target_id", "user_id", "hash", "create_at"}).
+			AddRow(1, models.TargetPost, 4, 9, int64(-1), time.Now()))
+
+	fps, err := repo.GetUserFingerprints(context.Background(), 9, ^uint64(0), since)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении отпечатков: %s", err)
+	}
+	if len(fps) != 1 || fps[0].UserID != 9 {
+		t.Errorf("неожиданные отпечатки: %+v", fps)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_GetSimilarFingerprints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	since := time.Now().Add(-time.Hour)
+	mock.ExpectQuery("SELECT (.+) FROM fingerprints WHERE create_at >= \\$1 AND \\(band0 = \\$2 OR band1 = \\$3 OR band2 = \\$4 OR band3 = \\$5\\) ORDER BY create_at DESC LIMIT \\$6").
+		WithArgs(since, int64(0xcdef), int64(0x89ab), int64(0x4567), int64(0x0123), 50).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "target_type", "target_id", "user_id", "hash", "create_at"}))
+
+	fps, err := repo.GetSimilarFingerprints(context.Background(), 0x0123456789abcdef, since, 50)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении отпечатков: %s", err)
+	}
+	if len(fps) != 0 {
+		t.Errorf("неожиданные отпечатки: %+v", fps)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
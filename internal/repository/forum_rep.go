@@ -1,51 +1,130 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/logger"
 	"go.uber.org/zap"
 	"time"
 )
 
 type ForumRepository interface {
-	GetAllThreads() ([]models.Thread, error)
-	GetThreadByID(id int) (models.Thread, error)
-	CreateThread(thread models.Thread) (models.Thread, error)
-	DeleteThreadByID(id int) error
-	GetThreadsByUserID(userId int) ([]models.Thread, error)
-	CreatePost(post models.Post) (models.Post, error)
-	GetPostsByThreadID(threadID int) ([]models.Post, error)
-	DeletePostByID(id int) error
-	GetPostsByUserID(id int) ([]models.Post, error)
-	GetChatPosts(threadID int) ([]models.Post, error)
-	LinkPostToChat(chat models.Chat) error
-	CheckUserByID(user models.User, id int) (bool, error)
-	GetPostByID(id int) (models.Post, error)
-	EditThread(thread models.Thread, userID int) error
+	GetAllThreads(ctx context.Context, viewer models.Viewer) ([]models.Thread, error)
+	GetThreadByID(ctx context.Context, id int) (models.Thread, error)
+	GetThreadForViewer(ctx context.Context, id int, viewer models.Viewer) (models.Thread, error)
+	CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error)
+	DeleteThreadByID(ctx context.Context, id int, entry models.AuditEntry) error
+	GetThreadsByUserID(ctx context.Context, userId int, viewer models.Viewer) ([]models.Thread, error)
+	CreatePost(ctx context.Context, post models.Post) (models.Post, error)
+	GetPostsByThreadID(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error)
+	DeletePostByID(ctx context.Context, id int, entry models.AuditEntry) error
+	GetPostsByUserID(ctx context.Context, id int, viewer models.Viewer) ([]models.Post, error)
+	GetChatPosts(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error)
+	LinkPostToChat(ctx context.Context, chat models.Chat) error
+	GetPostByID(ctx context.Context, id int) (models.Post, error)
+	GetLastPostTime(ctx context.Context, threadID, userID int) (time.Time, error)
+	CountUserContent(ctx context.Context, userID int) (int, error)
+	EditThread(ctx context.Context, thread models.Thread, entry models.AuditEntry) error
+	UpdateThreadState(ctx context.Context, thread models.Thread, entry models.AuditEntry) error
+	GetActor(ctx context.Context, userID int) (models.Actor, error)
+
+	ModerationRepository
+	ReportRepository
+	BanRepository
+	PremodRepository
+	DedupRepository
+	SpamRepository
+	AuditRepository
+	TrashRepository
+	IntegrityRepository
+	PreferenceRepository
+
+	// WithTx выполняет fn в одной транзакции: все вызовы переданного fn
+	// репозитория фиксируются вместе или откатываются, если fn вернула
+	// ошибку. Вложенный WithTx продолжает внешнюю транзакцию.
+	WithTx(ctx context.Context, fn func(repo ForumRepository) error) error
 }
 
 type forumRepository struct {
-	db     *sql.DB
+	// db - пул соединений или, внутри WithTx, текущая транзакция.
+	db     dbtx
+	pool   *sql.DB
+	tx     *sql.Tx
 	logger *zap.Logger
 }
 
 func NewForumRepository(db *sql.DB, logger *zap.Logger) ForumRepository {
 	return &forumRepository{
 		db:     db,
+		pool:   db,
 		logger: logger,
 	}
 }
 
-func (f *forumRepository) GetAllThreads() ([]models.Thread, error) {
-	f.logger.Info("Получение всех тредов")
-	query := `SELECT id, title, content, create_at, user_id 
+// log возвращает логгер запроса из ctx, а вне запроса - логгер репозитория.
+func (f *forumRepository) log(ctx context.Context) *zap.Logger {
+	return logger.From(ctx, f.logger)
+}
+
+// threadColumns перечисляет колонки треда в порядке, ожидаемом threadDest.
+const threadColumns = `id, title, content, create_at, user_id, pinned, locked, archived, category_id, slow_mode, pending, deleted_at, deleted_by`
+
+// threadDest возвращает приемники для сканирования колонок threadColumns.
+// createAt передается отдельно, так как часть запросов читает дату строкой.
+func threadDest(thread *models.Thread, createAt any) []any {
+	return []any{
+		&thread.ID,
+		&thread.Title,
+		&thread.Content,
+		createAt,
+		&thread.UserID,
+		&thread.Pinned,
+		&thread.Locked,
+		&thread.Archived,
+		&thread.CategoryID,
+		&thread.SlowMode,
+		&thread.Pending,
+		&thread.DeletedAt,
+		&thread.DeletedBy,
+	}
+}
+
+// postColumns перечисляет колонки поста в порядке, ожидаемом postDest.
+const postColumns = `id, content, create_at, thread_id, user_id, pending, deleted_at, deleted_by`
+
+func postDest(post *models.Post) []any {
+	return []any{
+		&post.ID,
+		&post.Content,
+		&post.CreateAt,
+		&post.ThreadID,
+		&post.UserID,
+		&post.Pending,
+		&post.DeletedAt,
+		&post.DeletedBy,
+	}
+}
+
+// pendingHidden возвращает условие, скрывающее ожидающие проверки записи
+// от всех, кроме автора и модераторов. prefix - псевдоним таблицы с точкой
+// или пустая строка, viewerArg и moderatorArg - номера параметров запроса
+// с ID читателя и признаком модератора.
+func pendingHidden(prefix string, viewerArg, moderatorArg int) string {
+	return fmt.Sprintf(`(%spending = 0 OR %suser_id = $%d OR $%d)`, prefix, prefix, viewerArg, moderatorArg)
+}
+
+func (f *forumRepository) GetAllThreads(ctx context.Context, viewer models.Viewer) ([]models.Thread, error) {
+	f.log(ctx).Info("Получение всех тредов")
+	query := `SELECT ` + threadColumns + `
               FROM threads 
-              ORDER BY create_at DESC`
-	rows, err := f.db.Query(query)
+              WHERE deleted_at IS NULL AND ` + shadowHidden("user_id", 1, 2) + ` AND ` + pendingHidden("", 1, 3) + `
+              ORDER BY pinned DESC, create_at DESC`
+	rows, err := f.db.QueryContext(ctx, query, viewer.ID, time.Now(), viewer.Moderator)
 	if err != nil {
-		f.logger.Error("Ошибка получения тредов", zap.Error(err))
+		f.log(ctx).Error("Ошибка получения тредов", zap.Error(err))
 		return nil, fmt.Errorf("Ошибка получения тредов: %w", err)
 	}
 	defer rows.Close()
@@ -55,21 +134,15 @@ func (f *forumRepository) GetAllThreads() ([]models.Thread, error) {
 		var thread models.Thread
 		var createAtStr sql.NullString
 
-		if err := rows.Scan(
-			&thread.ID,
-			&thread.Title,
-			&thread.Content,
-			&createAtStr,
-			&thread.UserID,
-		); err != nil {
-			f.logger.Error("Ошибка сканирования треда", zap.Error(err))
+		if err := rows.Scan(threadDest(&thread, &createAtStr)...); err != nil {
+			f.log(ctx).Error("Ошибка сканирования треда", zap.Error(err))
 			return nil, fmt.Errorf("Ошибка сканирования треда: %w", err)
 		}
 
 		if createAtStr.Valid && createAtStr.String != "" {
 			createAt, err := time.Parse(time.RFC3339Nano, createAtStr.String)
 			if err != nil {
-				f.logger.Error("Ошибка парсинга даты",
+				f.log(ctx).Error("Ошибка парсинга даты",
 					zap.String("date", createAtStr.String),
 					zap.Error(err))
 				return nil, fmt.Errorf("Ошибка парсинга даты: %w", err)
@@ -82,187 +155,239 @@ func (f *forumRepository) GetAllThreads() ([]models.Thread, error) {
 		threads = append(threads, thread)
 	}
 
-	f.logger.Info("Успешное получение тредов", zap.Int("count", len(threads)))
+	f.log(ctx).Info("Успешное получение тредов", zap.Int("count", len(threads)))
 	return threads, nil
 }
 
-func (f *forumRepository) GetThreadByID(id int) (models.Thread, error) {
-	f.logger.Debug("Получение треда по ID", zap.Int("id", id))
-	query := `SELECT id, title, content, create_at, user_id 
+func (f *forumRepository) GetThreadByID(ctx context.Context, id int) (models.Thread, error) {
+	f.log(ctx).Debug("Получение треда по ID", zap.Int("id", id))
+	query := `SELECT ` + threadColumns + `
               FROM threads 
-              WHERE id = $1
+              WHERE id = $1 AND deleted_at IS NULL
               ORDER BY create_at DESC`
 
 	var thread models.Thread
-	err := f.db.QueryRow(query, id).Scan(
-		&thread.ID,
-		&thread.Title,
-		&thread.Content,
-		&thread.CreateAt,
-		&thread.UserID,
-	)
+	err := f.db.QueryRowContext(ctx, query, id).Scan(threadDest(&thread, &thread.CreateAt)...)
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			f.logger.Warn("Тред не найден", zap.Int("id", id))
+			f.log(ctx).Warn("Тред не найден", zap.Int("id", id))
 			return models.Thread{}, models.ErrorNotFoundThread
 		}
-		f.logger.Error("Ошибка при получении треда по ID",
+		f.log(ctx).Error("Ошибка при получении треда по ID",
 			zap.Int("id", id),
 			zap.Error(err))
 		return models.Thread{}, fmt.Errorf("Ошибка поиска треда по id: %w", err)
 	}
 
-	f.logger.Debug("Тред успешно получен",
+	f.log(ctx).Debug("Тред успешно получен",
 		zap.Int("id", id),
 		zap.Any("thread", thread))
 	return thread, nil
 }
 
-func (f *forumRepository) CreateThread(thread models.Thread) (models.Thread, error) {
-	f.logger.Debug("Создание нового треда",
+// GetThreadForViewer возвращает тред так, как его видит читатель viewer:
+// скрытый от него тред не находится. Для проверок прав и изменений
+// используется GetThreadByID.
+func (f *forumRepository) GetThreadForViewer(ctx context.Context, id int, viewer models.Viewer) (models.Thread, error) {
+	f.log(ctx).Debug("Получение треда по ID", zap.Int("id", id))
+	query := `SELECT ` + threadColumns + `
+              FROM threads 
+              WHERE id = $1 AND deleted_at IS NULL AND ` + shadowHidden("user_id", 2, 3) + ` AND ` + pendingHidden("", 2, 4)
+
+	var thread models.Thread
+	err := f.db.QueryRowContext(ctx, query, id, viewer.ID, time.Now(), viewer.Moderator).Scan(threadDest(&thread, &thread.CreateAt)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		f.log(ctx).Warn("Тред не найден", zap.Int("id", id))
+		return models.Thread{}, models.ErrorNotFoundThread
+	}
+	if err != nil {
+		f.log(ctx).Error("Ошибка при получении треда по ID",
+			zap.Int("id", id),
+			zap.Error(err))
+		return models.Thread{}, fmt.Errorf("Ошибка поиска треда по id: %w", err)
+	}
+	return thread, nil
+}
+
+func (f *forumRepository) CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	f.log(ctx).Debug("Создание нового треда",
 		zap.String("title", thread.Title),
 		zap.Int("userID", thread.UserID))
 
 	query :=
-		`INSERT INTO threads (title, content, create_at, user_id)
-         VALUES ($1, $2, $3, $4)
-         RETURNING id, title, content, create_at, user_id`
+		`INSERT INTO threads (title, content, create_at, user_id, category_id, pending)
+         VALUES ($1, $2, $3, $4, $5, $6)
+         RETURNING ` + threadColumns
 
 	var createThread models.Thread
-	err := f.db.QueryRow(
+	err := f.db.QueryRowContext(ctx,
 		query,
 		thread.Title,
 		thread.Content,
 		time.Now(),
 		thread.UserID,
-	).Scan(
-		&createThread.ID,
-		&createThread.Title,
-		&createThread.Content,
-		&createThread.CreateAt,
-		&createThread.UserID,
-	)
+		thread.CategoryID,
+		thread.Pending,
+	).Scan(threadDest(&createThread, &createThread.CreateAt)...)
 
 	if err != nil {
-		f.logger.Error("Ошибка при создании треда",
+		f.log(ctx).Error("Ошибка при создании треда",
 			zap.Any("thread", thread),
 			zap.Error(err))
 		return models.Thread{}, fmt.Errorf("Ошибка при создании треда: %w", err)
 	}
 
-	f.logger.Info("Тред успешно создан",
+	f.log(ctx).Info("Тред успешно создан",
 		zap.Int("id", createThread.ID),
 		zap.String("title", createThread.Title))
 	return createThread, nil
 }
 
-func (f *forumRepository) EditThread(thread models.Thread, userID int) error {
+func (f *forumRepository) EditThread(ctx context.Context, thread models.Thread, entry models.AuditEntry) error {
 	query := `UPDATE threads
 			  SET title=$1, content=$2, create_at=$3
 			  WHERE id=$4;`
 
-	valid, err := f.CheckUserByID(thread, userID)
-	if err != nil {
-		return fmt.Errorf("ошибка проверки прав: %w", err)
-	}
-	if !valid {
-		return fmt.Errorf("нет прав на редактирование")
-	}
+	return f.inTx(ctx, func(tx *sql.Tx) error {
+		exec, err := tx.ExecContext(ctx, query, thread.Title, thread.Content, thread.CreateAt, thread.ID)
+		if err != nil {
+			return err
+		}
 
-	exec, err := f.db.Exec(query, thread.Title, thread.Content, thread.CreateAt, thread.ID)
-	if err != nil {
-		return err
-	}
+		affected, err := exec.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return models.ErrorNotFoundThread
+		}
+
+		return insertAudit(ctx, tx, entry)
+	})
+}
+
+func (f *forumRepository) UpdateThreadState(ctx context.Context, thread models.Thread, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Изменение состояния треда",
+		zap.Int("id", thread.ID),
+		zap.Bool("pinned", thread.Pinned),
+		zap.Bool("locked", thread.Locked),
+		zap.Bool("archived", thread.Archived),
+		zap.Int("slowMode", thread.SlowMode))
 
-	affected, err := exec.RowsAffected()
+	query := `UPDATE threads
+			  SET pinned=$1, locked=$2, archived=$3, slow_mode=$4
+			  WHERE id=$5`
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, thread.Pinned, thread.Locked, thread.Archived, thread.SlowMode, thread.ID)
+		if err != nil {
+			f.log(ctx).Error("Ошибка при изменении состояния треда",
+				zap.Int("id", thread.ID),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка изменения состояния треда: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененых строк: %w", err)
+		}
+		if rowsAffected == 0 {
+			f.log(ctx).Warn("Тред не найден для изменения состояния", zap.Int("id", thread.ID))
+			return models.ErrorNotFoundThread
+		}
+		return insertAudit(ctx, tx, entry)
+	})
 	if err != nil {
 		return err
 	}
 
-	if affected == 0 {
-		return sql.ErrNoRows
-	}
-
+	f.log(ctx).Info("Состояние треда изменено", zap.Int("id", thread.ID))
 	return nil
 }
 
-func (f *forumRepository) DeleteThreadByID(id int) error {
-	f.logger.Debug("Удаление треда по ID", zap.Int("id", id))
-	query := `DELETE FROM threads WHERE id = $1`
-	result, err := f.db.Exec(query, id)
-	if err != nil {
-		f.logger.Error("Ошибка при удалении треда",
-			zap.Int("id", id),
-			zap.Error(err))
-		return fmt.Errorf("Ошибка удаления треда: %w", err)
-	}
+// DeleteThreadByID переносит тред в корзину. Посты треда остаются на месте
+// и возвращаются вместе с ним при восстановлении.
+func (f *forumRepository) DeleteThreadByID(ctx context.Context, id int, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Удаление треда по ID", zap.Int("id", id))
+	query := `UPDATE threads SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL`
+
+	var rowsAffected int64
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, time.Now(), entry.ActorID, id)
+		if err != nil {
+			f.log(ctx).Error("Ошибка при удалении треда",
+				zap.Int("id", id),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка удаления треда: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		f.logger.Error("Ошибка при получении количества удаленных строк",
-			zap.Int("id", id),
-			zap.Error(err))
-		return fmt.Errorf("Ошибка получения измененых строк: %w", err)
-	}
+		rowsAffected, err = result.RowsAffected()
+		if err != nil {
+			f.log(ctx).Error("Ошибка при получении количества удаленных строк",
+				zap.Int("id", id),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка получения измененых строк: %w", err)
+		}
 
-	if rowsAffected == 0 {
-		f.logger.Warn("Тред не найден для удаления", zap.Int("id", id))
-		return models.ErrorNotFoundThread
+		if rowsAffected == 0 {
+			f.log(ctx).Warn("Тред не найден для удаления", zap.Int("id", id))
+			return models.ErrorNotFoundThread
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
 	}
 
-	f.logger.Info("Тред успешно удален",
+	f.log(ctx).Info("Тред успешно удален",
 		zap.Int("id", id),
 		zap.Int64("rowsAffected", rowsAffected))
 	return nil
 }
 
-func (f *forumRepository) CreatePost(post models.Post) (models.Post, error) {
-	f.logger.Debug("Создание нового поста",
+func (f *forumRepository) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	f.log(ctx).Debug("Создание нового поста",
 		zap.Int("threadID", post.ThreadID),
 		zap.Int("userID", post.UserID))
 
 	query :=
-		`INSERT INTO posts (content, create_at, thread_id, user_id)
-		 VALUES ($1, $2, $3, $4)
-		 RETURNING id, content, create_at, thread_id, user_id`
+		`INSERT INTO posts (content, create_at, thread_id, user_id, pending)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING ` + postColumns
 
 	var createdPost models.Post
-	err := f.db.QueryRow(
+	err := f.db.QueryRowContext(ctx,
 		query,
 		post.Content,
 		post.CreateAt,
 		post.ThreadID,
 		post.UserID,
-	).Scan(
-		&createdPost.ID,
-		&createdPost.Content,
-		&createdPost.CreateAt,
-		&createdPost.ThreadID,
-		&createdPost.UserID,
-	)
+		post.Pending,
+	).Scan(postDest(&createdPost)...)
 	if err != nil {
-		f.logger.Error("Ошибка при создании поста",
+		f.log(ctx).Error("Ошибка при создании поста",
 			zap.Any("post", post),
 			zap.Error(err))
 		return models.Post{}, fmt.Errorf("Ошибка при создании поста: %w", err)
 	}
 
-	f.logger.Info("Пост успешно создан",
+	f.log(ctx).Info("Пост успешно создан",
 		zap.Int("id", createdPost.ID),
 		zap.Int("threadID", createdPost.ThreadID))
 	return createdPost, nil
 }
-func (f *forumRepository) GetPostsByThreadID(threadID int) ([]models.Post, error) {
-	f.logger.Debug("Получение постов по ID треда", zap.Int("threadID", threadID))
+func (f *forumRepository) GetPostsByThreadID(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error) {
+	f.log(ctx).Debug("Получение постов по ID треда", zap.Int("threadID", threadID))
 	query :=
-		`SELECT id, content, create_at, thread_id, user_id 
-		 FROM posts WHERE thread_id = $1`
+		`SELECT ` + postColumns + `
+		 FROM posts WHERE thread_id = $1 AND ` + shadowHidden("user_id", 2, 3) + ` AND ` + pendingHidden("", 2, 4)
 
 	var posts []models.Post
-	rows, err := f.db.Query(query, threadID)
+	rows, err := f.db.QueryContext(ctx, query, threadID, viewer.ID, time.Now(), viewer.Moderator)
 	if err != nil {
-		f.logger.Error("Ошибка при запросе постов треда",
+		f.log(ctx).Error("Ошибка при запросе постов треда",
 			zap.Int("threadID", threadID),
 			zap.Error(err))
 		return nil, fmt.Errorf("Ошибка поиска постов по id треда: %w", err)
@@ -271,43 +396,38 @@ func (f *forumRepository) GetPostsByThreadID(threadID int) ([]models.Post, error
 
 	for rows.Next() {
 		var post models.Post
-		if err := rows.Scan(
-			&post.ID,
-			&post.Content,
-			&post.CreateAt,
-			&post.ThreadID,
-			&post.UserID,
-		); err != nil {
+		if err := rows.Scan(postDest(&post)...); err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
-				f.logger.Warn("Посты для треда не найдены",
+				f.log(ctx).Warn("Посты для треда не найдены",
 					zap.Int("threadID", threadID))
 				return nil, models.ErrorNotFoundPost
 			}
-			f.logger.Error("Ошибка при сканировании поста",
+			f.log(ctx).Error("Ошибка при сканировании поста",
 				zap.Int("threadID", threadID),
 				zap.Error(err))
 			return nil, fmt.Errorf("Ошибка поиска поста по id треда: %w", err)
 		}
+		post.Tombstone()
 		posts = append(posts, post)
 	}
 
-	f.logger.Debug("Посты успешно получены",
+	f.log(ctx).Debug("Посты успешно получены",
 		zap.Int("threadID", threadID),
 		zap.Int("count", len(posts)))
 	return posts, nil
 }
 
-func (f *forumRepository) GetPostsByUserID(id int) ([]models.Post, error) {
-	f.logger.Debug("Получение постов по ID пользователя", zap.Int("userID", id))
+func (f *forumRepository) GetPostsByUserID(ctx context.Context, id int, viewer models.Viewer) ([]models.Post, error) {
+	f.log(ctx).Debug("Получение постов по ID пользователя", zap.Int("userID", id))
 	query := `
-        SELECT id, content, create_at, thread_id, user_id
+        SELECT ` + postColumns + `
         FROM posts
-        WHERE user_id = $1
+        WHERE user_id = $1 AND deleted_at IS NULL AND ` + shadowHidden("user_id", 2, 3) + ` AND ` + pendingHidden("", 2, 4) + `
         ORDER BY create_at DESC`
 
-	rows, err := f.db.Query(query, id)
+	rows, err := f.db.QueryContext(ctx, query, id, viewer.ID, time.Now(), viewer.Moderator)
 	if err != nil {
-		f.logger.Error("Ошибка выполнения запроса постов пользователя",
+		f.log(ctx).Error("Ошибка выполнения запроса постов пользователя",
 			zap.Int("userID", id),
 			zap.Error(err))
 		return nil, fmt.Errorf("ошибка выполнения запроса: %w", err)
@@ -318,14 +438,8 @@ func (f *forumRepository) GetPostsByUserID(id int) ([]models.Post, error) {
 
 	for rows.Next() {
 		var post models.Post
-		if err := rows.Scan(
-			&post.ID,
-			&post.Content,
-			&post.CreateAt,
-			&post.ThreadID,
-			&post.UserID,
-		); err != nil {
-			f.logger.Error("Ошибка сканирования поста",
+		if err := rows.Scan(postDest(&post)...); err != nil {
+			f.log(ctx).Error("Ошибка сканирования поста",
 				zap.Int("userID", id),
 				zap.Error(err))
 			return nil, fmt.Errorf("ошибка сканирования поста: %w", err)
@@ -334,81 +448,126 @@ func (f *forumRepository) GetPostsByUserID(id int) ([]models.Post, error) {
 	}
 
 	if err := rows.Err(); err != nil {
-		f.logger.Error("Ошибка при обработке результатов",
+		f.log(ctx).Error("Ошибка при обработке результатов",
 			zap.Int("userID", id),
 			zap.Error(err))
 		return nil, fmt.Errorf("ошибка при итерации по результатам: %w", err)
 	}
 
 	if len(posts) == 0 {
-		f.logger.Warn("Посты пользователя не найдены",
+		f.log(ctx).Warn("Посты пользователя не найдены",
 			zap.Int("userID", id))
 		return nil, models.ErrorNotFoundUser
 	}
 
-	f.logger.Debug("Посты пользователя успешно получены",
+	f.log(ctx).Debug("Посты пользователя успешно получены",
 		zap.Int("userID", id),
 		zap.Int("count", len(posts)))
 	return posts, nil
 }
 
-func (f *forumRepository) GetPostByID(id int) (models.Post, error) {
-	query := `SELECT * FROM posts WHERE id = $1`
+func (f *forumRepository) GetPostByID(ctx context.Context, id int) (models.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id = $1 AND deleted_at IS NULL`
 
 	var post models.Post
-	err := f.db.QueryRow(query, id).Scan(
-		&post.ID,
-		&post.Content,
-		&post.CreateAt,
-		&post.ThreadID,
-		&post.UserID,
-	)
+	err := f.db.QueryRowContext(ctx, query, id).Scan(postDest(&post)...)
 
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, models.ErrorNotFoundPost
+		}
 		return models.Post{}, err
 	}
 
 	return post, nil
 }
 
-func (f *forumRepository) DeletePostByID(id int) error {
-	f.logger.Debug("Удаление поста по ID", zap.Int("id", id))
-	query := `DELETE FROM posts WHERE id = $1`
-	result, err := f.db.Exec(query, id)
-	if err != nil {
-		f.logger.Error("Ошибка при удалении поста",
-			zap.Int("id", id),
+// GetLastPostTime возвращает время последнего поста пользователя в треде.
+// Если пользователь еще не писал в тред, возвращается нулевое время.
+func (f *forumRepository) GetLastPostTime(ctx context.Context, threadID, userID int) (time.Time, error) {
+	query := `SELECT create_at FROM posts
+			  WHERE thread_id = $1 AND user_id = $2
+			  ORDER BY create_at DESC
+			  LIMIT 1`
+
+	var last time.Time
+	if err := f.db.QueryRowContext(ctx, query, threadID, userID).Scan(&last); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		f.log(ctx).Error("Ошибка при получении времени последнего поста",
+			zap.Int("threadID", threadID),
+			zap.Int("userID", userID),
 			zap.Error(err))
-		return fmt.Errorf("Ошибка при удалении поста: %w", err)
+		return time.Time{}, fmt.Errorf("Ошибка получения времени последнего поста: %w", err)
 	}
+	return last, nil
+}
 
-	rowAffected, err := result.RowsAffected()
-	if err != nil {
-		f.logger.Error("Ошибка при получении количества удаленных строк",
-			zap.Int("id", id),
+// CountUserContent возвращает число опубликованных постов и тредов пользователя.
+func (f *forumRepository) CountUserContent(ctx context.Context, userID int) (int, error) {
+	query := `SELECT (SELECT COUNT(*) FROM posts WHERE user_id = $1 AND pending = 0 AND deleted_at IS NULL) +
+			         (SELECT COUNT(*) FROM threads WHERE user_id = $1 AND pending = 0 AND deleted_at IS NULL)`
+
+	var count int
+	if err := f.db.QueryRowContext(ctx, query, userID).Scan(&count); err != nil {
+		f.log(ctx).Error("Ошибка подсчета сообщений пользователя",
+			zap.Int("userID", userID),
 			zap.Error(err))
-		return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		return 0, fmt.Errorf("Ошибка подсчета сообщений пользователя: %w", err)
 	}
-	if rowAffected == 0 {
-		f.logger.Warn("Пост не найден для удаления",
-			zap.Int("id", id))
-		return models.ErrorNotFoundPost
+	return count, nil
+}
+
+// DeletePostByID переносит пост в корзину. В ленте треда вместо него
+// остается заглушка models.DeletedContent.
+func (f *forumRepository) DeletePostByID(ctx context.Context, id int, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Удаление поста по ID", zap.Int("id", id))
+	query := `UPDATE posts SET deleted_at = $1, deleted_by = $2 WHERE id = $3 AND deleted_at IS NULL`
+
+	var rowAffected int64
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, time.Now(), entry.ActorID, id)
+		if err != nil {
+			f.log(ctx).Error("Ошибка при удалении поста",
+				zap.Int("id", id),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка при удалении поста: %w", err)
+		}
+
+		rowAffected, err = result.RowsAffected()
+		if err != nil {
+			f.log(ctx).Error("Ошибка при получении количества удаленных строк",
+				zap.Int("id", id),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if rowAffected == 0 {
+			f.log(ctx).Warn("Пост не найден для удаления",
+				zap.Int("id", id))
+			return models.ErrorNotFoundPost
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
 	}
 
-	f.logger.Info("Пост успешно удален",
+	f.log(ctx).Info("Пост успешно удален",
 		zap.Int("id", id),
 		zap.Int64("rowsAffected", rowAffected))
 	return nil
 }
 
-func (f *forumRepository) GetThreadsByUserID(userId int) ([]models.Thread, error) {
-	f.logger.Debug("Получение тредов по ID пользователя", zap.Int("userID", userId))
-	query := `SELECT * FROM threads 
-         	  WHERE user_ID = $1
+func (f *forumRepository) GetThreadsByUserID(ctx context.Context, userId int, viewer models.Viewer) ([]models.Thread, error) {
+	f.log(ctx).Debug("Получение тредов по ID пользователя", zap.Int("userID", userId))
+	query := `SELECT ` + threadColumns + `
+         	  FROM threads 
+         	  WHERE user_ID = $1 AND deleted_at IS NULL AND ` + shadowHidden("user_id", 2, 3) + ` AND ` + pendingHidden("", 2, 4) + `
          	  ORDER BY create_at DESC`
-	threads, err := f.db.Query(query, userId)
+	threads, err := f.db.QueryContext(ctx, query, userId, viewer.ID, time.Now(), viewer.Moderator)
 	if err != nil {
-		f.logger.Error("Ошибка при запросе тредов пользователя",
+		f.log(ctx).Error("Ошибка при запросе тредов пользователя",
 			zap.Int("userID", userId),
 			zap.Error(err))
 		return nil, err
@@ -418,53 +577,53 @@ func (f *forumRepository) GetThreadsByUserID(userId int) ([]models.Thread, error
 	var searchThreads []models.Thread
 	for threads.Next() {
 		var thread models.Thread
-		if err = threads.Scan(&thread.ID, &thread.Title, &thread.Content, &thread.CreateAt, &thread.UserID); err != nil {
-			f.logger.Error("Ошибка при сканировании треда",
+		if err = threads.Scan(threadDest(&thread, &thread.CreateAt)...); err != nil {
+			f.log(ctx).Error("Ошибка при сканировании треда",
 				zap.Int("userID", userId),
 				zap.Error(err))
 			return nil, err
 		}
 		searchThreads = append(searchThreads, thread)
 	}
-	f.logger.Debug("Треды пользователя успешно получены",
+	f.log(ctx).Debug("Треды пользователя успешно получены",
 		zap.Int("userID", userId),
 		zap.Int("count", len(searchThreads)))
 	return searchThreads, nil
 }
 
-func (f *forumRepository) LinkPostToChat(chat models.Chat) error {
-	f.logger.Debug("Привязка поста к чату",
+func (f *forumRepository) LinkPostToChat(ctx context.Context, chat models.Chat) error {
+	f.log(ctx).Debug("Привязка поста к чату",
 		zap.Int("threadID", chat.ThreadID),
 		zap.Int("postID", chat.PostID),
 		zap.Int("userID", chat.UserID))
 
 	query := `INSERT INTO chat (thread_id, user_id, post_id) VALUES ($1, $2, $3)`
-	_, err := f.db.Exec(query, chat.ThreadID, chat.UserID, chat.PostID)
+	_, err := f.db.ExecContext(ctx, query, chat.ThreadID, chat.UserID, chat.PostID)
 	if err != nil {
-		f.logger.Error("Ошибка при привязке поста к чату",
+		f.log(ctx).Error("Ошибка при привязке поста к чату",
 			zap.Any("chat", chat),
 			zap.Error(err))
 		return err
 	}
 
-	f.logger.Info("Пост успешно привязан к чату",
+	f.log(ctx).Info("Пост успешно привязан к чату",
 		zap.Int("threadID", chat.ThreadID),
 		zap.Int("postID", chat.PostID))
 	return nil
 }
 
-func (f *forumRepository) GetChatPosts(threadID int) ([]models.Post, error) {
-	f.logger.Debug("Получение постов чата по ID треда", zap.Int("threadID", threadID))
+func (f *forumRepository) GetChatPosts(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error) {
+	f.log(ctx).Debug("Получение постов чата по ID треда", zap.Int("threadID", threadID))
 	query := `
-		SELECT p.id, p.content, p.create_at, p.thread_id, p.user_id
+		SELECT p.id, p.content, p.create_at, p.thread_id, p.user_id, p.pending, p.deleted_at, p.deleted_by
 		FROM posts p
 		JOIN chat c ON p.id = c.post_id
-		WHERE c.thread_id = $1
+		WHERE c.thread_id = $1 AND ` + shadowHidden("p.user_id", 2, 3) + ` AND ` + pendingHidden("p.", 2, 4) + `
 		ORDER BY p.create_at ASC`
 
-	rows, err := f.db.Query(query, threadID)
+	rows, err := f.db.QueryContext(ctx, query, threadID, viewer.ID, time.Now(), viewer.Moderator)
 	if err != nil {
-		f.logger.Error("Ошибка при запросе постов чата",
+		f.log(ctx).Error("Ошибка при запросе постов чата",
 			zap.Int("threadID", threadID),
 			zap.Error(err))
 		return nil, err
@@ -474,48 +633,51 @@ func (f *forumRepository) GetChatPosts(threadID int) ([]models.Post, error) {
 	var posts []models.Post
 	for rows.Next() {
 		var post models.Post
-		if err := rows.Scan(&post.ID, &post.Content, &post.CreateAt, &post.ThreadID, &post.UserID); err != nil {
-			f.logger.Error("Ошибка при сканировании поста чата",
+		if err := rows.Scan(postDest(&post)...); err != nil {
+			f.log(ctx).Error("Ошибка при сканировании поста чата",
 				zap.Int("threadID", threadID),
 				zap.Error(err))
 			return nil, err
 		}
+		post.Tombstone()
 		posts = append(posts, post)
 	}
 
-	f.logger.Debug("Посты чата успешно получены",
+	f.log(ctx).Debug("Посты чата успешно получены",
 		zap.Int("threadID", threadID),
 		zap.Int("count", len(posts)))
 	return posts, nil
 }
 
-func (f *forumRepository) CheckUserByID(any models.User, id int) (bool, error) {
-	query := `SELECT id, name, email, role FROM users WHERE id = $1`
-
-	var user struct {
-		ID    int    `json:"id"`
-		Name  string `json:"name"`
-		Email string `json:"email"`
-		Role  string `json:"role"`
-	}
-
-	err := f.db.QueryRow(query, id).Scan(
-		&user.ID,
-		&user.Name,
-		&user.Email,
-		&user.Role,
-	)
+func (f *forumRepository) GetActor(ctx context.Context, userID int) (models.Actor, error) {
+	actor := models.Actor{ID: userID}
 
+	err := f.db.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1`, userID).Scan(&actor.Role)
 	if err != nil {
-		return false, err
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Actor{}, models.ErrorNotFoundUser
+		}
+		f.log(ctx).Error("Ошибка при получении роли пользователя",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return models.Actor{}, fmt.Errorf("Ошибка получения роли пользователя: %w", err)
 	}
 
-	if user.Role == "admin" {
-		return true, nil
+	rows, err := f.db.QueryContext(ctx, `SELECT category_id FROM category_moderators WHERE user_id = $1`, userID)
+	if err != nil {
+		f.log(ctx).Error("Ошибка при получении категорий модератора",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return models.Actor{}, fmt.Errorf("Ошибка получения категорий модератора: %w", err)
 	}
+	defer rows.Close()
 
-	if user.ID != any.USER_ID() {
-		return false, fmt.Errorf("Нет прав: %d %d %s", user.ID, any.USER_ID(), user.Role)
+	for rows.Next() {
+		var categoryID int
+		if err := rows.Scan(&categoryID); err != nil {
+			return models.Actor{}, fmt.Errorf("Ошибка сканирования категории модератора: %w", err)
+		}
+		actor.Categories = append(actor.Categories, categoryID)
 	}
-	return true, nil
+	return actor, nil
 }
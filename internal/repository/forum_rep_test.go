@@ -1,6 +1,10 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/fire9900/forum/internal/models"
 	"go.uber.org/zap"
@@ -13,6 +17,29 @@ func setupLogger() *zap.Logger {
 	return logger
 }
 
+var threadRowColumns = []string{"id", "title", "content", "create_at", "user_id", "pinned", "locked", "archived", "category_id", "slow_mode", "pending", "deleted_at", "deleted_by"}
+
+// threadRow возвращает значения колонок threadRowColumns для треда.
+func threadRow(thread models.Thread, createAt driver.Value) []driver.Value {
+	return []driver.Value{
+		thread.ID,
+		thread.Title,
+		thread.Content,
+		createAt,
+		thread.UserID,
+		thread.Pinned,
+		thread.Locked,
+		thread.Archived,
+		thread.CategoryID,
+		thread.SlowMode,
+		thread.Pending,
+		nil,
+		thread.DeletedBy,
+	}
+}
+
+var postRowColumns = []string{"id", "content", "create_at", "thread_id", "user_id", "pending", "deleted_at", "deleted_by"}
+
 func Test_forumRepository_GetAllThreads(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -23,14 +50,15 @@ func Test_forumRepository_GetAllThreads(t *testing.T) {
 	logger := setupLogger()
 	repo := NewForumRepository(db, logger)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "content", "create_at", "user_id"}).
-		AddRow(1, "Thread 1", "Content 1", time.Now(), 1).
-		AddRow(2, "Thread 2", "Content 2", time.Now(), 2)
+	rows := sqlmock.NewRows(threadRowColumns).
+		AddRow(threadRow(models.Thread{ID: 1, Title: "Thread 1", Content: "Content 1", UserID: 1, Pinned: true}, time.Now().Format(time.RFC3339Nano))...).
+		AddRow(threadRow(models.Thread{ID: 2, Title: "Thread 2", Content: "Content 2", UserID: 2}, time.Now().Format(time.RFC3339Nano))...)
 
-	mock.ExpectQuery("SELECT id, title, content, create_at, user_id FROM threads ORDER BY create_at DESC").
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE deleted_at IS NULL AND \\(user_id = \\$1 OR user_id NOT IN \\(SELECT user_id FROM bans WHERE shadow = 1 (.+)\\)\\) AND \\(pending = 0 OR user_id = \\$1 OR \\$3\\) ORDER BY pinned DESC, create_at DESC").
+		WithArgs(7, sqlmock.AnyArg(), false).
 		WillReturnRows(rows)
 
-	threads, err := repo.GetAllThreads()
+	threads, err := repo.GetAllThreads(context.Background(), models.Viewer{ID: 7})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении тем: %s", err)
 	}
@@ -55,14 +83,14 @@ func Test_forumRepository_GetThreadByID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testID := 1
-	rows := sqlmock.NewRows([]string{"id", "title", "content", "create_at", "user_id"}).
-		AddRow(testID, "Test Thread", "Test Content", time.Now(), 1)
+	rows := sqlmock.NewRows(threadRowColumns).
+		AddRow(threadRow(models.Thread{ID: testID, Title: "Test Thread", Content: "Test Content", UserID: 1, Locked: true}, time.Now())...)
 
-	mock.ExpectQuery("SELECT id, title, content, create_at, user_id FROM threads WHERE id = \\$1 ORDER BY create_at DESC").
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE id = \\$1 AND deleted_at IS NULL ORDER BY create_at DESC").
 		WithArgs(testID).
 		WillReturnRows(rows)
 
-	thread, err := repo.GetThreadByID(testID)
+	thread, err := repo.GetThreadByID(context.Background(), testID)
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении темы: %s", err)
 	}
@@ -71,6 +99,40 @@ func Test_forumRepository_GetThreadByID(t *testing.T) {
 		t.Errorf("ожидался ID темы %d, получено %d", testID, thread.ID)
 	}
 
+	if !thread.Locked {
+		t.Error("ожидалось, что тема будет закрыта")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_GetThreadForViewer(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE id = \\$1 AND deleted_at IS NULL AND \\(user_id = \\$2 OR user_id NOT IN (.+)\\) AND \\(pending = 0 OR user_id = \\$2 OR \\$4\\)").
+		WithArgs(1, 5, sqlmock.AnyArg(), false).
+		WillReturnRows(sqlmock.NewRows(threadRowColumns).
+			AddRow(threadRow(models.Thread{ID: 1, Title: "Тред", Content: "Текст", UserID: 5}, time.Now())...))
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE id = \\$1").
+		WithArgs(2, 0, sqlmock.AnyArg(), false).
+		WillReturnRows(sqlmock.NewRows(threadRowColumns))
+
+	thread, err := repo.GetThreadForViewer(context.Background(), 1, models.Viewer{ID: 5})
+	if err != nil || thread.ID != 1 {
+		t.Errorf("ожидался тред 1, получено %+v, %v", thread, err)
+	}
+	if _, err := repo.GetThreadForViewer(context.Background(), 2, models.Viewer{}); !errors.Is(err, models.ErrorNotFoundThread) {
+		t.Errorf("для скрытого треда ожидалась ErrorNotFoundThread, получено %v", err)
+	}
+
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("не все ожидания были выполнены: %s", err)
 	}
@@ -92,11 +154,13 @@ func Test_forumRepository_CreateThread(t *testing.T) {
 		UserID:  1,
 	}
 
+	created := newThread
+	created.ID = 1
 	mock.ExpectQuery("INSERT INTO threads").
-		WithArgs(newThread.Title, newThread.Content, sqlmock.AnyArg(), newThread.UserID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "create_at", "user_id"}).AddRow(1, newThread.Title, newThread.Content, time.Now(), newThread.UserID))
+		WithArgs(newThread.Title, newThread.Content, sqlmock.AnyArg(), newThread.UserID, newThread.CategoryID, false).
+		WillReturnRows(sqlmock.NewRows(threadRowColumns).AddRow(threadRow(created, time.Now())...))
 
-	createdThread, err := repo.CreateThread(newThread)
+	createdThread, err := repo.CreateThread(context.Background(), newThread)
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при создании темы: %s", err)
 	}
@@ -121,11 +185,22 @@ func Test_forumRepository_DeleteThreadByID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testID := 1
-	mock.ExpectExec("DELETE FROM threads WHERE id = \\$1").
-		WithArgs(testID).
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE threads SET deleted_at = \\$1, deleted_by = \\$2 WHERE id = \\$3 AND deleted_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), 1, testID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	err = repo.DeleteThreadByID(testID)
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(1, models.AuditThreadDelete, models.TargetThread, testID, "", "", `{"id":1}`, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeleteThreadByID(context.Background(), testID, models.AuditEntry{
+		ActorID:    1,
+		Action:     models.AuditThreadDelete,
+		TargetType: models.TargetThread,
+		TargetID:   testID,
+		Before:     []byte(`{"id":1}`),
+	})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при удалении темы: %s", err)
 	}
@@ -135,6 +210,46 @@ func Test_forumRepository_DeleteThreadByID(t *testing.T) {
 	}
 }
 
+func Test_forumRepository_UpdateThreadState(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger()
+	repo := NewForumRepository(db, logger)
+
+	thread := models.Thread{ID: 1, Pinned: true, Locked: true, SlowMode: 30}
+	entry := models.AuditEntry{ActorID: 3, Action: models.AuditThreadState, TargetType: models.TargetThread, TargetID: thread.ID}
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE threads SET pinned=\\$1, locked=\\$2, archived=\\$3, slow_mode=\\$4 WHERE id=\\$5").
+		WithArgs(true, true, false, 30, thread.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(3, models.AuditThreadState, models.TargetThread, thread.ID, "", "", "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.UpdateThreadState(context.Background(), thread, entry); err != nil {
+		t.Errorf("ошибка не ожидалась при изменении состояния темы: %s", err)
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE threads SET pinned=\\$1, locked=\\$2, archived=\\$3, slow_mode=\\$4 WHERE id=\\$5").
+		WithArgs(false, false, false, 0, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.UpdateThreadState(context.Background(), models.Thread{ID: 2}, entry); err != models.ErrorNotFoundThread {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotFoundThread, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
 func Test_forumRepository_CreatePost(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -153,11 +268,11 @@ func Test_forumRepository_CreatePost(t *testing.T) {
 	}
 
 	mock.ExpectQuery("INSERT INTO posts").
-		WithArgs(newPost.Content, newPost.CreateAt, newPost.ThreadID, newPost.UserID).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "content", "create_at", "thread_id", "user_id"}).
-			AddRow(1, newPost.Content, newPost.CreateAt, newPost.ThreadID, newPost.UserID))
+		WithArgs(newPost.Content, newPost.CreateAt, newPost.ThreadID, newPost.UserID, false).
+		WillReturnRows(sqlmock.NewRows(postRowColumns).
+			AddRow(1, newPost.Content, newPost.CreateAt, newPost.ThreadID, newPost.UserID, false, nil, 0))
 
-	createdPost, err := repo.CreatePost(newPost)
+	createdPost, err := repo.CreatePost(context.Background(), newPost)
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при создании поста: %s", err)
 	}
@@ -182,21 +297,24 @@ func Test_forumRepository_GetPostsByThreadID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testThreadID := 1
-	rows := sqlmock.NewRows([]string{"id", "content", "create_at", "thread_id", "user_id"}).
-		AddRow(1, "Post 1", time.Now(), testThreadID, 1).
-		AddRow(2, "Post 2", time.Now(), testThreadID, 2)
+	rows := sqlmock.NewRows(postRowColumns).
+		AddRow(1, "Post 1", time.Now(), testThreadID, 1, false, nil, 0).
+		AddRow(2, "Post 2", time.Now(), testThreadID, 2, false, time.Now(), 2)
 
-	mock.ExpectQuery("SELECT id, content, create_at, thread_id, user_id FROM posts WHERE thread_id = \\$1").
-		WithArgs(testThreadID).
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE thread_id = \\$1 AND \\(user_id = \\$2 OR user_id NOT IN \\(SELECT user_id FROM bans WHERE shadow = 1 (.+)\\)\\) AND \\(pending = 0 OR user_id = \\$2 OR \\$4\\)").
+		WithArgs(testThreadID, 3, sqlmock.AnyArg(), false).
 		WillReturnRows(rows)
 
-	posts, err := repo.GetPostsByThreadID(testThreadID)
+	posts, err := repo.GetPostsByThreadID(context.Background(), testThreadID, models.Viewer{ID: 3})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении постов: %s", err)
 	}
 
 	if len(posts) != 2 {
-		t.Errorf("ожидалось 2 поста, получено %d", len(posts))
+		t.Fatalf("ожидалось 2 поста, получено %d", len(posts))
+	}
+	if posts[1].Content != models.DeletedContent || posts[1].UserID != 0 {
+		t.Errorf("удаленный пост должен остаться заглушкой, получено %+v", posts[1])
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
@@ -215,15 +333,15 @@ func Test_forumRepository_GetPostsByUserID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testUserID := 1
-	rows := sqlmock.NewRows([]string{"id", "content", "create_at", "thread_id", "user_id"}).
-		AddRow(1, "Post 1", time.Now(), 1, testUserID).
-		AddRow(2, "Post 2", time.Now(), 2, testUserID)
+	rows := sqlmock.NewRows(postRowColumns).
+		AddRow(1, "Post 1", time.Now(), 1, testUserID, false, nil, 0).
+		AddRow(2, "Post 2", time.Now(), 2, testUserID, true, nil, 0)
 
-	mock.ExpectQuery("SELECT id, content, create_at, thread_id, user_id FROM posts WHERE user_id = \\$1 ORDER BY create_at DESC").
-		WithArgs(testUserID).
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE user_id = \\$1 AND (.+) ORDER BY create_at DESC").
+		WithArgs(testUserID, testUserID, sqlmock.AnyArg(), false).
 		WillReturnRows(rows)
 
-	posts, err := repo.GetPostsByUserID(testUserID)
+	posts, err := repo.GetPostsByUserID(context.Background(), testUserID, models.Viewer{ID: testUserID})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении постов: %s", err)
 	}
@@ -248,14 +366,14 @@ func Test_forumRepository_GetPostByID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testPostID := 1
-	rows := sqlmock.NewRows([]string{"id", "content", "create_at", "thread_id", "user_id"}).
-		AddRow(testPostID, "Test Post", time.Now(), 1, 1)
+	rows := sqlmock.NewRows(postRowColumns).
+		AddRow(testPostID, "Test Post", time.Now(), 1, 1, false, nil, 0)
 
-	mock.ExpectQuery("SELECT \\* FROM posts WHERE id = \\$1").
+	mock.ExpectQuery("SELECT (.+) FROM posts WHERE id = \\$1").
 		WithArgs(testPostID).
 		WillReturnRows(rows)
 
-	post, err := repo.GetPostByID(testPostID)
+	post, err := repo.GetPostByID(context.Background(), testPostID)
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении поста: %s", err)
 	}
@@ -269,6 +387,70 @@ func Test_forumRepository_GetPostByID(t *testing.T) {
 	}
 }
 
+func Test_forumRepository_GetLastPostTime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger()
+	repo := NewForumRepository(db, logger)
+
+	last := time.Now().Add(-10 * time.Second)
+	mock.ExpectQuery("SELECT create_at FROM posts WHERE thread_id = \\$1 AND user_id = \\$2").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"create_at"}).AddRow(last))
+
+	got, err := repo.GetLastPostTime(context.Background(), 1, 2)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении времени поста: %s", err)
+	}
+	if !got.Equal(last) {
+		t.Errorf("ожидалось время %v, получено %v", last, got)
+	}
+
+	mock.ExpectQuery("SELECT create_at FROM posts WHERE thread_id = \\$1 AND user_id = \\$2").
+		WithArgs(1, 3).
+		WillReturnError(sql.ErrNoRows)
+
+	got, err = repo.GetLastPostTime(context.Background(), 1, 3)
+	if err != nil || !got.IsZero() {
+		t.Errorf("ожидалось нулевое время без ошибки, получено %v, %v", got, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_CountUserContent(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	logger := setupLogger()
+	repo := NewForumRepository(db, logger)
+
+	mock.ExpectQuery("SELECT \\(SELECT COUNT\\(\\*\\) FROM posts WHERE user_id = \\$1 AND pending = 0 AND deleted_at IS NULL\\) (.+) FROM threads WHERE user_id = \\$1 AND pending = 0 AND deleted_at IS NULL\\)").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(7))
+
+	count, err := repo.CountUserContent(context.Background(), 4)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при подсчете сообщений: %s", err)
+	}
+	if count != 7 {
+		t.Errorf("ожидалось 7 сообщений, получено %d", count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
 func Test_forumRepository_DeletePostByID(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -280,11 +462,22 @@ func Test_forumRepository_DeletePostByID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testPostID := 1
-	mock.ExpectExec("DELETE FROM posts WHERE id = \\$1").
-		WithArgs(testPostID).
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET deleted_at = \\$1, deleted_by = \\$2 WHERE id = \\$3 AND deleted_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), 2, testPostID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
-
-	err = repo.DeletePostByID(testPostID)
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(2, models.AuditPostDelete, models.TargetPost, testPostID, "спам", "", "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.DeletePostByID(context.Background(), testPostID, models.AuditEntry{
+		ActorID:    2,
+		Action:     models.AuditPostDelete,
+		TargetType: models.TargetPost,
+		TargetID:   testPostID,
+		Reason:     "спам",
+	})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при удалении поста: %s", err)
 	}
@@ -305,15 +498,15 @@ func Test_forumRepository_GetThreadsByUserID(t *testing.T) {
 	repo := NewForumRepository(db, logger)
 
 	testUserID := 1
-	rows := sqlmock.NewRows([]string{"id", "title", "content", "create_at", "user_id"}).
-		AddRow(1, "Thread 1", "Content 1", time.Now(), testUserID).
-		AddRow(2, "Thread 2", "Content 2", time.Now(), testUserID)
+	rows := sqlmock.NewRows(threadRowColumns).
+		AddRow(threadRow(models.Thread{ID: 1, Title: "Thread 1", Content: "Content 1", UserID: testUserID}, time.Now())...).
+		AddRow(threadRow(models.Thread{ID: 2, Title: "Thread 2", Content: "Content 2", UserID: testUserID}, time.Now())...)
 
-	mock.ExpectQuery("SELECT \\* FROM threads WHERE user_ID = \\$1 ORDER BY create_at DESC").
-		WithArgs(testUserID).
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE user_ID = \\$1 AND deleted_at IS NULL AND \\(user_id = \\$2 (.+)\\) AND \\(pending = 0 OR user_id = \\$2 OR \\$4\\) ORDER BY create_at DESC").
+		WithArgs(testUserID, testUserID, sqlmock.AnyArg(), false).
 		WillReturnRows(rows)
 
-	threads, err := repo.GetThreadsByUserID(testUserID)
+	threads, err := repo.GetThreadsByUserID(context.Background(), testUserID, models.Viewer{ID: testUserID})
 	if err != nil {
 		t.Errorf("ошибка не ожидалась при получении тем: %s", err)
 	}
@@ -327,7 +520,7 @@ func Test_forumRepository_GetThreadsByUserID(t *testing.T) {
 	}
 }
 
-func Test_forumRepository_CheckUserByID(t *testing.T) {
+func Test_forumRepository_GetActor(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
@@ -337,36 +530,28 @@ func Test_forumRepository_CheckUserByID(t *testing.T) {
 	logger := setupLogger()
 	repo := NewForumRepository(db, logger)
 
-	adminID := 1
-	rows := sqlmock.NewRows([]string{"id", "name", "email", "role"}).
-		AddRow(adminID, "admin", "admin@test.com", "admin")
-
-	mock.ExpectQuery("SELECT id, name, email, role FROM users WHERE id = \\$1").
-		WithArgs(adminID).
-		WillReturnRows(rows)
+	userID := 2
+	mock.ExpectQuery("SELECT role FROM users WHERE id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow(models.RoleUser))
+	mock.ExpectQuery("SELECT category_id FROM category_moderators WHERE user_id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"category_id"}).AddRow(3).AddRow(5))
 
-	valid, err := repo.CheckUserByID(models.Thread{UserID: adminID}, adminID)
+	actor, err := repo.GetActor(context.Background(), userID)
 	if err != nil {
-		t.Errorf("ошибка не ожидалась при проверке пользователя-администратора: %s", err)
+		t.Errorf("ошибка не ожидалась при получении пользователя: %s", err)
 	}
-	if !valid {
-		t.Error("ожидалось, что администратор будет валидным")
+	if actor.Role != models.RoleUser || !actor.Moderates(5) || actor.Moderates(4) {
+		t.Errorf("неожиданный пользователь: %+v", actor)
 	}
 
-	userID := 2
-	rows = sqlmock.NewRows([]string{"id", "name", "email", "role"}).
-		AddRow(userID, "user", "user@test.com", "user")
-
-	mock.ExpectQuery("SELECT id, name, email, role FROM users WHERE id = \\$1").
-		WithArgs(userID).
-		WillReturnRows(rows)
+	mock.ExpectQuery("SELECT role FROM users WHERE id = \\$1").
+		WithArgs(99).
+		WillReturnError(sql.ErrNoRows)
 
-	valid, err = repo.CheckUserByID(models.Thread{UserID: userID}, userID)
-	if err != nil {
-		t.Errorf("ошибка не ожидалась при проверке обычного пользователя: %s", err)
-	}
-	if !valid {
-		t.Error("ожидалось, что обычный пользователь будет валидным, когда ID совпадают")
+	if _, err := repo.GetActor(context.Background(), 99); err != models.ErrorNotFoundUser {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotFoundUser, err)
 	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
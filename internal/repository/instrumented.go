@@ -0,0 +1,447 @@
+package repository
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/metrics"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/tracing"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/fire9900/forum/internal/repository")
+
+// instrumentedRepository замеряет длительность каждого метода репозитория
+// и открывает на него спан трассировки. Методы лишь делегируют вызов next,
+// поэтому сами запросы и обработка ошибок остаются в forumRepository.
+type instrumentedRepository struct {
+	next    ForumRepository
+	metrics *metrics.Metrics
+}
+
+// NewInstrumentedRepository оборачивает repo так, что длительность и исход
+// каждого вызова попадают в метрику forum_db_query_duration_seconds и в
+// спан ForumRepository.<метод>.
+func NewInstrumentedRepository(repo ForumRepository, m *metrics.Metrics) ForumRepository {
+	return &instrumentedRepository{next: repo, metrics: m}
+}
+
+// start открывает спан метода. Возвращенная функция завершает спан и
+// учитывает вызов в метрике; ей передается адрес именованной ошибки метода.
+func (r *instrumentedRepository) start(ctx context.Context, method string) (context.Context, func(err *error)) {
+	begin := time.Now()
+	ctx, span := tracer.Start(ctx, "ForumRepository."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemSqlite))
+	return ctx, func(err *error) {
+		r.metrics.ObserveQuery(method, *err, time.Since(begin))
+		tracing.End(span, *err)
+	}
+}
+
+// WithTx замеряет транзакцию целиком, а вызовы внутри нее - по отдельности.
+func (r *instrumentedRepository) WithTx(ctx context.Context, fn func(repo ForumRepository) error) (err error) {
+	ctx, finish := r.start(ctx, "WithTx")
+	defer finish(&err)
+	return r.next.WithTx(ctx, func(tx ForumRepository) error {
+		return fn(&instrumentedRepository{next: tx, metrics: r.metrics})
+	})
+}
+
+func (r *instrumentedRepository) GetAllThreads(ctx context.Context, viewer models.Viewer) (_ []models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetAllThreads")
+	defer finish(&err)
+	return r.next.GetAllThreads(ctx, viewer)
+}
+
+func (r *instrumentedRepository) GetThreadByID(ctx context.Context, id int) (_ models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetThreadByID")
+	defer finish(&err)
+	return r.next.GetThreadByID(ctx, id)
+}
+
+func (r *instrumentedRepository) GetThreadForViewer(ctx context.Context, id int, viewer models.Viewer) (_ models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetThreadForViewer")
+	defer finish(&err)
+	return r.next.GetThreadForViewer(ctx, id, viewer)
+}
+
+func (r *instrumentedRepository) CreateThread(ctx context.Context, thread models.Thread) (_ models.Thread, err error) {
+	ctx, finish := r.start(ctx, "CreateThread")
+	defer finish(&err)
+	return r.next.CreateThread(ctx, thread)
+}
+
+func (r *instrumentedRepository) DeleteThreadByID(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "DeleteThreadByID")
+	defer finish(&err)
+	return r.next.DeleteThreadByID(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) GetThreadsByUserID(ctx context.Context, userId int, viewer models.Viewer) (_ []models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetThreadsByUserID")
+	defer finish(&err)
+	return r.next.GetThreadsByUserID(ctx, userId, viewer)
+}
+
+func (r *instrumentedRepository) CreatePost(ctx context.Context, post models.Post) (_ models.Post, err error) {
+	ctx, finish := r.start(ctx, "CreatePost")
+	defer finish(&err)
+	return r.next.CreatePost(ctx, post)
+}
+
+func (r *instrumentedRepository) GetPostsByThreadID(ctx context.Context, threadID int, viewer models.Viewer) (_ []models.Post, err error) {
+	ctx, finish := r.start(ctx, "GetPostsByThreadID")
+	defer finish(&err)
+	return r.next.GetPostsByThreadID(ctx, threadID, viewer)
+}
+
+func (r *instrumentedRepository) DeletePostByID(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "DeletePostByID")
+	defer finish(&err)
+	return r.next.DeletePostByID(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) GetPostsByUserID(ctx context.Context, id int, viewer models.Viewer) (_ []models.Post, err error) {
+	ctx, finish := r.start(ctx, "GetPostsByUserID")
+	defer finish(&err)
+	return r.next.GetPostsByUserID(ctx, id, viewer)
+}
+
+func (r *instrumentedRepository) GetChatPosts(ctx context.Context, threadID int, viewer models.Viewer) (_ []models.Post, err error) {
+	ctx, finish := r.start(ctx, "GetChatPosts")
+	defer finish(&err)
+	return r.next.GetChatPosts(ctx, threadID, viewer)
+}
+
+func (r *instrumentedRepository) LinkPostToChat(ctx context.Context, chat models.Chat) (err error) {
+	ctx, finish := r.start(ctx, "LinkPostToChat")
+	defer finish(&err)
+	return r.next.LinkPostToChat(ctx, chat)
+}
+
+func (r *instrumentedRepository) GetPostByID(ctx context.Context, id int) (_ models.Post, err error) {
+	ctx, finish := r.start(ctx, "GetPostByID")
+	defer finish(&err)
+	return r.next.GetPostByID(ctx, id)
+}
+
+func (r *instrumentedRepository) GetLastPostTime(ctx context.Context, threadID, userID int) (_ time.Time, err error) {
+	ctx, finish := r.start(ctx, "GetLastPostTime")
+	defer finish(&err)
+	return r.next.GetLastPostTime(ctx, threadID, userID)
+}
+
+func (r *instrumentedRepository) CountUserContent(ctx context.Context, userID int) (_ int, err error) {
+	ctx, finish := r.start(ctx, "CountUserContent")
+	defer finish(&err)
+	return r.next.CountUserContent(ctx, userID)
+}
+
+func (r *instrumentedRepository) EditThread(ctx context.Context, thread models.Thread, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "EditThread")
+	defer finish(&err)
+	return r.next.EditThread(ctx, thread, entry)
+}
+
+func (r *instrumentedRepository) UpdateThreadState(ctx context.Context, thread models.Thread, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "UpdateThreadState")
+	defer finish(&err)
+	return r.next.UpdateThreadState(ctx, thread, entry)
+}
+
+func (r *instrumentedRepository) GetActor(ctx context.Context, userID int) (_ models.Actor, err error) {
+	ctx, finish := r.start(ctx, "GetActor")
+	defer finish(&err)
+	return r.next.GetActor(ctx, userID)
+}
+
+func (r *instrumentedRepository) GetAllCategories(ctx context.Context) (_ []models.Category, err error) {
+	ctx, finish := r.start(ctx, "GetAllCategories")
+	defer finish(&err)
+	return r.next.GetAllCategories(ctx)
+}
+
+func (r *instrumentedRepository) GetCategoryByID(ctx context.Context, id int) (_ models.Category, err error) {
+	ctx, finish := r.start(ctx, "GetCategoryByID")
+	defer finish(&err)
+	return r.next.GetCategoryByID(ctx, id)
+}
+
+func (r *instrumentedRepository) CreateCategory(ctx context.Context, category models.Category, entry models.AuditEntry) (_ models.Category, err error) {
+	ctx, finish := r.start(ctx, "CreateCategory")
+	defer finish(&err)
+	return r.next.CreateCategory(ctx, category, entry)
+}
+
+func (r *instrumentedRepository) AddCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "AddCategoryModerator")
+	defer finish(&err)
+	return r.next.AddCategoryModerator(ctx, categoryID, userID, entry)
+}
+
+func (r *instrumentedRepository) RemoveCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "RemoveCategoryModerator")
+	defer finish(&err)
+	return r.next.RemoveCategoryModerator(ctx, categoryID, userID, entry)
+}
+
+func (r *instrumentedRepository) GetThreadRedirect(ctx context.Context, oldID int) (_ int, err error) {
+	ctx, finish := r.start(ctx, "GetThreadRedirect")
+	defer finish(&err)
+	return r.next.GetThreadRedirect(ctx, oldID)
+}
+
+func (r *instrumentedRepository) MergeThreads(ctx context.Context, fromID, toID int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "MergeThreads")
+	defer finish(&err)
+	return r.next.MergeThreads(ctx, fromID, toID, entry)
+}
+
+func (r *instrumentedRepository) SplitThread(ctx context.Context, req models.SplitRequest, thread models.Thread, entry models.AuditEntry) (_ models.Thread, err error) {
+	ctx, finish := r.start(ctx, "SplitThread")
+	defer finish(&err)
+	return r.next.SplitThread(ctx, req, thread, entry)
+}
+
+func (r *instrumentedRepository) MoveThread(ctx context.Context, threadID, categoryID int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "MoveThread")
+	defer finish(&err)
+	return r.next.MoveThread(ctx, threadID, categoryID, entry)
+}
+
+func (r *instrumentedRepository) CreateReport(ctx context.Context, report models.Report) (_ models.Report, err error) {
+	ctx, finish := r.start(ctx, "CreateReport")
+	defer finish(&err)
+	return r.next.CreateReport(ctx, report)
+}
+
+func (r *instrumentedRepository) GetReports(ctx context.Context, filter models.ReportFilter) (_ []models.Report, err error) {
+	ctx, finish := r.start(ctx, "GetReports")
+	defer finish(&err)
+	return r.next.GetReports(ctx, filter)
+}
+
+func (r *instrumentedRepository) GetReportByID(ctx context.Context, id int) (_ models.Report, err error) {
+	ctx, finish := r.start(ctx, "GetReportByID")
+	defer finish(&err)
+	return r.next.GetReportByID(ctx, id)
+}
+
+func (r *instrumentedRepository) ResolveReport(ctx context.Context, report models.Report, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "ResolveReport")
+	defer finish(&err)
+	return r.next.ResolveReport(ctx, report, entry)
+}
+
+func (r *instrumentedRepository) CreateNotification(ctx context.Context, notification models.Notification) (err error) {
+	ctx, finish := r.start(ctx, "CreateNotification")
+	defer finish(&err)
+	return r.next.CreateNotification(ctx, notification)
+}
+
+func (r *instrumentedRepository) GetNotifications(ctx context.Context, userID int) (_ []models.Notification, err error) {
+	ctx, finish := r.start(ctx, "GetNotifications")
+	defer finish(&err)
+	return r.next.GetNotifications(ctx, userID)
+}
+
+func (r *instrumentedRepository) MarkNotificationRead(ctx context.Context, id, userID int) (err error) {
+	ctx, finish := r.start(ctx, "MarkNotificationRead")
+	defer finish(&err)
+	return r.next.MarkNotificationRead(ctx, id, userID)
+}
+
+func (r *instrumentedRepository) CreateBan(ctx context.Context, ban models.Ban, entry models.AuditEntry) (_ models.Ban, err error) {
+	ctx, finish := r.start(ctx, "CreateBan")
+	defer finish(&err)
+	return r.next.CreateBan(ctx, ban, entry)
+}
+
+func (r *instrumentedRepository) GetActiveBans(ctx context.Context, userID int) (_ []models.Ban, err error) {
+	ctx, finish := r.start(ctx, "GetActiveBans")
+	defer finish(&err)
+	return r.next.GetActiveBans(ctx, userID)
+}
+
+func (r *instrumentedRepository) GetBanByID(ctx context.Context, id int) (_ models.Ban, err error) {
+	ctx, finish := r.start(ctx, "GetBanByID")
+	defer finish(&err)
+	return r.next.GetBanByID(ctx, id)
+}
+
+func (r *instrumentedRepository) DeleteBan(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "DeleteBan")
+	defer finish(&err)
+	return r.next.DeleteBan(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) DeleteExpiredBans(ctx context.Context) (_ int64, err error) {
+	ctx, finish := r.start(ctx, "DeleteExpiredBans")
+	defer finish(&err)
+	return r.next.DeleteExpiredBans(ctx)
+}
+
+func (r *instrumentedRepository) GetPendingThreads(ctx context.Context) (_ []models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetPendingThreads")
+	defer finish(&err)
+	return r.next.GetPendingThreads(ctx)
+}
+
+func (r *instrumentedRepository) GetPendingPosts(ctx context.Context) (_ []models.PendingPost, err error) {
+	ctx, finish := r.start(ctx, "GetPendingPosts")
+	defer finish(&err)
+	return r.next.GetPendingPosts(ctx)
+}
+
+func (r *instrumentedRepository) ApproveThread(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "ApproveThread")
+	defer finish(&err)
+	return r.next.ApproveThread(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) ApprovePost(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "ApprovePost")
+	defer finish(&err)
+	return r.next.ApprovePost(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) RejectThread(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "RejectThread")
+	defer finish(&err)
+	return r.next.RejectThread(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) RejectPost(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "RejectPost")
+	defer finish(&err)
+	return r.next.RejectPost(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) SaveFingerprint(ctx context.Context, fp models.Fingerprint) (err error) {
+	ctx, finish := r.start(ctx, "SaveFingerprint")
+	defer finish(&err)
+	return r.next.SaveFingerprint(ctx, fp)
+}
+
+func (r *instrumentedRepository) GetFingerprints(ctx context.Context, since time.Time, limit int) (_ []models.Fingerprint, err error) {
+	ctx, finish := r.start(ctx, "GetFingerprints")
+	defer finish(&err)
+	return r.next.GetFingerprints(ctx, since, limit)
+}
+
+func (r *instrumentedRepository) GetUserFingerprints(ctx context.Context, userID int, hash uint64, since time.Time) (_ []models.Fingerprint, err error) {
+	ctx, finish := r.start(ctx, "GetUserFingerprints")
+	defer finish(&err)
+	return r.next.GetUserFingerprints(ctx, userID, hash, since)
+}
+
+func (r *instrumentedRepository) GetSimilarFingerprints(ctx context.Context, hash uint64, since time.Time, limit int) (_ []models.Fingerprint, err error) {
+	ctx, finish := r.start(ctx, "GetSimilarFingerprints")
+	defer finish(&err)
+	return r.next.GetSimilarFingerprints(ctx, hash, since, limit)
+}
+
+func (r *instrumentedRepository) DeleteFingerprintsBefore(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, finish := r.start(ctx, "DeleteFingerprintsBefore")
+	defer finish(&err)
+	return r.next.DeleteFingerprintsBefore(ctx, before)
+}
+
+func (r *instrumentedRepository) GetSpamModel(ctx context.Context) (_ models.SpamModel, err error) {
+	ctx, finish := r.start(ctx, "GetSpamModel")
+	defer finish(&err)
+	return r.next.GetSpamModel(ctx)
+}
+
+func (r *instrumentedRepository) TrainSpam(ctx context.Context, feedback models.SpamFeedback, tokens map[string]int, entry models.AuditEntry) (previous string, err error) {
+	ctx, finish := r.start(ctx, "TrainSpam")
+	defer finish(&err)
+	return r.next.TrainSpam(ctx, feedback, tokens, entry)
+}
+
+func (r *instrumentedRepository) SaveSpamScore(ctx context.Context, score models.SpamScore) (err error) {
+	ctx, finish := r.start(ctx, "SaveSpamScore")
+	defer finish(&err)
+	return r.next.SaveSpamScore(ctx, score)
+}
+
+func (r *instrumentedRepository) GetSpamScores(ctx context.Context, min float64, limit int) (_ []models.SpamScore, err error) {
+	ctx, finish := r.start(ctx, "GetSpamScores")
+	defer finish(&err)
+	return r.next.GetSpamScores(ctx, min, limit)
+}
+
+func (r *instrumentedRepository) AddAuditEntry(ctx context.Context, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "AddAuditEntry")
+	defer finish(&err)
+	return r.next.AddAuditEntry(ctx, entry)
+}
+
+func (r *instrumentedRepository) GetAuditLog(ctx context.Context, filter models.AuditFilter) (_ []models.AuditEntry, err error) {
+	ctx, finish := r.start(ctx, "GetAuditLog")
+	defer finish(&err)
+	return r.next.GetAuditLog(ctx, filter)
+}
+
+func (r *instrumentedRepository) GetTrash(ctx context.Context, filter models.TrashFilter) (_ models.Trash, err error) {
+	ctx, finish := r.start(ctx, "GetTrash")
+	defer finish(&err)
+	return r.next.GetTrash(ctx, filter)
+}
+
+func (r *instrumentedRepository) GetDeletedThread(ctx context.Context, id int) (_ models.Thread, err error) {
+	ctx, finish := r.start(ctx, "GetDeletedThread")
+	defer finish(&err)
+	return r.next.GetDeletedThread(ctx, id)
+}
+
+func (r *instrumentedRepository) GetDeletedPost(ctx context.Context, id int) (_ models.Post, err error) {
+	ctx, finish := r.start(ctx, "GetDeletedPost")
+	defer finish(&err)
+	return r.next.GetDeletedPost(ctx, id)
+}
+
+func (r *instrumentedRepository) RestoreThread(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "RestoreThread")
+	defer finish(&err)
+	return r.next.RestoreThread(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) RestorePost(ctx context.Context, id int, entry models.AuditEntry) (err error) {
+	ctx, finish := r.start(ctx, "RestorePost")
+	defer finish(&err)
+	return r.next.RestorePost(ctx, id, entry)
+}
+
+func (r *instrumentedRepository) PurgeDeleted(ctx context.Context, before time.Time) (_ int64, err error) {
+	ctx, finish := r.start(ctx, "PurgeDeleted")
+	defer finish(&err)
+	return r.next.PurgeDeleted(ctx, before)
+}
+
+func (r *instrumentedRepository) CheckIntegrity(ctx context.Context) (_ []models.Orphans, err error) {
+	ctx, finish := r.start(ctx, "CheckIntegrity")
+	defer finish(&err)
+	return r.next.CheckIntegrity(ctx)
+}
+
+func (r *instrumentedRepository) RepairIntegrity(ctx context.Context) (_ []models.Orphans, err error) {
+	ctx, finish := r.start(ctx, "RepairIntegrity")
+	defer finish(&err)
+	return r.next.RepairIntegrity(ctx)
+}
+
+func (r *instrumentedRepository) GetUserLanguage(ctx context.Context, userID int) (_ string, err error) {
+	ctx, finish := r.start(ctx, "GetUserLanguage")
+	defer finish(&err)
+	return r.next.GetUserLanguage(ctx, userID)
+}
+
+func (r *instrumentedRepository) SetUserLanguage(ctx context.Context, userID int, language string) (err error) {
+	ctx, finish := r.start(ctx, "SetUserLanguage")
+	defer finish(&err)
+	return r.next.SetUserLanguage(ctx, userID, language)
+}
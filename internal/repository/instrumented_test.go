@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/metrics"
+	"github.com/fire9900/forum/internal/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace/noop"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	return rec.Body.String()
+}
+
+func Test_instrumentedRepository(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	m := metrics.New()
+	repo := NewInstrumentedRepository(NewForumRepository(db, setupLogger()), m)
+	post := models.Post{Content: "Привет", CreateAt: time.Now(), ThreadID: 2, UserID: 5}
+
+	mock.ExpectQuery("SELECT (.+) FROM threads").WillReturnRows(sqlmock.NewRows(threadRowColumns))
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO posts").
+		WillReturnRows(sqlmock.NewRows(postRowColumns).AddRow(7, post.Content, post.CreateAt, 2, 5, false, nil, 0))
+	mock.ExpectExec("INSERT INTO chat").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if _, err := repo.GetThreadByID(context.Background(), 1); err == nil {
+		t.Fatal("ожидалась ошибка для отсутствующего треда")
+	}
+	err = repo.WithTx(context.Background(), func(repo ForumRepository) error {
+		created, err := repo.CreatePost(context.Background(), post)
+		if err != nil {
+			return err
+		}
+		return repo.LinkPostToChat(context.Background(), models.Chat{ThreadID: post.ThreadID, UserID: post.UserID, PostID: created.ID})
+	})
+	if err != nil {
+		t.Fatalf("ошибка создания поста: %v", err)
+	}
+
+	body := scrape(t, m)
+	for _, want := range []string{
+		`forum_db_query_duration_seconds_count{method="GetThreadByID",status="error"} 1`,
+		`forum_db_query_duration_seconds_count{method="WithTx",status="ok"} 1`,
+		`forum_db_query_duration_seconds_count{method="CreatePost",status="ok"} 1`,
+		`forum_db_query_duration_seconds_count{method="LinkPostToChat",status="ok"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("нет метрики %q", want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания выполнены: %s", err)
+	}
+}
+
+func Test_instrumentedRepository_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(noop.NewTracerProvider())
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewInstrumentedRepository(NewForumRepository(db, setupLogger()), nil)
+	mock.ExpectQuery("SELECT (.+) FROM threads").WillReturnRows(sqlmock.NewRows(threadRowColumns))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	if _, err := repo.GetThreadByID(ctx, 1); err == nil {
+		t.Fatal("ожидалась ошибка для отсутствующего треда")
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ожидалось 2 спана, получено %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "ForumRepository.GetThreadByID" {
+		t.Errorf("неожиданное имя спана %q", span.Name())
+	}
+	if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Error("спан репозитория должен быть дочерним для спана запроса")
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("ожидался статус ошибки, получено %v", span.Status().Code)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+)
+
+type IntegrityRepository interface {
+	CheckIntegrity(ctx context.Context) ([]models.Orphans, error)
+	RepairIntegrity(ctx context.Context) ([]models.Orphans, error)
+}
+
+// integrityChecks перечисляет ссылки, защищенные внешними ключами. Порядок
+// важен при исправлении: посты удаляются раньше строк чата, которые на них
+// ссылаются.
+var integrityChecks = []models.Orphans{
+	{Table: "posts", Column: "thread_id", Parent: "threads"},
+	{Table: "chat", Column: "thread_id", Parent: "threads"},
+	{Table: "chat", Column: "post_id", Parent: "posts"},
+}
+
+func orphanCondition(check models.Orphans) string {
+	return fmt.Sprintf(`%s NOT IN (SELECT id FROM %s)`, check.Column, check.Parent)
+}
+
+// CheckIntegrity подсчитывает строки, ссылающиеся на отсутствующие треды и
+// посты. Такие строки могли остаться от удалений до появления внешних ключей.
+func (f *forumRepository) CheckIntegrity(ctx context.Context) ([]models.Orphans, error) {
+	report := make([]models.Orphans, 0, len(integrityChecks))
+	for _, check := range integrityChecks {
+		query := `SELECT COUNT(*) FROM ` + check.Table + ` WHERE ` + orphanCondition(check)
+		if err := f.db.QueryRowContext(ctx, query).Scan(&check.Count); err != nil {
+			f.log(ctx).Error("Ошибка проверки целостности",
+				zap.String("table", check.Table),
+				zap.String("column", check.Column),
+				zap.Error(err))
+			return nil, fmt.Errorf("Ошибка проверки целостности %s.%s: %w", check.Table, check.Column, err)
+		}
+		report = append(report, check)
+	}
+	return report, nil
+}
+
+// RepairIntegrity одной транзакцией удаляет строки, найденные CheckIntegrity,
+// и возвращает число удаленных строк по каждой ссылке.
+func (f *forumRepository) RepairIntegrity(ctx context.Context) ([]models.Orphans, error) {
+	report := make([]models.Orphans, 0, len(integrityChecks))
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		for _, check := range integrityChecks {
+			result, err := tx.ExecContext(ctx, `DELETE FROM `+check.Table+` WHERE `+orphanCondition(check))
+			if err != nil {
+				return fmt.Errorf("Ошибка удаления ссылок %s.%s: %w", check.Table, check.Column, err)
+			}
+			if check.Count, err = result.RowsAffected(); err != nil {
+				return fmt.Errorf("Ошибка получения удаленных строк: %w", err)
+			}
+			report = append(report, check)
+		}
+		return nil
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка исправления целостности", zap.Error(err))
+		return nil, err
+	}
+	return report, nil
+}
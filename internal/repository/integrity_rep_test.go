@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+)
+
+func Test_forumRepository_CheckIntegrity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM posts WHERE thread_id NOT IN \\(SELECT id FROM threads\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM chat WHERE thread_id NOT IN \\(SELECT id FROM threads\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM chat WHERE post_id NOT IN \\(SELECT id FROM posts\\)").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	report, err := repo.CheckIntegrity(context.Background())
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при проверке целостности: %s", err)
+	}
+	if len(report) != 3 || report[0].Count != 2 || report[2].Count != 1 {
+		t.Errorf("неверный отчет о целостности: %+v", report)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_RepairIntegrity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM posts WHERE thread_id NOT IN \\(SELECT id FROM threads\\)").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM chat WHERE thread_id NOT IN \\(SELECT id FROM threads\\)").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM chat WHERE post_id NOT IN \\(SELECT id FROM posts\\)").
+		WillReturnError(sqlmock.ErrCancelled)
+	mock.ExpectRollback()
+
+	if _, err := repo.RepairIntegrity(context.Background()); err == nil {
+		t.Error("ожидалась ошибка при сбое удаления")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,338 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"time"
+)
+
+type ModerationRepository interface {
+	GetAllCategories(ctx context.Context) ([]models.Category, error)
+	GetCategoryByID(ctx context.Context, id int) (models.Category, error)
+	CreateCategory(ctx context.Context, category models.Category, entry models.AuditEntry) (models.Category, error)
+	AddCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error
+	RemoveCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error
+	GetThreadRedirect(ctx context.Context, oldID int) (int, error)
+	MergeThreads(ctx context.Context, fromID, toID int, entry models.AuditEntry) error
+	SplitThread(ctx context.Context, req models.SplitRequest, thread models.Thread, entry models.AuditEntry) (models.Thread, error)
+	MoveThread(ctx context.Context, threadID, categoryID int, entry models.AuditEntry) error
+}
+
+func threadExists(ctx context.Context, tx *sql.Tx, id int) error {
+	var found int
+	err := tx.QueryRowContext(ctx, `SELECT id FROM threads WHERE id = $1 AND deleted_at IS NULL`, id).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.ErrorNotFoundThread
+	}
+	return err
+}
+
+func (f *forumRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	f.log(ctx).Debug("Получение всех категорий")
+	query := `SELECT id, name, description, create_at FROM categories ORDER BY name`
+
+	rows, err := f.db.QueryContext(ctx, query)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения категорий", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения категорий: %w", err)
+	}
+	defer rows.Close()
+
+	var categories []models.Category
+	for rows.Next() {
+		var category models.Category
+		if err := rows.Scan(
+			&category.ID,
+			&category.Name,
+			&category.Description,
+			&category.CreateAt,
+		); err != nil {
+			f.log(ctx).Error("Ошибка сканирования категории", zap.Error(err))
+			return nil, fmt.Errorf("Ошибка сканирования категории: %w", err)
+		}
+		categories = append(categories, category)
+	}
+
+	f.log(ctx).Debug("Категории успешно получены", zap.Int("count", len(categories)))
+	return categories, nil
+}
+
+func (f *forumRepository) GetCategoryByID(ctx context.Context, id int) (models.Category, error) {
+	query := `SELECT id, name, description, create_at FROM categories WHERE id = $1`
+
+	var category models.Category
+	err := f.db.QueryRowContext(ctx, query, id).Scan(
+		&category.ID,
+		&category.Name,
+		&category.Description,
+		&category.CreateAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Category{}, models.ErrorNotFoundCategory
+		}
+		f.log(ctx).Error("Ошибка при получении категории",
+			zap.Int("id", id),
+			zap.Error(err))
+		return models.Category{}, fmt.Errorf("Ошибка поиска категории по id: %w", err)
+	}
+	return category, nil
+}
+
+// CreateCategory создает категорию. Идентификатор и снимок созданной
+// категории дописываются в запись аудита.
+func (f *forumRepository) CreateCategory(ctx context.Context, category models.Category, entry models.AuditEntry) (models.Category, error) {
+	f.log(ctx).Debug("Создание новой категории", zap.String("name", category.Name))
+
+	query := `INSERT INTO categories (name, description, create_at)
+			  VALUES ($1, $2, $3)
+			  RETURNING id, name, description, create_at`
+
+	var created models.Category
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, query, category.Name, category.Description, time.Now()).Scan(
+			&created.ID,
+			&created.Name,
+			&created.Description,
+			&created.CreateAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		entry.TargetID = created.ID
+		entry.After = models.Snapshot(created)
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при создании категории",
+			zap.String("name", category.Name),
+			zap.Error(err))
+		return models.Category{}, fmt.Errorf("Ошибка при создании категории: %w", err)
+	}
+
+	f.log(ctx).Info("Категория успешно создана",
+		zap.Int("id", created.ID),
+		zap.String("name", created.Name))
+	return created, nil
+}
+
+func (f *forumRepository) AddCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error {
+	query := `INSERT OR IGNORE INTO category_moderators (category_id, user_id, create_at)
+			  VALUES ($1, $2, $3)`
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, query, categoryID, userID, time.Now()); err != nil {
+			return err
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при назначении модератора категории",
+			zap.Int("categoryID", categoryID),
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return fmt.Errorf("Ошибка назначения модератора категории: %w", err)
+	}
+
+	f.log(ctx).Info("Модератор категории назначен",
+		zap.Int("categoryID", categoryID),
+		zap.Int("userID", userID))
+	return nil
+}
+
+func (f *forumRepository) RemoveCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error {
+	query := `DELETE FROM category_moderators WHERE category_id = $1 AND user_id = $2`
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, categoryID, userID)
+		if err != nil {
+			f.log(ctx).Error("Ошибка при снятии модератора категории",
+				zap.Int("categoryID", categoryID),
+				zap.Int("userID", userID),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка снятия модератора категории: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrorNotFoundModerator
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	f.log(ctx).Info("Модератор категории снят",
+		zap.Int("categoryID", categoryID),
+		zap.Int("userID", userID))
+	return nil
+}
+
+func (f *forumRepository) GetThreadRedirect(ctx context.Context, oldID int) (int, error) {
+	query := `SELECT new_id FROM thread_redirects WHERE old_id = $1`
+
+	var newID int
+	if err := f.db.QueryRowContext(ctx, query, oldID).Scan(&newID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, models.ErrorNotFoundThread
+		}
+		return 0, fmt.Errorf("Ошибка поиска перенаправления треда: %w", err)
+	}
+	return newID, nil
+}
+
+func (f *forumRepository) MergeThreads(ctx context.Context, fromID, toID int, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Объединение тредов",
+		zap.Int("fromID", fromID),
+		zap.Int("toID", toID))
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		if err := threadExists(ctx, tx, fromID); err != nil {
+			return err
+		}
+		if err := threadExists(ctx, tx, toID); err != nil {
+			return err
+		}
+
+		steps := []struct {
+			query string
+			args  []any
+		}{
+			{`UPDATE posts SET thread_id = $1 WHERE thread_id = $2`, []any{toID, fromID}},
+			{`UPDATE chat SET thread_id = $1 WHERE thread_id = $2`, []any{toID, fromID}},
+			{`UPDATE thread_redirects SET new_id = $1 WHERE new_id = $2`, []any{toID, fromID}},
+			{`INSERT INTO thread_redirects (old_id, new_id, create_at) VALUES ($1, $2, $3)`, []any{fromID, toID, time.Now()}},
+			{`DELETE FROM threads WHERE id = $1`, []any{fromID}},
+		}
+		for _, step := range steps {
+			if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+				return fmt.Errorf("Ошибка объединения тредов: %w", err)
+			}
+		}
+
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при объединении тредов",
+			zap.Int("fromID", fromID),
+			zap.Int("toID", toID),
+			zap.Error(err))
+		return err
+	}
+
+	f.log(ctx).Info("Треды успешно объединены",
+		zap.Int("fromID", fromID),
+		zap.Int("toID", toID))
+	return nil
+}
+
+func (f *forumRepository) SplitThread(ctx context.Context, req models.SplitRequest, thread models.Thread, entry models.AuditEntry) (models.Thread, error) {
+	f.log(ctx).Debug("Разделение треда",
+		zap.Int("threadID", req.ThreadID),
+		zap.Int("fromPostID", req.FromPostID),
+		zap.Int("toPostID", req.ToPostID))
+
+	var created models.Thread
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		query := `INSERT INTO threads (title, content, create_at, user_id, category_id)
+				  VALUES ($1, $2, $3, $4, $5)
+				  RETURNING ` + threadColumns
+		if err := tx.QueryRowContext(ctx, query,
+			thread.Title,
+			thread.Content,
+			time.Now(),
+			thread.UserID,
+			thread.CategoryID,
+		).Scan(threadDest(&created, &created.CreateAt)...); err != nil {
+			return fmt.Errorf("Ошибка при создании треда: %w", err)
+		}
+
+		result, err := tx.ExecContext(ctx,
+			`UPDATE posts SET thread_id = $1 WHERE thread_id = $2 AND id BETWEEN $3 AND $4`,
+			created.ID, req.ThreadID, req.FromPostID, req.ToPostID)
+		if err != nil {
+			return fmt.Errorf("Ошибка переноса постов: %w", err)
+		}
+		moved, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if moved == 0 {
+			return models.ErrorInvalidPostRange
+		}
+
+		if _, err := tx.ExecContext(ctx,
+			`UPDATE chat SET thread_id = $1 WHERE thread_id = $2 AND post_id BETWEEN $3 AND $4`,
+			created.ID, req.ThreadID, req.FromPostID, req.ToPostID); err != nil {
+			return fmt.Errorf("Ошибка переноса сообщений чата: %w", err)
+		}
+
+		entry.TargetID = created.ID
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при разделении треда",
+			zap.Int("threadID", req.ThreadID),
+			zap.Error(err))
+		return models.Thread{}, err
+	}
+
+	f.log(ctx).Info("Тред успешно разделен",
+		zap.Int("threadID", req.ThreadID),
+		zap.Int("newThreadID", created.ID))
+	return created, nil
+}
+
+func (f *forumRepository) MoveThread(ctx context.Context, threadID, categoryID int, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Перенос треда в категорию",
+		zap.Int("threadID", threadID),
+		zap.Int("categoryID", categoryID))
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		if categoryID != 0 {
+			var found int
+			err := tx.QueryRowContext(ctx, `SELECT id FROM categories WHERE id = $1`, categoryID).Scan(&found)
+			if errors.Is(err, sql.ErrNoRows) {
+				return models.ErrorNotFoundCategory
+			}
+			if err != nil {
+				return fmt.Errorf("Ошибка поиска категории: %w", err)
+			}
+		}
+
+		result, err := tx.ExecContext(ctx, `UPDATE threads SET category_id = $1 WHERE id = $2`, categoryID, threadID)
+		if err != nil {
+			return fmt.Errorf("Ошибка переноса треда: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrorNotFoundThread
+		}
+
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка при переносе треда",
+			zap.Int("threadID", threadID),
+			zap.Int("categoryID", categoryID),
+			zap.Error(err))
+		return err
+	}
+
+	f.log(ctx).Info("Тред успешно перенесен",
+		zap.Int("threadID", threadID),
+		zap.Int("categoryID", categoryID))
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_MergeThreads(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	entry := models.AuditEntry{ActorID: 1, Action: models.AuditThreadMerge, TargetType: models.TargetThread, TargetID: 2}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM threads WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("SELECT id FROM threads WHERE id = \\$1").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("UPDATE posts SET thread_id = \\$1 WHERE thread_id = \\$2").WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE chat SET thread_id = \\$1 WHERE thread_id = \\$2").WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("UPDATE thread_redirects SET new_id = \\$1 WHERE new_id = \\$2").WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO thread_redirects").WithArgs(1, 2, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM threads WHERE id = \\$1").WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Reason, entry.Details, "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.MergeThreads(context.Background(), 1, 2, entry); err != nil {
+		t.Errorf("ошибка не ожидалась при объединении тем: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_MergeThreads_Rollback(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM threads WHERE id = \\$1").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery("SELECT id FROM threads WHERE id = \\$1").WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectExec("UPDATE posts SET thread_id = \\$1 WHERE thread_id = \\$2").WithArgs(2, 1).
+		WillReturnError(errors.New("disk I/O error"))
+	mock.ExpectRollback()
+
+	if err := repo.MergeThreads(context.Background(), 1, 2, models.AuditEntry{}); err == nil {
+		t.Error("ожидалась ошибка при объединении тем")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_SplitThread(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	req := models.SplitRequest{ThreadID: 1, FromPostID: 10, ToPostID: 12}
+	thread := models.Thread{Title: "Split", Content: "Content", UserID: 1, CategoryID: 3}
+	created := thread
+	created.ID = 5
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO threads").
+		WithArgs(thread.Title, thread.Content, sqlmock.AnyArg(), thread.UserID, thread.CategoryID).
+		WillReturnRows(sqlmock.NewRows(threadRowColumns).AddRow(threadRow(created, time.Now())...))
+	mock.ExpectExec("UPDATE posts SET thread_id = \\$1 WHERE thread_id = \\$2 AND id BETWEEN \\$3 AND \\$4").
+		WithArgs(5, 1, 10, 12).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if _, err := repo.SplitThread(context.Background(), req, thread, models.AuditEntry{}); !errors.Is(err, models.ErrorInvalidPostRange) {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorInvalidPostRange, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_MoveThread(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id FROM categories WHERE id = \\$1").WithArgs(7).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	if err := repo.MoveThread(context.Background(), 1, 7, models.AuditEntry{}); !errors.Is(err, models.ErrorNotFoundCategory) {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotFoundCategory, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+type PreferenceRepository interface {
+	GetUserLanguage(ctx context.Context, userID int) (string, error)
+	SetUserLanguage(ctx context.Context, userID int, language string) error
+}
+
+// GetUserLanguage возвращает язык, выбранный пользователем, или пустую
+// строку, если пользователь его не выбирал.
+func (f *forumRepository) GetUserLanguage(ctx context.Context, userID int) (string, error) {
+	var language string
+	err := f.db.QueryRowContext(ctx, `SELECT language FROM user_preferences WHERE user_id = $1`, userID).Scan(&language)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения языка пользователя",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return "", fmt.Errorf("Ошибка получения языка пользователя: %w", err)
+	}
+	return language, nil
+}
+
+// SetUserLanguage сохраняет язык пользователя. Пустой language сбрасывает
+// выбор, и язык снова определяется по Accept-Language.
+func (f *forumRepository) SetUserLanguage(ctx context.Context, userID int, language string) error {
+	query := `INSERT INTO user_preferences (user_id, language) VALUES ($1, $2)
+			  ON CONFLICT (user_id) DO UPDATE SET language = excluded.language`
+	args := []any{userID, language}
+	if language == "" {
+		query = `DELETE FROM user_preferences WHERE user_id = $1`
+		args = args[:1]
+	}
+
+	if _, err := f.db.ExecContext(ctx, query, args...); err != nil {
+		f.log(ctx).Error("Ошибка изменения языка пользователя",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return fmt.Errorf("Ошибка изменения языка пользователя: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"testing"
+)
+
+func Test_forumRepository_GetUserLanguage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectQuery("SELECT language FROM user_preferences WHERE user_id = \\$1").
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"language"}).AddRow("en"))
+	mock.ExpectQuery("SELECT language FROM user_preferences WHERE user_id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"language"}))
+
+	language, err := repo.GetUserLanguage(context.Background(), 1)
+	if err != nil || language != "en" {
+		t.Errorf("ожидался язык en, получено %q, %v", language, err)
+	}
+	language, err = repo.GetUserLanguage(context.Background(), 2)
+	if err != nil || language != "" {
+		t.Errorf("без выбора ожидалась пустая строка, получено %q, %v", language, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_SetUserLanguage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectExec("INSERT INTO user_preferences (.+) ON CONFLICT").
+		WithArgs(1, "en").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("DELETE FROM user_preferences WHERE user_id = \\$1").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	if err := repo.SetUserLanguage(context.Background(), 1, "en"); err != nil {
+		t.Errorf("ошибка не ожидалась при сохранении языка: %s", err)
+	}
+	if err := repo.SetUserLanguage(context.Background(), 1, ""); err != nil {
+		t.Errorf("ошибка не ожидалась при сбросе языка: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+)
+
+type PremodRepository interface {
+	GetPendingThreads(ctx context.Context) ([]models.Thread, error)
+	GetPendingPosts(ctx context.Context) ([]models.PendingPost, error)
+	ApproveThread(ctx context.Context, id int, entry models.AuditEntry) error
+	ApprovePost(ctx context.Context, id int, entry models.AuditEntry) error
+	RejectThread(ctx context.Context, id int, entry models.AuditEntry) error
+	RejectPost(ctx context.Context, id int, entry models.AuditEntry) error
+}
+
+func (f *forumRepository) GetPendingThreads(ctx context.Context) ([]models.Thread, error) {
+	query := `SELECT ` + threadColumns + ` FROM threads WHERE pending = 1 AND deleted_at IS NULL ORDER BY create_at ASC`
+
+	rows, err := f.db.QueryContext(ctx, query)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения тредов на проверке", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения тредов на проверке: %w", err)
+	}
+	defer rows.Close()
+
+	var threads []models.Thread
+	for rows.Next() {
+		var thread models.Thread
+		if err := rows.Scan(threadDest(&thread, &thread.CreateAt)...); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования треда: %w", err)
+		}
+		threads = append(threads, thread)
+	}
+	return threads, nil
+}
+
+func (f *forumRepository) GetPendingPosts(ctx context.Context) ([]models.PendingPost, error) {
+	query := `SELECT p.id, p.content, p.create_at, p.thread_id, p.user_id, p.pending, p.deleted_at, p.deleted_by, t.category_id
+			  FROM posts p
+			  JOIN threads t ON t.id = p.thread_id
+			  WHERE p.pending = 1 AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+			  ORDER BY p.create_at ASC`
+
+	rows, err := f.db.QueryContext(ctx, query)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения постов на проверке", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения постов на проверке: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []models.PendingPost
+	for rows.Next() {
+		var post models.PendingPost
+		if err := rows.Scan(append(postDest(&post.Post), &post.CategoryID)...); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования поста: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+// publish снимает с записи таблицы table признак ожидания проверки.
+func (f *forumRepository) publish(ctx context.Context, table string, id int, entry models.AuditEntry) error {
+	return f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE `+table+` SET pending = 0 WHERE id = $1 AND pending = 1`, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка публикации: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		} else if affected == 0 {
+			return models.ErrorNotPending
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+}
+
+func (f *forumRepository) ApproveThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	if err := f.publish(ctx, "threads", id, entry); err != nil {
+		return err
+	}
+	f.log(ctx).Info("Тред одобрен",
+		zap.Int("id", id),
+		zap.Int("moderatorID", entry.ActorID))
+	return nil
+}
+
+func (f *forumRepository) ApprovePost(ctx context.Context, id int, entry models.AuditEntry) error {
+	if err := f.publish(ctx, "posts", id, entry); err != nil {
+		return err
+	}
+	f.log(ctx).Info("Пост одобрен",
+		zap.Int("id", id),
+		zap.Int("moderatorID", entry.ActorID))
+	return nil
+}
+
+// RejectThread удаляет ожидающий проверки тред. Посты и чат треда удаляются
+// каскадно внешними ключами.
+func (f *forumRepository) RejectThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM threads WHERE id = $1 AND pending = 1`, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка удаления треда: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		} else if affected == 0 {
+			return models.ErrorNotPending
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	f.log(ctx).Info("Тред отклонен",
+		zap.Int("id", id),
+		zap.Int("moderatorID", entry.ActorID))
+	return nil
+}
+
+func (f *forumRepository) RejectPost(ctx context.Context, id int, entry models.AuditEntry) error {
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM posts WHERE id = $1 AND pending = 1`, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка удаления поста: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		} else if affected == 0 {
+			return models.ErrorNotPending
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	f.log(ctx).Info("Пост отклонен",
+		zap.Int("id", id),
+		zap.Int("moderatorID", entry.ActorID))
+	return nil
+}
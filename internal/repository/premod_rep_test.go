@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_GetPendingPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	rows := sqlmock.NewRows(append(postRowColumns, "category_id")).
+		AddRow(4, "Первый пост", time.Now(), 2, 9, true, nil, 0, 3)
+
+	mock.ExpectQuery("SELECT (.+) FROM posts p JOIN threads t ON t.id = p.thread_id WHERE p.pending = 1").
+		WillReturnRows(rows)
+
+	posts, err := repo.GetPendingPosts(context.Background())
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении постов: %s", err)
+	}
+	if len(posts) != 1 || posts[0].ID != 4 || posts[0].CategoryID != 3 || !posts[0].Pending {
+		t.Errorf("неожиданные посты: %+v", posts)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_ApprovePost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET pending = 0 WHERE id = \\$1 AND pending = 1").
+		WithArgs(4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	if err := repo.ApprovePost(context.Background(), 4, models.AuditEntry{ActorID: 1, Action: models.AuditPostApprove}); err != nil {
+		t.Errorf("ошибка не ожидалась при одобрении поста: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_ApproveThread_NotPending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE threads SET pending = 0 WHERE id = \\$1 AND pending = 1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.ApproveThread(context.Background(), 2, models.AuditEntry{ActorID: 1, Action: models.AuditThreadApprove})
+	if err != models.ErrorNotPending {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotPending, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_RejectThread(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM threads WHERE id = \\$1 AND pending = 1").
+		WithArgs(2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = repo.RejectThread(context.Background(), 2, models.AuditEntry{ActorID: 1, Action: models.AuditThreadReject, Details: "реклама"})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при отклонении треда: %s", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report models.Report) (models.Report, error)
+	GetReports(ctx context.Context, filter models.ReportFilter) ([]models.Report, error)
+	GetReportByID(ctx context.Context, id int) (models.Report, error)
+	ResolveReport(ctx context.Context, report models.Report, entry models.AuditEntry) error
+	CreateNotification(ctx context.Context, notification models.Notification) error
+	GetNotifications(ctx context.Context, userID int) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, id, userID int) error
+}
+
+const reportColumns = `id, reporter_id, target_type, target_id, reason, status, moderator_id, resolution, create_at, resolved_at`
+
+func reportDest(report *models.Report, resolvedAt *sql.NullTime) []any {
+	return []any{
+		&report.ID,
+		&report.ReporterID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.Reason,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Resolution,
+		&report.CreateAt,
+		resolvedAt,
+	}
+}
+
+func (f *forumRepository) CreateReport(ctx context.Context, report models.Report) (models.Report, error) {
+	f.log(ctx).Debug("Создание жалобы",
+		zap.Int("reporterID", report.ReporterID),
+		zap.String("targetType", report.TargetType),
+		zap.Int("targetID", report.TargetID))
+
+	query := `INSERT INTO reports (reporter_id, target_type, target_id, reason, status, create_at)
+			  VALUES ($1, $2, $3, $4, $5, $6)
+			  RETURNING ` + reportColumns
+
+	var created models.Report
+	var resolvedAt sql.NullTime
+	err := f.db.QueryRowContext(ctx, query,
+		report.ReporterID,
+		report.TargetType,
+		report.TargetID,
+		report.Reason,
+		models.ReportOpen,
+		time.Now(),
+	).Scan(reportDest(&created, &resolvedAt)...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка при создании жалобы",
+			zap.Any("report", report),
+			zap.Error(err))
+		return models.Report{}, fmt.Errorf("Ошибка при создании жалобы: %w", err)
+	}
+
+	f.log(ctx).Info("Жалоба успешно создана",
+		zap.Int("id", created.ID),
+		zap.String("targetType", created.TargetType),
+		zap.Int("targetID", created.TargetID))
+	return created, nil
+}
+
+func (f *forumRepository) GetReports(ctx context.Context, filter models.ReportFilter) ([]models.Report, error) {
+	f.log(ctx).Debug("Получение жалоб", zap.Any("filter", filter))
+
+	var conditions []string
+	var args []any
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conditions = append(conditions, fmt.Sprintf("target_type = $%d", len(args)))
+	}
+	if filter.ReporterID != 0 {
+		args = append(args, filter.ReporterID)
+		conditions = append(conditions, fmt.Sprintf("reporter_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + reportColumns + ` FROM reports`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY create_at ASC`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit, filter.Offset)
+		query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+	}
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения жалоб", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения жалоб: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []models.Report
+	for rows.Next() {
+		var report models.Report
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(reportDest(&report, &resolvedAt)...); err != nil {
+			f.log(ctx).Error("Ошибка сканирования жалобы", zap.Error(err))
+			return nil, fmt.Errorf("Ошибка сканирования жалобы: %w", err)
+		}
+		if resolvedAt.Valid {
+			report.ResolvedAt = &resolvedAt.Time
+		}
+		reports = append(reports, report)
+	}
+
+	f.log(ctx).Debug("Жалобы успешно получены", zap.Int("count", len(reports)))
+	return reports, nil
+}
+
+func (f *forumRepository) GetReportByID(ctx context.Context, id int) (models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM reports WHERE id = $1`
+
+	var report models.Report
+	var resolvedAt sql.NullTime
+	if err := f.db.QueryRowContext(ctx, query, id).Scan(reportDest(&report, &resolvedAt)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Report{}, models.ErrorNotFoundReport
+		}
+		f.log(ctx).Error("Ошибка при получении жалобы",
+			zap.Int("id", id),
+			zap.Error(err))
+		return models.Report{}, fmt.Errorf("Ошибка поиска жалобы по id: %w", err)
+	}
+	if resolvedAt.Valid {
+		report.ResolvedAt = &resolvedAt.Time
+	}
+	return report, nil
+}
+
+func (f *forumRepository) ResolveReport(ctx context.Context, report models.Report, entry models.AuditEntry) error {
+	f.log(ctx).Debug("Закрытие жалобы",
+		zap.Int("id", report.ID),
+		zap.String("status", report.Status))
+
+	query := `UPDATE reports
+			  SET status=$1, moderator_id=$2, resolution=$3, resolved_at=$4
+			  WHERE id=$5 AND status=$6`
+
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query,
+			report.Status,
+			report.ModeratorID,
+			report.Resolution,
+			time.Now(),
+			report.ID,
+			models.ReportOpen,
+		)
+		if err != nil {
+			f.log(ctx).Error("Ошибка при закрытии жалобы",
+				zap.Int("id", report.ID),
+				zap.Error(err))
+			return fmt.Errorf("Ошибка закрытия жалобы: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		}
+		if affected == 0 {
+			return models.ErrorReportClosed
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		return err
+	}
+
+	f.log(ctx).Info("Жалоба закрыта",
+		zap.Int("id", report.ID),
+		zap.String("status", report.Status),
+		zap.Int("moderatorID", report.ModeratorID))
+	return nil
+}
+
+func (f *forumRepository) CreateNotification(ctx context.Context, notification models.Notification) error {
+	query := `INSERT INTO notifications (user_id, message, create_at) VALUES ($1, $2, $3)`
+
+	if _, err := f.db.ExecContext(ctx, query, notification.UserID, notification.Message, time.Now()); err != nil {
+		f.log(ctx).Error("Ошибка при создании уведомления",
+			zap.Int("userID", notification.UserID),
+			zap.Error(err))
+		return fmt.Errorf("Ошибка создания уведомления: %w", err)
+	}
+	return nil
+}
+
+func (f *forumRepository) GetNotifications(ctx context.Context, userID int) ([]models.Notification, error) {
+	query := `SELECT id, user_id, message, is_read, create_at
+			  FROM notifications
+			  WHERE user_id = $1
+			  ORDER BY create_at DESC`
+
+	rows, err := f.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения уведомлений",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения уведомлений: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Message, &n.Read, &n.CreateAt); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования уведомления: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+func (f *forumRepository) MarkNotificationRead(ctx context.Context, id, userID int) error {
+	query := `UPDATE notifications SET is_read = 1 WHERE id = $1 AND user_id = $2`
+
+	result, err := f.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return fmt.Errorf("Ошибка изменения уведомления: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+	}
+	if affected == 0 {
+		return models.ErrorNotFoundNotification
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+var reportRowColumns = []string{"id", "reporter_id", "target_type", "target_id", "reason", "status", "moderator_id", "resolution", "create_at", "resolved_at"}
+
+func Test_forumRepository_GetReports(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	rows := sqlmock.NewRows(reportRowColumns).
+		AddRow(1, 2, models.TargetPost, 5, "spam", models.ReportOpen, 0, "", time.Now(), nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM reports WHERE status = \\$1 AND target_type = \\$2 ORDER BY create_at ASC LIMIT \\$3 OFFSET \\$4").
+		WithArgs(models.ReportOpen, models.TargetPost, 10, 20).
+		WillReturnRows(rows)
+
+	reports, err := repo.GetReports(context.Background(), models.ReportFilter{
+		Status:     models.ReportOpen,
+		TargetType: models.TargetPost,
+		Limit:      10,
+		Offset:     20,
+	})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении жалоб: %s", err)
+	}
+
+	if len(reports) != 1 || reports[0].ResolvedAt != nil {
+		t.Errorf("ожидалась 1 открытая жалоба, получено %+v", reports)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_ResolveReport(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	report := models.Report{ID: 1, Status: models.ReportDismissed, ModeratorID: 3}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE reports SET (.+) WHERE id=\\$5 AND status=\\$6").
+		WithArgs(report.Status, report.ModeratorID, report.Resolution, sqlmock.AnyArg(), report.ID, models.ReportOpen).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	if err := repo.ResolveReport(context.Background(), report, models.AuditEntry{ActorID: 3, Action: models.AuditReportResolve}); err != models.ErrorReportClosed {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorReportClosed, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"sort"
+)
+
+type SpamRepository interface {
+	GetSpamModel(ctx context.Context) (models.SpamModel, error)
+	TrainSpam(ctx context.Context, feedback models.SpamFeedback, tokens map[string]int, entry models.AuditEntry) (previous string, err error)
+	SaveSpamScore(ctx context.Context, score models.SpamScore) error
+	GetSpamScores(ctx context.Context, min float64, limit int) ([]models.SpamScore, error)
+}
+
+func (f *forumRepository) GetSpamModel(ctx context.Context) (models.SpamModel, error) {
+	model := models.SpamModel{Tokens: make(map[string]models.SpamCounts)}
+
+	rows, err := f.db.QueryContext(ctx, `SELECT label, COUNT(*) FROM spam_feedback GROUP BY label`)
+	if err != nil {
+		f.log(ctx).Error("Ошибка загрузки примеров классификатора спама", zap.Error(err))
+		return models.SpamModel{}, fmt.Errorf("Ошибка загрузки примеров классификатора: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var label string
+		var count int
+		if err := rows.Scan(&label, &count); err != nil {
+			return models.SpamModel{}, fmt.Errorf("Ошибка сканирования примеров: %w", err)
+		}
+		if label == models.SpamLabelSpam {
+			model.Docs.Spam = count
+		} else {
+			model.Docs.Ham = count
+		}
+	}
+
+	tokenRows, err := f.db.QueryContext(ctx, `SELECT token, spam, ham FROM spam_tokens`)
+	if err != nil {
+		f.log(ctx).Error("Ошибка загрузки словаря классификатора спама", zap.Error(err))
+		return models.SpamModel{}, fmt.Errorf("Ошибка загрузки словаря классификатора: %w", err)
+	}
+	defer tokenRows.Close()
+
+	for tokenRows.Next() {
+		var token string
+		var counts models.SpamCounts
+		if err := tokenRows.Scan(&token, &counts.Spam, &counts.Ham); err != nil {
+			return models.SpamModel{}, fmt.Errorf("Ошибка сканирования словаря: %w", err)
+		}
+		model.Tokens[token] = counts
+	}
+	return model, nil
+}
+
+// TrainSpam сохраняет разметку поста и обновляет счетчики слов. Если пост
+// уже размечен другой меткой, ее вклад вычитается; previous возвращает
+// прежнюю метку. Повторная разметка той же меткой ничего не меняет и не
+// попадает в журнал аудита.
+func (f *forumRepository) TrainSpam(ctx context.Context, feedback models.SpamFeedback, tokens map[string]int, entry models.AuditEntry) (string, error) {
+	var previous string
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `SELECT label FROM spam_feedback WHERE post_id = $1`, feedback.PostID).Scan(&previous)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			_, err = tx.ExecContext(ctx, `INSERT INTO spam_feedback (post_id, label, moderator_id, create_at) VALUES ($1, $2, $3, $4)`,
+				feedback.PostID, feedback.Label, feedback.ModeratorID, feedback.CreateAt)
+		case err != nil:
+			return fmt.Errorf("Ошибка получения разметки поста: %w", err)
+		case previous == feedback.Label:
+			return nil
+		default:
+			if err := addSpamTokens(ctx, tx, tokens, previous, -1); err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `UPDATE spam_feedback SET label = $1, moderator_id = $2, create_at = $3 WHERE post_id = $4`,
+				feedback.Label, feedback.ModeratorID, feedback.CreateAt, feedback.PostID)
+		}
+		if err != nil {
+			return fmt.Errorf("Ошибка сохранения разметки поста: %w", err)
+		}
+		if err := addSpamTokens(ctx, tx, tokens, feedback.Label, 1); err != nil {
+			return err
+		}
+
+		if previous != "" {
+			entry.Before = models.Snapshot(map[string]string{"label": previous})
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка обучения классификатора спама",
+			zap.Int("postID", feedback.PostID),
+			zap.Error(err))
+		return "", err
+	}
+	return previous, nil
+}
+
+// addSpamTokens прибавляет вхождения слов к счетчикам класса label,
+// sign = -1 вычитает их. Слова обновляются в алфавитном порядке.
+func addSpamTokens(ctx context.Context, tx *sql.Tx, tokens map[string]int, label string, sign int) error {
+	words := make([]string, 0, len(tokens))
+	for token := range tokens {
+		words = append(words, token)
+	}
+	sort.Strings(words)
+
+	query := `INSERT INTO spam_tokens (token, spam, ham) VALUES ($1, $2, $3)
+			  ON CONFLICT (token) DO UPDATE SET spam = spam + excluded.spam, ham = ham + excluded.ham`
+	for _, token := range words {
+		n := sign * tokens[token]
+		spam, ham := 0, n
+		if label == models.SpamLabelSpam {
+			spam, ham = n, 0
+		}
+		if _, err := tx.ExecContext(ctx, query, token, spam, ham); err != nil {
+			return fmt.Errorf("Ошибка обновления словаря классификатора: %w", err)
+		}
+	}
+	return nil
+}
+
+func (f *forumRepository) SaveSpamScore(ctx context.Context, score models.SpamScore) error {
+	query := `INSERT INTO spam_scores (post_id, score, create_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (post_id) DO UPDATE SET score = excluded.score, create_at = excluded.create_at`
+
+	if _, err := f.db.ExecContext(ctx, query, score.PostID, score.Score, score.CreateAt); err != nil {
+		f.log(ctx).Error("Ошибка сохранения оценки спама",
+			zap.Int("postID", score.PostID),
+			zap.Error(err))
+		return fmt.Errorf("Ошибка сохранения оценки спама: %w", err)
+	}
+	return nil
+}
+
+// GetSpamScores возвращает оценки существующих постов не ниже min,
+// начиная с самых высоких.
+func (f *forumRepository) GetSpamScores(ctx context.Context, min float64, limit int) ([]models.SpamScore, error) {
+	query := `SELECT s.post_id, s.score, s.create_at
+			  FROM spam_scores s
+			  JOIN posts p ON p.id = s.post_id
+			  WHERE s.score >= $1 AND p.deleted_at IS NULL
+			  ORDER BY s.score DESC
+			  LIMIT $2`
+
+	rows, err := f.db.QueryContext(ctx, query, min, limit)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения оценок спама", zap.Error(err))
+		return nil, fmt.Errorf("Ошибка получения оценок спама: %w", err)
+	}
+	defer rows.Close()
+
+	var scores []models.SpamScore
+	for rows.Next() {
+		var score models.SpamScore
+		if err := rows.Scan(&score.PostID, &score.Score, &score.CreateAt); err != nil {
+			return nil, fmt.Errorf("Ошибка сканирования оценки спама: %w", err)
+		}
+		scores = append(scores, score)
+	}
+	return scores, nil
+}
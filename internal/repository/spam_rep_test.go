@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_TrainSpam(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT label FROM spam_feedback WHERE post_id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"label"}))
+	mock.ExpectExec("INSERT INTO spam_feedback").
+		WithArgs(4, models.SpamLabelSpam, 1, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO spam_tokens (.+) ON CONFLICT").
+		WithArgs("бонус", 2, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO spam_tokens (.+) ON CONFLICT").
+		WithArgs("казино", 1, 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(1, models.AuditSpamLabel, models.TargetPost, 4, "", "", "", "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	previous, err := repo.TrainSpam(context.Background(), models.SpamFeedback{PostID: 4, Label: models.SpamLabelSpam, ModeratorID: 1, CreateAt: time.Now()},
+		map[string]int{"казино": 1, "бонус": 2},
+		models.AuditEntry{ActorID: 1, Action: models.AuditSpamLabel, TargetType: models.TargetPost, TargetID: 4})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при обучении: %s", err)
+	}
+	if previous != "" {
+		t.Errorf("ожидалась пустая прежняя метка, получено %q", previous)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_TrainSpam_Relabel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT label FROM spam_feedback WHERE post_id = \\$1").
+		WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"label"}).AddRow(models.SpamLabelSpam))
+	mock.ExpectExec("INSERT INTO spam_tokens (.+) ON CONFLICT").
+		WithArgs("роутер", -1, 0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE spam_feedback SET label = \\$1").
+		WithArgs(models.SpamLabelHam, 2, sqlmock.AnyArg(), 4).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO spam_tokens (.+) ON CONFLICT").
+		WithArgs("роутер", 0, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WithArgs(2, models.AuditSpamLabel, models.TargetPost, 4, "", "", `{"label":"spam"}`, "", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	previous, err := repo.TrainSpam(context.Background(), models.SpamFeedback{PostID: 4, Label: models.SpamLabelHam, ModeratorID: 2, CreateAt: time.Now()},
+		map[string]int{"роутер": 1},
+		models.AuditEntry{ActorID: 2, Action: models.AuditSpamLabel, TargetType: models.TargetPost, TargetID: 4})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при смене метки: %s", err)
+	}
+	if previous != models.SpamLabelSpam {
+		t.Errorf("ожидалась прежняя метка %q, получено %q", models.SpamLabelSpam, previous)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
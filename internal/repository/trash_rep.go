@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type TrashRepository interface {
+	GetTrash(ctx context.Context, filter models.TrashFilter) (models.Trash, error)
+	GetDeletedThread(ctx context.Context, id int) (models.Thread, error)
+	GetDeletedPost(ctx context.Context, id int) (models.Post, error)
+	RestoreThread(ctx context.Context, id int, entry models.AuditEntry) error
+	RestorePost(ctx context.Context, id int, entry models.AuditEntry) error
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// trashScope возвращает условие, ограничивающее корзину по filter,
+// и его параметры. ownerColumn и categoryColumn - колонки автора и
+// категории в запросе.
+func trashScope(filter models.TrashFilter, ownerColumn, categoryColumn string) (string, []any) {
+	if filter.All {
+		return "", nil
+	}
+
+	args := []any{filter.OwnerID}
+	scope := []string{fmt.Sprintf("%s = $1", ownerColumn)}
+	if len(filter.Categories) > 0 {
+		placeholders := make([]string, len(filter.Categories))
+		for i, id := range filter.Categories {
+			args = append(args, id)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		scope = append(scope, fmt.Sprintf("%s IN (%s)", categoryColumn, strings.Join(placeholders, ", ")))
+	}
+	return " AND (" + strings.Join(scope, " OR ") + ")", args
+}
+
+func (f *forumRepository) GetTrash(ctx context.Context, filter models.TrashFilter) (models.Trash, error) {
+	trash := models.Trash{Threads: []models.Thread{}, Posts: []models.Post{}}
+
+	scope, args := trashScope(filter, "user_id", "category_id")
+	args = append(args, filter.Limit)
+	query := `SELECT ` + threadColumns + ` FROM threads
+			  WHERE deleted_at IS NOT NULL` + scope + `
+			  ORDER BY deleted_at DESC
+			  LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err := f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения удаленных тредов", zap.Error(err))
+		return models.Trash{}, fmt.Errorf("Ошибка получения удаленных тредов: %w", err)
+	}
+	for rows.Next() {
+		var thread models.Thread
+		if err := rows.Scan(threadDest(&thread, &thread.CreateAt)...); err != nil {
+			rows.Close()
+			return models.Trash{}, fmt.Errorf("Ошибка сканирования треда: %w", err)
+		}
+		trash.Threads = append(trash.Threads, thread)
+	}
+	rows.Close()
+
+	scope, args = trashScope(filter, "p.user_id", "t.category_id")
+	args = append(args, filter.Limit)
+	query = `SELECT p.id, p.content, p.create_at, p.thread_id, p.user_id, p.pending, p.deleted_at, p.deleted_by
+			 FROM posts p
+			 JOIN threads t ON t.id = p.thread_id
+			 WHERE p.deleted_at IS NOT NULL` + scope + `
+			 ORDER BY p.deleted_at DESC
+			 LIMIT $` + fmt.Sprint(len(args))
+
+	rows, err = f.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		f.log(ctx).Error("Ошибка получения удаленных постов", zap.Error(err))
+		return models.Trash{}, fmt.Errorf("Ошибка получения удаленных постов: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var post models.Post
+		if err := rows.Scan(postDest(&post)...); err != nil {
+			return models.Trash{}, fmt.Errorf("Ошибка сканирования поста: %w", err)
+		}
+		trash.Posts = append(trash.Posts, post)
+	}
+	return trash, nil
+}
+
+func (f *forumRepository) GetDeletedThread(ctx context.Context, id int) (models.Thread, error) {
+	query := `SELECT ` + threadColumns + ` FROM threads WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	var thread models.Thread
+	if err := f.db.QueryRowContext(ctx, query, id).Scan(threadDest(&thread, &thread.CreateAt)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Thread{}, models.ErrorNotFoundThread
+		}
+		return models.Thread{}, fmt.Errorf("Ошибка поиска удаленного треда: %w", err)
+	}
+	return thread, nil
+}
+
+func (f *forumRepository) GetDeletedPost(ctx context.Context, id int) (models.Post, error) {
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id = $1 AND deleted_at IS NOT NULL`
+
+	var post models.Post
+	if err := f.db.QueryRowContext(ctx, query, id).Scan(postDest(&post)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Post{}, models.ErrorNotFoundPost
+		}
+		return models.Post{}, fmt.Errorf("Ошибка поиска удаленного поста: %w", err)
+	}
+	return post, nil
+}
+
+func (f *forumRepository) RestoreThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	return f.restore(ctx, "threads", id, models.ErrorNotFoundThread, entry)
+}
+
+func (f *forumRepository) RestorePost(ctx context.Context, id int, entry models.AuditEntry) error {
+	return f.restore(ctx, "posts", id, models.ErrorNotFoundPost, entry)
+}
+
+// restore возвращает запись таблицы table из корзины. notFound
+// возвращается, если запись не найдена среди удаленных.
+func (f *forumRepository) restore(ctx context.Context, table string, id int, notFound error, entry models.AuditEntry) error {
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `UPDATE `+table+` SET deleted_at = NULL, deleted_by = 0 WHERE id = $1 AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			return fmt.Errorf("Ошибка восстановления: %w", err)
+		}
+		if affected, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("Ошибка получения измененных строк: %w", err)
+		} else if affected == 0 {
+			return notFound
+		}
+		return insertAudit(ctx, tx, entry)
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка восстановления из корзины",
+			zap.String("table", table),
+			zap.Int("id", id),
+			zap.Error(err))
+		return err
+	}
+
+	f.log(ctx).Info("Восстановлено из корзины",
+		zap.String("table", table),
+		zap.Int("id", id),
+		zap.Int("actorID", entry.ActorID))
+	return nil
+}
+
+// PurgeDeleted окончательно удаляет треды и посты, попавшие в корзину раньше
+// before, вместе с постами удаляемых тредов. Строки чата удаляются каскадно.
+// Возвращает число удаленных тредов и постов.
+func (f *forumRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	statements := []string{
+		// Посты удаляемых тредов удаляются явно, чтобы попасть в счетчик:
+		// каскадные удаления не учитываются в RowsAffected.
+		`DELETE FROM posts WHERE deleted_at < $1 OR thread_id IN (SELECT id FROM threads WHERE deleted_at < $1)`,
+		`DELETE FROM threads WHERE deleted_at < $1`,
+	}
+
+	var purged int64
+	err := f.inTx(ctx, func(tx *sql.Tx) error {
+		for _, query := range statements {
+			result, err := tx.ExecContext(ctx, query, before)
+			if err != nil {
+				return fmt.Errorf("Ошибка очистки корзины: %w", err)
+			}
+			affected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("Ошибка получения удаленных строк: %w", err)
+			}
+			purged += affected
+		}
+		return nil
+	})
+	if err != nil {
+		f.log(ctx).Error("Ошибка очистки корзины", zap.Error(err))
+		return 0, err
+	}
+	return purged, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+	"time"
+)
+
+func Test_forumRepository_GetTrash_Scoped(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	deletedAt := time.Now()
+
+	mock.ExpectQuery("SELECT (.+) FROM threads WHERE deleted_at IS NOT NULL AND \\(user_id = \\$1 OR category_id IN \\(\\$2, \\$3\\)\\) ORDER BY deleted_at DESC LIMIT \\$4").
+		WithArgs(5, 1, 2, 100).
+		WillReturnRows(sqlmock.NewRows(threadRowColumns).
+			AddRow(1, "Тред", "Содержание", time.Now(), 5, false, false, false, 1, 0, false, deletedAt, 5))
+	mock.ExpectQuery("SELECT (.+) FROM posts p JOIN threads t ON t.id = p.thread_id WHERE p.deleted_at IS NOT NULL AND \\(p.user_id = \\$1 OR t.category_id IN \\(\\$2, \\$3\\)\\)").
+		WithArgs(5, 1, 2, 100).
+		WillReturnRows(sqlmock.NewRows(postRowColumns))
+
+	trash, err := repo.GetTrash(context.Background(), models.TrashFilter{OwnerID: 5, Categories: []int{1, 2}, Limit: 100})
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при получении корзины: %s", err)
+	}
+	if len(trash.Threads) != 1 || trash.Threads[0].DeletedBy != 5 {
+		t.Errorf("ожидался один удаленный тред, получено %+v", trash.Threads)
+	}
+	if trash.Posts == nil {
+		t.Error("пустой список постов должен сериализоваться массивом")
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_RestorePost_NotDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET deleted_at = NULL, deleted_by = 0 WHERE id = \\$1 AND deleted_at IS NOT NULL").
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.RestorePost(context.Background(), 7, models.AuditEntry{ActorID: 1, Action: models.AuditPostRestore, TargetType: models.TargetPost, TargetID: 7})
+	if !errors.Is(err, models.ErrorNotFoundPost) {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorNotFoundPost, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_PurgeDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	before := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM posts WHERE deleted_at < \\$1 OR thread_id IN").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM threads WHERE deleted_at < \\$1").
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	purged, err := repo.PurgeDeleted(context.Background(), before)
+	if err != nil {
+		t.Errorf("ошибка не ожидалась при очистке корзины: %s", err)
+	}
+	if purged != 4 {
+		t.Errorf("ожидалось 4 удаленных сообщения, получено %d", purged)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"go.uber.org/zap"
+)
+
+// dbtx - общие методы пула соединений и транзакции, через которые
+// репозиторий выполняет запросы.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func (f *forumRepository) WithTx(ctx context.Context, fn func(repo ForumRepository) error) error {
+	if f.tx != nil {
+		return fn(f)
+	}
+	return f.runTx(ctx, func(tx *sql.Tx) error {
+		return fn(&forumRepository{db: tx, pool: f.pool, tx: tx, logger: f.logger})
+	})
+}
+
+// inTx выполняет fn в транзакции и откатывает ее, если fn вернула ошибку.
+// Внутри WithTx fn выполняется в уже открытой транзакции.
+func (f *forumRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if f.tx != nil {
+		return fn(f.tx)
+	}
+	return f.runTx(ctx, fn)
+}
+
+func (f *forumRepository) runTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := f.pool.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("Ошибка начала транзакции: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			f.log(ctx).Error("Ошибка отката транзакции", zap.Error(rbErr))
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Ошибка фиксации транзакции: %w", err)
+	}
+	return nil
+}
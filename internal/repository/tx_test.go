@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+)
+
+func Test_forumRepository_WithTx_NestedTransaction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+	report := models.Report{ID: 3, Status: models.ReportActioned, ModeratorID: 1}
+
+	// Удаление поста и закрытие жалобы используют inTx, но внутри WithTx
+	// не открывают собственных транзакций: закрытие жалобы не удалось,
+	// поэтому откатывается и удаление поста.
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE posts SET deleted_at = \\$1, deleted_by = \\$2 WHERE id = \\$3 AND deleted_at IS NULL").
+		WithArgs(sqlmock.AnyArg(), 1, 9).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit_log").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE reports").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.WithTx(context.Background(), func(repo ForumRepository) error {
+		if err := repo.DeletePostByID(context.Background(), 9, models.AuditEntry{ActorID: 1, Action: models.AuditPostDelete, TargetType: models.TargetPost, TargetID: 9}); err != nil {
+			return err
+		}
+		return repo.WithTx(context.Background(), func(repo ForumRepository) error {
+			return repo.ResolveReport(context.Background(), report, models.AuditEntry{ActorID: 1, Action: models.AuditReportResolve, TargetType: models.TargetReport, TargetID: 3})
+		})
+	})
+	if !errors.Is(err, models.ErrorReportClosed) {
+		t.Errorf("ожидалась ошибка %v, получено %v", models.ErrorReportClosed, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
+
+func Test_forumRepository_WithTx_BeginFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	defer db.Close()
+
+	repo := NewForumRepository(db, setupLogger())
+
+	mock.ExpectBegin().WillReturnError(errors.New("database is locked"))
+
+	called := false
+	err = repo.WithTx(context.Background(), func(repo ForumRepository) error {
+		called = true
+		return nil
+	})
+	if err == nil || called {
+		t.Errorf("fn не должна вызываться без транзакции, ошибка: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("не все ожидания были выполнены: %s", err)
+	}
+}
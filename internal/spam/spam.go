@@ -0,0 +1,159 @@
+// Package spam оценивает вероятность того, что пост - спам, наивным
+// байесовским классификатором. Классификатор обучается на решениях
+// модераторов и работает без внешних сервисов.
+package spam
+
+import (
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rule - имя правила в жалобах и логах.
+const Rule = "spam.bayes"
+
+// Config задает пороги оценки:
+//   - FlagThreshold - с этой оценки пост отправляется в очередь модерации;
+//   - HoldThreshold - с этой оценки пост скрывается до проверки модератором;
+//   - MinSamples - пока в одном из классов меньше примеров, посты не оцениваются.
+//
+// Нулевой порог отключает соответствующее действие.
+type Config struct {
+	FlagThreshold float64
+	HoldThreshold float64
+	MinSamples    int
+}
+
+func DefaultConfig() Config {
+	return Config{
+		FlagThreshold: 0.9,
+		HoldThreshold: 0.99,
+		MinSamples:    20,
+	}
+}
+
+// Classifier - мультиномиальный наивный байесовский классификатор
+// со сглаживанием Лапласа. Обучение и оценка безопасны для конкурентного вызова.
+type Classifier struct {
+	mu     sync.RWMutex
+	config Config
+	model  models.SpamModel
+	totals models.SpamCounts
+}
+
+// NewClassifier создает классификатор с накопленной статистикой model.
+func NewClassifier(cfg Config, model models.SpamModel) *Classifier {
+	c := &Classifier{config: cfg, model: model}
+	if c.model.Tokens == nil {
+		c.model.Tokens = make(map[string]models.SpamCounts)
+	}
+	for _, counts := range c.model.Tokens {
+		c.totals.Spam += counts.Spam
+		c.totals.Ham += counts.Ham
+	}
+	return c
+}
+
+func (c *Classifier) Config() Config {
+	return c.config
+}
+
+// Samples возвращает число обучающих примеров по классам.
+func (c *Classifier) Samples() models.SpamCounts {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.model.Docs
+}
+
+// Tokens разбивает текст на слова в нижнем регистре и считает их вхождения.
+// Однобуквенные и слишком длинные слова пропускаются.
+func Tokens(text string) map[string]int {
+	tokens := make(map[string]int)
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if n := utf8.RuneCountInString(word); n < 2 || n > 40 {
+			continue
+		}
+		tokens[word]++
+	}
+	return tokens
+}
+
+// Learn добавляет пример с меткой label. Если пост уже был размечен меткой
+// previous, ее вклад сначала вычитается.
+func (c *Classifier) Learn(tokens map[string]int, label, previous string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if previous != "" {
+		c.add(tokens, previous, -1)
+	}
+	c.add(tokens, label, 1)
+}
+
+func (c *Classifier) add(tokens map[string]int, label string, sign int) {
+	spam := label == models.SpamLabelSpam
+	if spam {
+		c.model.Docs.Spam += sign
+	} else {
+		c.model.Docs.Ham += sign
+	}
+
+	for token, n := range tokens {
+		counts := c.model.Tokens[token]
+		if spam {
+			counts.Spam += sign * n
+			c.totals.Spam += sign * n
+		} else {
+			counts.Ham += sign * n
+			c.totals.Ham += sign * n
+		}
+		if counts.Spam <= 0 && counts.Ham <= 0 {
+			delete(c.model.Tokens, token)
+			continue
+		}
+		c.model.Tokens[token] = counts
+	}
+}
+
+// Score возвращает вероятность того, что текст - спам. ok ложно, пока
+// классификатору не хватает примеров.
+func (c *Classifier) Score(text string) (score float64, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	docs := c.model.Docs
+	if docs.Spam < c.config.MinSamples || docs.Ham < c.config.MinSamples || docs.Spam == 0 || docs.Ham == 0 {
+		return 0, false
+	}
+
+	vocabulary := float64(len(c.model.Tokens))
+	logSpam := math.Log(float64(docs.Spam) / float64(docs.Spam+docs.Ham))
+	logHam := math.Log(float64(docs.Ham) / float64(docs.Spam+docs.Ham))
+	for token, n := range Tokens(text) {
+		counts, known := c.model.Tokens[token]
+		if !known {
+			continue
+		}
+		logSpam += float64(n) * math.Log((float64(counts.Spam)+1)/(float64(c.totals.Spam)+vocabulary))
+		logHam += float64(n) * math.Log((float64(counts.Ham)+1)/(float64(c.totals.Ham)+vocabulary))
+	}
+	return 1 / (1 + math.Exp(logHam-logSpam)), true
+}
+
+// Action возвращает действие для оценки score согласно порогам.
+func (c *Classifier) Action(score float64) automod.Action {
+	switch {
+	case c.config.HoldThreshold > 0 && score >= c.config.HoldThreshold:
+		return automod.ActionHold
+	case c.config.FlagThreshold > 0 && score >= c.config.FlagThreshold:
+		return automod.ActionFlag
+	default:
+		return ""
+	}
+}
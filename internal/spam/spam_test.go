@@ -0,0 +1,78 @@
+package spam
+
+import (
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"testing"
+)
+
+func trained(t *testing.T) *Classifier {
+	t.Helper()
+	c := NewClassifier(Config{FlagThreshold: 0.8, HoldThreshold: 0.99, MinSamples: 2}, models.SpamModel{})
+	for _, text := range []string{
+		"Лучшие ставки на спорт, бонус на депозит",
+		"Казино онлайн, бонус за регистрацию",
+		"Дешевые кредиты без проверки, ставки и бонус",
+	} {
+		c.Learn(Tokens(text), models.SpamLabelSpam, "")
+	}
+	for _, text := range []string{
+		"Подскажите, как настроить роутер",
+		"Спасибо, обновление драйвера помогло",
+		"Какой роутер выбрать для дома",
+	} {
+		c.Learn(Tokens(text), models.SpamLabelHam, "")
+	}
+	return c
+}
+
+func TestScore(t *testing.T) {
+	c := trained(t)
+
+	spam, ok := c.Score("Бонус на ставки каждому")
+	if !ok {
+		t.Fatal("Score() не оценил текст обученным классификатором")
+	}
+	ham, _ := c.Score("Роутер не видит драйвера")
+	if spam <= 0.5 || ham >= 0.5 {
+		t.Errorf("Score() spam = %.3f, ham = %.3f", spam, ham)
+	}
+	if c.Action(spam) == "" {
+		t.Errorf("Action(%.3f) пусто при пороге %.2f", spam, c.Config().FlagThreshold)
+	}
+}
+
+func TestScoreNotTrained(t *testing.T) {
+	c := NewClassifier(DefaultConfig(), models.SpamModel{})
+	if _, ok := c.Score("бонус на ставки"); ok {
+		t.Error("Score() оценил текст без обучающих примеров")
+	}
+}
+
+func TestLearnRelabel(t *testing.T) {
+	c := trained(t)
+	tokens := Tokens("Какой роутер выбрать для дома")
+
+	c.Learn(tokens, models.SpamLabelSpam, models.SpamLabelHam)
+
+	if got := c.Samples(); got != (models.SpamCounts{Spam: 4, Ham: 2}) {
+		t.Errorf("Samples() = %+v после смены метки", got)
+	}
+}
+
+func TestAction(t *testing.T) {
+	c := NewClassifier(Config{FlagThreshold: 0.8, HoldThreshold: 0.95}, models.SpamModel{})
+	tests := []struct {
+		score float64
+		want  automod.Action
+	}{
+		{0.5, ""},
+		{0.8, automod.ActionFlag},
+		{0.97, automod.ActionHold},
+	}
+	for _, tt := range tests {
+		if got := c.Action(tt.score); got != tt.want {
+			t.Errorf("Action(%v) = %q, want %q", tt.score, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package gin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type AuditHandler struct {
+	auditCase usecase.AuditUseCase
+	logger    *zap.Logger
+}
+
+func NewAuditHandler(L usecase.AuditUseCase, logger *zap.Logger) *AuditHandler {
+	return &AuditHandler{auditCase: L, logger: logger}
+}
+
+// @Summary Журнал аудита
+// @Description Получить записи журнала действий модераторов, начиная с новых. С format=csv журнал отдается файлом. Доступно администраторам
+// @Tags audit
+// @Produce json,text/csv
+// @Security ApiKeyAuth
+// @Param actor_id query int false "ID модератора"
+// @Param action query string false "Действие, например post.delete"
+// @Param target_type query string false "Тип объекта: post, thread, user, report, category, automod"
+// @Param target_id query int false "ID объекта"
+// @Param from query string false "Начало периода в RFC3339"
+// @Param to query string false "Конец периода в RFC3339, не включительно"
+// @Param limit query int false "Количество записей, по умолчанию 100, не больше 10000"
+// @Param offset query int false "Смещение"
+// @Param format query string false "Формат ответа: json или csv"
+// @Success 200 {array} models.AuditEntry
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /admin/audit [get]
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.Error(handler.InvalidQuery("format"))
+		return
+	}
+
+	filter := models.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+	for name, dest := range map[string]*int{
+		"actor_id":  &filter.ActorID,
+		"target_id": &filter.TargetID,
+		"limit":     &filter.Limit,
+		"offset":    &filter.Offset,
+	} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				c.Error(handler.InvalidQuery(name))
+				return
+			}
+			*dest = n
+		}
+	}
+	for name, dest := range map[string]**time.Time{
+		"from": &filter.From,
+		"to":   &filter.To,
+	} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.Error(handler.InvalidQuery(name))
+				return
+			}
+			*dest = &t
+		}
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	entries, err := h.auditCase.GetAuditLog(c.Request.Context(), filter, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения журнала аудита",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	if format == "csv" {
+		h.writeAuditCSV(c, entries)
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// writeAuditCSV отдает записи журнала CSV-файлом.
+func (h *AuditHandler) writeAuditCSV(c *gin.Context, entries []models.AuditEntry) {
+	filename := fmt.Sprintf("audit-%s.csv", time.Now().Format("20060102-150405"))
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"id", "create_at", "actor_id", "action", "target_type", "target_id", "reason", "details", "before", "after"})
+	for _, e := range entries {
+		_ = w.Write([]string{
+			strconv.Itoa(e.ID),
+			e.CreateAt.Format(time.RFC3339),
+			strconv.Itoa(e.ActorID),
+			e.Action,
+			e.TargetType,
+			strconv.Itoa(e.TargetID),
+			e.Reason,
+			e.Details,
+			string(e.Before),
+			string(e.After),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка выгрузки журнала аудита в CSV", zap.Error(err))
+	}
+}
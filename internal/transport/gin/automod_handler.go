@@ -0,0 +1,114 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type AutomodHandler struct {
+	automodCase usecase.AutomodUseCase
+	logger      *zap.Logger
+}
+
+func NewAutomodHandler(A usecase.AutomodUseCase, logger *zap.Logger) *AutomodHandler {
+	return &AutomodHandler{automodCase: A, logger: logger}
+}
+
+// @Summary Получить правила автомодерации
+// @Description Получить действующие правила автомодерации. Доступно администратору
+// @Tags automod
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} automod.Config
+// @Failure 403 {object} object
+// @Router /admin/automod [get]
+func (h *AutomodHandler) GetConfig(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	cfg, err := h.automodCase.GetConfig(c.Request.Context(), uid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, cfg)
+}
+
+// @Summary Изменить правила автомодерации
+// @Description Заменить набор правил автомодерации. Правила применяются сразу; dry_run включает пробный режим, в котором решения только логируются. Доступно администратору
+// @Tags automod
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param config body automod.Config true "Набор правил"
+// @Success 200 {object} automod.Config
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /admin/automod [put]
+func (h *AutomodHandler) UpdateConfig(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var cfg automod.Config
+	if err := c.ShouldBindJSON(&cfg); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	updated, err := h.automodCase.UpdateConfig(c.Request.Context(), cfg, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка изменения правил автомодерации",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, updated)
+}
+
+// @Summary Повторяющиеся сообщения
+// @Description Получить группы совпадающих и почти совпадающих постов и тредов. Доступно администратору
+// @Tags automod
+// @Produce json
+// @Security ApiKeyAuth
+// @Param window query string false "Окно поиска, например 6h. По умолчанию - глобальное окно детектора"
+// @Success 200 {array} models.DuplicateCluster
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /admin/duplicates [get]
+func (h *AutomodHandler) GetDuplicates(c *gin.Context) {
+	var window time.Duration
+	if value := c.Query("window"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			c.Error(handler.InvalidQuery("window"))
+			return
+		}
+		window = d
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	clusters, err := h.automodCase.GetDuplicates(c.Request.Context(), window, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения повторяющихся сообщений",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, clusters)
+}
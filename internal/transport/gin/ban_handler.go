@@ -0,0 +1,126 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type BanHandler struct {
+	banCase usecase.BanUseCase
+	logger  *zap.Logger
+}
+
+func NewBanHandler(B usecase.BanUseCase, logger *zap.Logger) *BanHandler {
+	return &BanHandler{banCase: B, logger: logger}
+}
+
+// @Summary Заблокировать пользователя
+// @Description Заблокировать пользователя на всем форуме или в категории. Без expires_at блокировка бессрочная, shadow включает теневую блокировку
+// @Tags bans
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param ban body models.Ban true "Блокировка: user_id, category_id, shadow, reason, expires_at"
+// @Success 200 {object} models.Ban
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/bans [post]
+func (h *BanHandler) BanUser(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var ban models.Ban
+	if err := c.ShouldBindJSON(&ban); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	created, err := h.banCase.BanUser(c.Request.Context(), ban, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка блокировки пользователя",
+			zap.Int("userID", ban.UserID),
+			zap.Int("moderatorID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, created)
+}
+
+// @Summary Получить блокировки
+// @Description Получить действующие блокировки, при заданном user_id - только блокировки пользователя
+// @Tags bans
+// @Produce json
+// @Security ApiKeyAuth
+// @Param user_id query int false "ID пользователя"
+// @Success 200 {array} models.Ban
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /admin/bans [get]
+func (h *BanHandler) GetBans(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.Atoi(c.DefaultQuery("user_id", "0"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	bans, err := h.banCase.GetBans(c.Request.Context(), userID, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения блокировок",
+			zap.Int("userID", userID),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, bans)
+}
+
+// @Summary Снять блокировку
+// @Description Досрочно снять блокировку пользователя
+// @Tags bans
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID блокировки"
+// @Success 200 {object} object
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/bans/{id} [delete]
+func (h *BanHandler) LiftBan(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.banCase.LiftBan(c.Request.Context(), id, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка снятия блокировки",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": handler.Message(c, "ban_lifted", "Блокировка снята")})
+}
@@ -1,10 +1,11 @@
 package gin
 
 import (
-	"fmt"
 	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
 	"github.com/fire9900/forum/internal/usecase"
 	"github.com/fire9900/forum/pkg/logger"
+	"github.com/fire9900/forum/pkg/wsserver"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
@@ -25,17 +26,21 @@ import (
 type ForumHandler struct {
 	threadCase usecase.ThreadUseCase
 	postCase   usecase.PostUseCase
+	hub        *wsserver.Hub
+	logger     *zap.Logger
 }
 
-func NewForumHandler(P usecase.PostUseCase, T usecase.ThreadUseCase) *ForumHandler {
+func NewForumHandler(P usecase.PostUseCase, T usecase.ThreadUseCase, hub *wsserver.Hub, logger *zap.Logger) *ForumHandler {
 	return &ForumHandler{
 		threadCase: T,
 		postCase:   P,
+		hub:        hub,
+		logger:     logger,
 	}
 }
 
 // @Summary Получить все треды
-// @Description Получить список всех тредов
+// @Description Получить список всех тредов. Авторизация необязательна: с токеном в списке есть и скрытые от остальных треды пользователя
 // @Tags threads
 // @Accept  json
 // @Produce  json
@@ -43,21 +48,21 @@ func NewForumHandler(P usecase.PostUseCase, T usecase.ThreadUseCase) *ForumHandl
 // @Failure 400 {object} object
 // @Router /threads [get]]
 func (h *ForumHandler) GetAllThread(c *gin.Context) {
-	threads, err := h.threadCase.GetAllThreads()
+	threads, err := h.threadCase.GetAllThreads(c.Request.Context(), c.GetInt("userID"))
 	if err != nil {
-		logger.Logger.Error("Ошибка получения всех тредов",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения всех тредов",
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Успешное получение всех тредов",
+	logger.From(c.Request.Context(), h.logger).Info("Успешное получение всех тредов",
 		zap.Int("количество", len(threads)))
 	c.JSON(http.StatusOK, threads)
 }
 
 // @Summary Получить тред по ID
-// @Description Получить тред по его идентификатору
+// @Description Получить тред по его идентификатору. Авторизация необязательна: скрытый тред находится только для его автора
 // @Tags threads
 // @Accept json
 // @Produce json
@@ -69,81 +74,71 @@ func (h *ForumHandler) GetAllThread(c *gin.Context) {
 func (h *ForumHandler) GetThreadByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Logger.Error("Ошибка конвертации ID треда",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка конвертации ID треда",
 			zap.String("id", c.Param("id")),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		c.Error(handler.ErrInvalidID)
 		return
 	}
 
-	thread, err := h.threadCase.GetThreadByID(id)
+	thread, err := h.threadCase.GetThreadByID(c.Request.Context(), id, c.GetInt("userID"))
 	if err != nil {
-		logger.Logger.Error("Ошибка получения треда по ID",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения треда по ID",
 			zap.Int("id", id),
 			zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Тред не найден"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Успешное получение треда",
+	logger.From(c.Request.Context(), h.logger).Info("Успешное получение треда",
 		zap.Int("id", id))
 	c.JSON(http.StatusOK, thread)
 }
 
 // @Summary Создать тред
-// @Description Создать новый тред
+// @Description Создать новый тред. Тред, задержанный автомодерацией до проверки, возвращается со статусом 202
 // @Tags threads
 // @Accept  json
 // @Produce  json
 // @Security ApiKeyAuth
 // @Param thread body models.Thread true "Данные треда"
 // @Success 200 {object} models.Thread
+// @Success 202 {object} models.Thread
 // @Failure 400 {object} object
 // @Failure 401 {object} object
+// @Failure 403 {object} object
+// @Failure 409 {object} object
 // @Failure 500 {object} object
 // @Router /threads [post]
 func (h *ForumHandler) CreateThread(c *gin.Context) {
-	userID, exist := c.Get("userID")
-	if !exist {
-		logger.Logger.Warn("Попытка создания треда без авторизации")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Требуется авторизация",
-		})
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
 		return
 	}
 
 	var thread models.Thread
 	if err := c.ShouldBindJSON(&thread); err != nil {
-		logger.Logger.Error("Ошибка парсинга тела запроса",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка парсинга тела запроса",
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
-		return
-	}
-
-	uid, ok := userID.(int)
-	if !ok {
-		logger.Logger.Error("Неверный тип userID",
-			zap.Any("userID", userID))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Ошибка сервера",
-		})
+		c.Error(handler.InvalidBody(err))
 		return
 	}
 	thread.UserID = uid
 
-	createdThread, err := h.threadCase.CreateThread(thread)
+	createdThread, err := h.threadCase.CreateThread(c.Request.Context(), thread)
 	if err != nil {
-		logger.Logger.Error("Ошибка создания треда",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка создания треда",
 			zap.Any("thread", thread),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания треда"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Тред успешно создан",
+	logger.From(c.Request.Context(), h.logger).Info("Тред успешно создан",
 		zap.Int("id", createdThread.ID),
-		zap.Int("userID", uid))
-	c.JSON(http.StatusOK, createdThread)
+		zap.Int("userID", uid),
+		zap.Bool("pending", createdThread.Pending))
+	c.JSON(createdStatus(createdThread.Pending), createdThread)
 }
 
 // @Summary Удалить тред
@@ -160,35 +155,28 @@ func (h *ForumHandler) CreateThread(c *gin.Context) {
 func (f *ForumHandler) DeleteTheadByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Logger.Error("Неверный формат ID треда для удаления",
+		logger.From(c.Request.Context(), f.logger).Error("Неверный формат ID треда для удаления",
 			zap.String("id", c.Param("id")),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
-		return
-	}
-
-	userID, exist := c.Get("userID")
-	if !exist {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не авторизован"})
+		c.Error(handler.ErrInvalidID)
 		return
 	}
 
-	uid, ok := userID.(int)
+	uid, ok := currentUserID(c, f.logger)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка парсинга id"})
 		return
 	}
 
-	err = f.threadCase.DeleteThreadByID(id, uid)
+	err = f.threadCase.DeleteThreadByID(c.Request.Context(), id, uid)
 	if err != nil {
-		logger.Logger.Error("Ошибка удаления треда",
+		logger.From(c.Request.Context(), f.logger).Error("Ошибка удаления треда",
 			zap.Int("id", id),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Errorf("Ошибка удаления треда: %s", err.Error())})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Тред успешно удален",
+	logger.From(c.Request.Context(), f.logger).Info("Тред успешно удален",
 		zap.Int("id", id))
 	c.JSON(http.StatusOK, nil)
 }
@@ -207,84 +195,144 @@ func (f *ForumHandler) DeleteTheadByID(c *gin.Context) {
 func (f *ForumHandler) EditThread(c *gin.Context) {
 	var thread models.Thread
 	if err := c.ShouldBindJSON(&thread); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Неверный формат данных",
-			"details": err.Error(),
-		})
+		c.Error(handler.InvalidBody(err))
 		return
 	}
 
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Требуется авторизация"})
-		return
-	}
-
-	uid, ok := userID.(int)
+	uid, ok := currentUserID(c, f.logger)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сервера"})
 		return
 	}
 
-	if err := f.threadCase.EditThread(thread, uid); err != nil {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error":   "Не удалось обновить тред",
-			"details": err.Error(),
-		})
+	if err := f.threadCase.EditThread(c.Request.Context(), thread, uid); err != nil {
+		logger.From(c.Request.Context(), f.logger).Error("Ошибка обновления треда",
+			zap.Int("id", thread.ID),
+			zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Тред успешно обновлен",
+		"message": handler.Message(c, "thread_updated", "Тред успешно обновлен"),
 		"thread":  thread,
 	})
 }
 
+// @Summary Изменить состояние треда
+// @Description Закрепить, закрыть, отправить тред в архив или задать медленный режим (slow_mode, секунды между постами пользователя). Доступно модераторам форума и категории треда
+// @Tags threads
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Param state body models.ThreadState true "Новое состояние"
+// @Success 200 {object} models.Thread
+// @Failure 400 {object} object
+// @Failure 401 {object} object
+// @Failure 403 {object} object
+// @Router /threads/{id}/state [put]
+func (f *ForumHandler) SetThreadState(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		logger.From(c.Request.Context(), f.logger).Error("Неверный формат ID треда",
+			zap.String("id", c.Param("id")),
+			zap.Error(err))
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var state models.ThreadState
+	if err := c.ShouldBindJSON(&state); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	uid, ok := currentUserID(c, f.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := f.threadCase.SetThreadState(c.Request.Context(), id, state, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), f.logger).Error("Ошибка изменения состояния треда",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	f.hub.BroadcastThreadState(thread)
+
+	logger.From(c.Request.Context(), f.logger).Info("Состояние треда изменено",
+		zap.Int("id", id),
+		zap.Int("userID", uid))
+	c.JSON(http.StatusOK, thread)
+}
+
 // @Summary Создать пост
-// @Description Создать новый пост в треде
+// @Description Создать новый пост в треде от имени авторизованного пользователя
 // @Tags posts
 // @Accept json
 // @Produce json
 // @Security ApiKeyAuth
-// @Param post body models.Post true "Данные поста"
+// @Param post body object true "{\"content\": \"текст\", \"thread_id\": 1}"
 // @Success 200 {object} models.Post
+// @Success 202 {object} models.Post
 // @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 409 {object} object
+// @Failure 429 {object} object
 // @Failure 500 {object} object
 // @Router /threads/posts [post]
 func (h *ForumHandler) CreatePost(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	// Автор берется из токена: по нему проверяются блокировки, медленный
+	// режим, премодерация и лимиты.
 	var DTOPost struct {
 		Content  string `json:"content"`
 		ThreadID int    `json:"thread_id"`
-		UserID   int    `json:"user_id"`
 	}
 
 	if err := c.ShouldBindJSON(&DTOPost); err != nil {
-		logger.Logger.Error("Ошибка парсинга тела запроса при создании поста",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка парсинга тела запроса при создании поста",
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат данных"})
+		c.Error(handler.InvalidBody(err))
 		return
 	}
 
 	post := models.Post{
 		Content:  DTOPost.Content,
 		ThreadID: DTOPost.ThreadID,
-		UserID:   DTOPost.UserID,
+		UserID:   uid,
 		CreateAt: time.Now(),
 	}
 
-	createdPost, err := h.postCase.CreatePost(post)
+	createdPost, err := h.postCase.CreatePost(c.Request.Context(), post)
 	if err != nil {
-		logger.Logger.Error("Ошибка создания поста",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка создания поста",
 			zap.Any("post", post),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания поста"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Пост успешно создан",
+	logger.From(c.Request.Context(), h.logger).Info("Пост успешно создан",
 		zap.Int("id", createdPost.ID),
-		zap.Int("threadID", DTOPost.ThreadID))
-	c.JSON(http.StatusOK, createdPost)
+		zap.Int("threadID", DTOPost.ThreadID),
+		zap.Bool("pending", createdPost.Pending))
+	c.JSON(createdStatus(createdPost.Pending), createdPost)
+}
+
+// createdStatus возвращает 202 для сообщения, задержанного до проверки модератором.
+func createdStatus(pending bool) int {
+	if pending {
+		return http.StatusAccepted
+	}
+	return http.StatusOK
 }
 
 // @Summary Получить посты треда
@@ -295,28 +343,29 @@ func (h *ForumHandler) CreatePost(c *gin.Context) {
 // @Param id path int true "ID треда"
 // @Success 200 {array} models.Post
 // @Failure 400 {object} object
+// @Failure 404 {object} object
 // @Failure 500 {object} object
 // @Router /thread/{id}/posts [get]
 func (h *ForumHandler) GetPostsByThreadID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Logger.Error("Неверный формат ID треда",
+		logger.From(c.Request.Context(), h.logger).Error("Неверный формат ID треда",
 			zap.String("id", c.Param("id")),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		c.Error(handler.ErrInvalidID)
 		return
 	}
 
-	posts, err := h.postCase.GetPostByThreadID(id)
+	posts, err := h.postCase.GetPostByThreadID(c.Request.Context(), id, c.GetInt("userID"))
 	if err != nil {
-		logger.Logger.Error("Ошибка получения постов треда",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения постов треда",
 			zap.Int("threadID", id),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения постов"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Посты треда успешно получены",
+	logger.From(c.Request.Context(), h.logger).Info("Посты треда успешно получены",
 		zap.Int("threadID", id),
 		zap.Int("количество", len(posts)))
 	c.JSON(http.StatusOK, posts)
@@ -332,51 +381,38 @@ func (h *ForumHandler) GetPostsByThreadID(c *gin.Context) {
 // @Success 200 {array} models.Post
 // @Failure 400 {object} object
 // @Failure 401 {object} object
+// @Failure 403 {object} object
 // @Failure 500 {object} object
 // @Router /posts/user/{id} [get]
 func (h *ForumHandler) GetPostsByUserID(c *gin.Context) {
-	userID, exist := c.Get("userID")
-	if !exist {
-		logger.Logger.Warn("Попытка получения постов без авторизации")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Требуется авторизация",
-		})
-		return
-	}
-
-	uid, ok := userID.(int)
+	uid, ok := currentUserID(c, h.logger)
 	if !ok {
-		logger.Logger.Error("Неверный тип userID",
-			zap.Any("userID", userID))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Ошибка сервера",
-		})
 		return
 	}
 
 	id, err := strconv.Atoi(c.Param("id"))
-	if err != nil || id != uid {
-		logger.Logger.Warn("Несоответствие ID пользователя",
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+	if id != uid {
+		logger.From(c.Request.Context(), h.logger).Warn("Несоответствие ID пользователя",
 			zap.Int("paramID", id),
 			zap.Int("userID", uid))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Неверный ID пользователя",
-		})
+		c.Error(models.ErrorForbidden)
 		return
 	}
 
-	posts, err := h.postCase.GetPostsByUserID(id)
+	posts, err := h.postCase.GetPostsByUserID(c.Request.Context(), id, uid)
 	if err != nil {
-		logger.Logger.Error("Ошибка получения постов пользователя",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения постов пользователя",
 			zap.Int("userID", id),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Ошибка получения постов",
-		})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Посты пользователя успешно получены",
+	logger.From(c.Request.Context(), h.logger).Info("Посты пользователя успешно получены",
 		zap.Int("userID", id),
 		zap.Int("количество", len(posts)))
 	c.JSON(http.StatusOK, posts)
@@ -396,34 +432,27 @@ func (h *ForumHandler) GetPostsByUserID(c *gin.Context) {
 func (h *ForumHandler) DeletePostByID(c *gin.Context) {
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Logger.Error("Неверный формат ID поста",
+		logger.From(c.Request.Context(), h.logger).Error("Неверный формат ID поста",
 			zap.String("id", c.Param("id")),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID"})
+		c.Error(handler.ErrInvalidID)
 		return
 	}
 
-	userID, exit := c.Get("userID")
-	if !exit {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не авторизован"})
-		return
-	}
-
-	uid, ok := userID.(int)
+	uid, ok := currentUserID(c, h.logger)
 	if !ok {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "ошибка парсинга id"})
 		return
 	}
 
-	if err := h.postCase.DeletePostByID(id, uid); err != nil {
-		logger.Logger.Error("Ошибка удаления поста",
+	if err := h.postCase.DeletePostByID(c.Request.Context(), id, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка удаления поста",
 			zap.Int("postID", id),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка удаления поста"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Пост успешно удален",
+	logger.From(c.Request.Context(), h.logger).Info("Пост успешно удален",
 		zap.Int("postID", id))
 	c.JSON(http.StatusOK, nil)
 }
@@ -438,49 +467,38 @@ func (h *ForumHandler) DeletePostByID(c *gin.Context) {
 // @Success 200 {array} models.Thread
 // @Failure 400 {object} object
 // @Failure 401 {object} object
+// @Failure 403 {object} object
 // @Failure 404 {object} object
 // @Router /threads/user/{id} [get]
 func (h *ForumHandler) GetThreadsByUserID(c *gin.Context) {
-	userID, exist := c.Get("userID")
-	if !exist {
-		logger.Logger.Warn("Попытка получения тредов без авторизации")
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "Требуется авторизация",
-		})
-		return
-	}
-
-	uid, ok := userID.(int)
+	uid, ok := currentUserID(c, h.logger)
 	if !ok {
-		logger.Logger.Error("Неверный тип userID",
-			zap.Any("userID", userID))
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Ошибка сервера",
-		})
 		return
 	}
 
 	paramID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
 	if paramID != uid {
-		logger.Logger.Warn("Несоответствие ID пользователя",
+		logger.From(c.Request.Context(), h.logger).Warn("Несоответствие ID пользователя",
 			zap.Int("paramID", paramID),
 			zap.Int("userID", uid))
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Неверный ID пользователя",
-		})
+		c.Error(models.ErrorForbidden)
 		return
 	}
 
-	threads, err := h.threadCase.GetUserThreads(paramID)
+	threads, err := h.threadCase.GetUserThreads(c.Request.Context(), paramID, uid)
 	if err != nil {
-		logger.Logger.Error("Ошибка получения тредов пользователя",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения тредов пользователя",
 			zap.Int("userID", paramID),
 			zap.Error(err))
-		c.JSON(http.StatusNotFound, gin.H{"error": "Треды не найдены"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Треды пользователя успешно получены",
+	logger.From(c.Request.Context(), h.logger).Info("Треды пользователя успешно получены",
 		zap.Int("userID", paramID),
 		zap.Int("количество", len(threads)))
 	c.JSON(http.StatusOK, threads)
@@ -499,23 +517,23 @@ func (h *ForumHandler) GetThreadsByUserID(c *gin.Context) {
 func (h *ForumHandler) GetChatPosts(c *gin.Context) {
 	threadID, err := strconv.Atoi(c.Param("thread_id"))
 	if err != nil {
-		logger.Logger.Error("Неверный формат ID треда",
+		logger.From(c.Request.Context(), h.logger).Error("Неверный формат ID треда",
 			zap.String("thread_id", c.Param("thread_id")),
 			zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверный формат ID треда"})
+		c.Error(handler.ErrInvalidID)
 		return
 	}
 
-	posts, err := h.postCase.GetChatPosts(threadID)
+	posts, err := h.postCase.GetChatPosts(c.Request.Context(), threadID, c.GetInt("userID"))
 	if err != nil {
-		logger.Logger.Error("Ошибка получения сообщений чата",
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения сообщений чата",
 			zap.Int("threadID", threadID),
 			zap.Error(err))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка получения сообщений"})
+		c.Error(err)
 		return
 	}
 
-	logger.Logger.Info("Сообщения чата успешно получены",
+	logger.From(c.Request.Context(), h.logger).Info("Сообщения чата успешно получены",
 		zap.Int("threadID", threadID),
 		zap.Int("количество", len(posts)))
 	c.JSON(http.StatusOK, posts)
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/i18n"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// Ошибки транспортного уровня: запрос отклонен до вызова сценария.
+var (
+	ErrUnauthorized    = models.Unauthorized("unauthorized", "Требуется авторизация")
+	ErrInvalidID       = models.Validation("invalid_id", "Неверный формат ID")
+	ErrInvalidBody     = models.Validation("invalid_body", "Неверный формат данных")
+	ErrInvalidQuery    = models.Validation("invalid_query", "Неверный параметр запроса")
+	ErrTooManyRequests = models.RateLimited("rate_limited", "Слишком много запросов")
+	ErrRouteNotFound   = models.NotFound("route_not_found", "Маршрут не найден")
+)
+
+// CodeInternal - код ответа на ошибку, не описанную доменной моделью.
+// Текст такой ошибки клиенту не отдается.
+const CodeInternal = "internal"
+
+// kindStatus сопоставляет класс доменной ошибки с HTTP-статусом.
+var kindStatus = map[models.ErrorKind]int{
+	models.KindNotFound:     http.StatusNotFound,
+	models.KindForbidden:    http.StatusForbidden,
+	models.KindValidation:   http.StatusBadRequest,
+	models.KindConflict:     http.StatusConflict,
+	models.KindRateLimited:  http.StatusTooManyRequests,
+	models.KindUnauthorized: http.StatusUnauthorized,
+}
+
+// ErrorResponse - тело любого ответа с ошибкой.
+type ErrorResponse struct {
+	Error      string            `json:"error"`
+	Code       string            `json:"code"`
+	Details    map[string]string `json:"details,omitempty"`
+	RetryAfter int               `json:"retry_after,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+}
+
+// retryAfter реализуют ошибки, после которых запрос можно повторить
+// через известное время.
+type retryAfter interface {
+	RetryAfterSeconds() int
+}
+
+// ErrorMiddleware превращает последнюю ошибку из c.Errors в ответ: статус
+// выбирается по классу доменной ошибки, тело - ErrorResponse с кодом
+// и текстом на языке запроса. Обработчики только вызывают c.Error
+// и возвращаются. Ошибки вне доменной модели отдаются как 500 и пишутся
+// в лог.
+func ErrorMiddleware(log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		err := c.Errors.Last().Err
+		status, body := errorResponse(err)
+		loc := i18n.From(c.Request.Context())
+		body.Error = loc.Error(body.Code, body.Error)
+		body.Details = loc.Details(body.Code, body.Details)
+		body.RequestID = RequestID(c)
+
+		span := trace.SpanFromContext(c.Request.Context())
+		span.SetAttributes(attribute.String("error.code", body.Code))
+		if status >= http.StatusInternalServerError {
+			span.RecordError(err)
+			logger.From(c.Request.Context(), log).Error("Внутренняя ошибка сервера",
+				zap.Error(err))
+		}
+
+		if body.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(body.RetryAfter))
+		}
+		c.AbortWithStatusJSON(status, body)
+	}
+}
+
+func errorResponse(err error) (int, ErrorResponse) {
+	var domain *models.Error
+	if !errors.As(err, &domain) {
+		return http.StatusInternalServerError, ErrorResponse{
+			Error: "Внутренняя ошибка сервера",
+			Code:  CodeInternal,
+		}
+	}
+
+	status, ok := kindStatus[domain.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	body := ErrorResponse{
+		Error:   domain.Message,
+		Code:    domain.Code,
+		Details: domain.Fields,
+	}
+	var retry retryAfter
+	if errors.As(err, &retry) {
+		body.RetryAfter = retry.RetryAfterSeconds()
+	}
+	return status, body
+}
+
+// InvalidBody описывает ошибку разбора JSON-тела запроса. Для поля
+// неверного типа в деталях указывается его имя.
+func InvalidBody(err error) error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return ErrInvalidBody.WithField(typeErr.Field, "ожидается "+typeErr.Type.String())
+	}
+	return fmt.Errorf("%w: %v", ErrInvalidBody, err)
+}
+
+// InvalidQuery описывает неверный параметр запроса name.
+func InvalidQuery(name string) error {
+	return ErrInvalidQuery.WithField(name, "неверное значение")
+}
+
+// tooManyRequests - отказ лимитера с временем до следующей попытки.
+type tooManyRequests struct {
+	seconds int
+}
+
+func (e *tooManyRequests) Error() string {
+	return ErrTooManyRequests.Error()
+}
+
+func (e *tooManyRequests) Unwrap() error {
+	return ErrTooManyRequests
+}
+
+func (e *tooManyRequests) RetryAfterSeconds() int {
+	return e.seconds
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newErrorRouter возвращает роутер с цепочкой RequestIDMiddleware
+// и ErrorMiddleware, маршрут /fail которого завершается ошибкой err.
+func newErrorRouter(log *zap.Logger, err error) *gin.Engine {
+	r := gin.New()
+	r.Use(RequestIDMiddleware(log), ErrorMiddleware(log))
+	r.GET("/fail", func(c *gin.Context) {
+		c.Error(err)
+	})
+	return r
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		code       string
+		message    string
+		details    map[string]string
+		retryAfter string
+	}{
+		{"not found", models.ErrorNotFoundThread, http.StatusNotFound, "thread_not_found", "Тред не найден", nil, ""},
+		{"forbidden", models.Forbidden("no_access", "Нет доступа"), http.StatusForbidden, "no_access", "Нет доступа", nil, ""},
+		{"validation", models.Validation("invalid_post", "Неверный пост").WithField("content", "пустое"), http.StatusBadRequest, "invalid_post", "Неверный пост", map[string]string{"content": "пустое"}, ""},
+		{"conflict", models.Conflict("not_pending", "Не ожидает проверки"), http.StatusConflict, "not_pending", "Не ожидает проверки", nil, ""},
+		{"rate limited", models.RateLimited("rate_limited", "Слишком много запросов"), http.StatusTooManyRequests, "rate_limited", "Слишком много запросов", nil, ""},
+		{"unauthorized", ErrUnauthorized, http.StatusUnauthorized, "unauthorized", "Требуется авторизация", nil, ""},
+		{"wrapped", fmt.Errorf("Ошибка поиска треда: %w", models.ErrorNotFoundThread), http.StatusNotFound, "thread_not_found", "Тред не найден", nil, ""},
+		{"slow mode", &models.SlowModeError{Wait: 1500 * time.Millisecond}, http.StatusTooManyRequests, "slow_mode", models.ErrorSlowMode.Message, nil, "2"},
+		{"internal", errors.New("sql: database is locked at /var/lib/forum.db"), http.StatusInternalServerError, CodeInternal, "Внутренняя ошибка сервера", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.ErrorLevel)
+			req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+			req.Header.Set(RequestIDHeader, "req-42")
+			rec := httptest.NewRecorder()
+
+			newErrorRouter(zap.New(core), tt.err).ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.retryAfter, rec.Header().Get("Retry-After"))
+
+			var body ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.message, body.Error)
+			assert.Equal(t, tt.details, body.Details)
+			assert.Equal(t, "req-42", body.RequestID)
+
+			if tt.status == http.StatusInternalServerError {
+				assert.NotContains(t, rec.Body.String(), "database is locked", "текст внутренней ошибки не должен уходить клиенту")
+				assert.Equal(t, 1, logs.Len(), "внутренняя ошибка должна попасть в лог")
+			} else {
+				assert.Zero(t, logs.Len())
+			}
+		})
+	}
+}
+
+func TestErrorMiddlewareKeepsWrittenResponse(t *testing.T) {
+	r := gin.New()
+	r.Use(ErrorMiddleware(zap.NewNop()))
+	r.GET("/partial", func(c *gin.Context) {
+		c.String(http.StatusOK, "готово")
+		c.Error(errors.New("ошибка после ответа"))
+	})
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/partial", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "готово", rec.Body.String())
+}
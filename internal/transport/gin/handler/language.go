@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"github.com/fire9900/forum/pkg/i18n"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// LanguageMiddleware выбирает язык ответов по заголовку Accept-Language,
+// а если ни один язык не подходит - defaultLang. Переводчик запроса
+// кладется в контекст запроса: по нему ErrorMiddleware и обработчики
+// переводят сообщения. Выбранный язык возвращается в Content-Language.
+func LanguageMiddleware(defaultLang string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		setLanguage(c, i18n.Match(c.GetHeader("Accept-Language"), defaultLang))
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+// languageStore возвращает язык, выбранный пользователем, или пустую
+// строку, если пользователь его не выбирал.
+type languageStore interface {
+	GetLanguage(ctx context.Context, userID int) (string, error)
+}
+
+// UserLanguageMiddleware заменяет язык из Accept-Language языком, который
+// пользователь выбрал в настройках. Ставится после AuthMiddleware. Если
+// настройки недоступны, запрос обслуживается на языке из заголовка.
+func UserLanguageMiddleware(store languageStore, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetInt("userID")
+		lang, err := store.GetLanguage(c.Request.Context(), userID)
+		if err != nil {
+			logger.From(c.Request.Context(), log).Warn("Ошибка получения языка пользователя",
+				zap.Int("userID", userID),
+				zap.Error(err))
+		}
+		if i18n.Supported(lang) {
+			setLanguage(c, lang)
+		}
+		c.Next()
+	}
+}
+
+func setLanguage(c *gin.Context, lang string) {
+	c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), i18n.For(lang)))
+	c.Header("Content-Language", lang)
+}
+
+// Message возвращает ответ key из раздела messages каталога на языке
+// запроса или fallback, если перевода нет.
+func Message(c *gin.Context, key, fallback string) string {
+	return i18n.From(c.Request.Context()).T("messages."+key, fallback)
+}
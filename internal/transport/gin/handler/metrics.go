@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"github.com/fire9900/forum/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// MetricsMiddleware замеряет время обработки запроса и код ответа. Запросы
+// к несуществующим маршрутам учитываются под общим именем, чтобы сканеры
+// не раздували число серий.
+func MetricsMiddleware(m *metrics.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -1,53 +1,94 @@
 package handler
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	"github.com/fire9900/auth/pkg/client"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/tracing"
+	"github.com/fire9900/forum/pkg/logger"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"strings"
 )
 
 var (
-	ErrMissingAuthHeader = errors.New("отсутствует заголовок авторизации")
-	ErrInvalidToken      = errors.New("невалидный токен")
+	ErrMissingAuthHeader = models.Unauthorized("missing_auth_header", "Отсутствует заголовок авторизации")
+	ErrInvalidToken      = models.Unauthorized("invalid_token", "Невалидный токен")
 )
 
+// traceAuth оборачивает вызов сервиса авторизации клиентским спаном.
+// Клиент сервиса не принимает контекст, поэтому трассировка в сам gRPC-вызов
+// не передается: спан показывает только время ожидания ответа.
+func traceAuth[T any](ctx context.Context, name string, call func() (T, error)) (T, error) {
+	_, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.RPCSystemGRPC))
+	result, err := call()
+	tracing.End(span, err)
+	return result, err
+}
+
+// AuthMiddleware пропускает только запросы с действительным токеном
+// и кладет ID пользователя в контекст под ключом userID.
 func AuthMiddleware(authClient *client.AuthClient) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": ErrMissingAuthHeader,
-			})
+		if c.GetHeader("Authorization") == "" {
+			c.Error(ErrMissingAuthHeader)
+			c.Abort()
 			return
 		}
-
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		valid, err := authClient.ValidateToken(tokenString)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error":   ErrInvalidToken,
-				"details": err.Error(),
-			})
+		if !authenticate(c, authClient) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		if !valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+// OptionalAuthMiddleware для публичных маршрутов: запрос без заголовка
+// авторизации обслуживается как анонимный, а с заголовком проверяется так
+// же, как в AuthMiddleware. По ID пользователя публичные маршруты показывают
+// автору и модераторам скрытые от остальных записи.
+func OptionalAuthMiddleware(authClient *client.AuthClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" && !authenticate(c, authClient) {
 			return
 		}
+		c.Next()
+	}
+}
 
-		userID, err := authClient.GetUserID(tokenString)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error":   "Failed to get user ID",
-				"details": err.Error(),
-			})
-			return
-		}
+// authenticate проверяет токен из заголовка Authorization. При ошибке
+// запрос прерывается и возвращается false.
+func authenticate(c *gin.Context, authClient *client.AuthClient) bool {
+	tokenString := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	valid, err := traceAuth(c.Request.Context(), "AuthClient.ValidateToken", func() (bool, error) {
+		return authClient.ValidateToken(tokenString)
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("%w: %v", ErrInvalidToken, err))
+		c.Abort()
+		return false
+	}
 
-		c.Set("userID", int(userID))
-		c.Next()
+	if !valid {
+		c.Error(ErrInvalidToken)
+		c.Abort()
+		return false
+	}
+
+	userID, err := traceAuth(c.Request.Context(), "AuthClient.GetUserID", func() (int32, error) {
+		return authClient.GetUserID(tokenString)
+	})
+	if err != nil {
+		c.Error(fmt.Errorf("%w: не удалось получить ID пользователя: %v", ErrInvalidToken, err))
+		c.Abort()
+		return false
 	}
+
+	c.Set("userID", int(userID))
+	c.Request = c.Request.WithContext(logger.With(c.Request.Context(), zap.Int("user_id", int(userID))))
+	return true
 }
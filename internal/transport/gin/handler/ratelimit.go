@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/fire9900/forum/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"strconv"
+)
+
+// RateLimitMiddleware ограничивает частоту запросов к endpoint по пользователю,
+// IP и треду. Должен стоять после AuthMiddleware, чтобы учитывать пользователя.
+// Если хранилище лимитов недоступно, запрос пропускается.
+func RateLimitMiddleware(limiter *ratelimit.Limiter, endpoint string, log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys := ratelimit.Keys{
+			UserID:   c.GetInt("userID"),
+			IP:       c.ClientIP(),
+			ThreadID: threadID(c),
+		}
+
+		result, err := limiter.Allow(c.Request.Context(), endpoint, keys)
+		if err != nil {
+			logger.From(c.Request.Context(), log).Error("Ошибка проверки лимита запросов",
+				zap.String("endpoint", endpoint),
+				zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !result.Allowed {
+			logger.From(c.Request.Context(), log).Warn("Превышен лимит запросов",
+				zap.String("endpoint", endpoint),
+				zap.Int("userID", keys.UserID),
+				zap.String("ip", keys.IP),
+				zap.Int("threadID", keys.ThreadID))
+			c.Error(&tooManyRequests{seconds: result.RetryAfterSeconds()})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// maxThreadIDBody ограничивает часть тела, которую лимитер читает в поисках
+// thread_id: пост не длиннее 5000 символов вместе с JSON-экранированием
+// заведомо помещается в этот объем.
+const maxThreadIDBody = 64 << 10
+
+// threadID берет ID треда из параметра пути, а для JSON-запросов - из поля
+// thread_id тела. Читается не больше maxThreadIDBody байт; тело целиком
+// восстанавливается для последующего разбора в хендлере.
+func threadID(c *gin.Context) int {
+	if id, err := strconv.Atoi(c.Param("id")); err == nil {
+		return id
+	}
+	if c.Request.Body == nil || c.ContentType() != gin.MIMEJSON {
+		return 0
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxThreadIDBody+1))
+	c.Request.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), c.Request.Body), c.Request.Body}
+	if err != nil || len(body) > maxThreadIDBody {
+		return 0
+	}
+
+	var payload struct {
+		ThreadID int `json:"thread_id"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return 0
+	}
+	return payload.ThreadID
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/fire9900/forum/pkg/ratelimit"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newRateLimitRouter возвращает роутер POST /posts с лимитом в один запрос
+// на тред; хендлер отвечает длиной прочитанного тела.
+func newRateLimitRouter() *gin.Engine {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), ratelimit.Config{Endpoints: map[string]ratelimit.Rules{
+		ratelimit.EndpointPostCreate: {ratelimit.ScopeThread: ratelimit.PerMinute(1, 1)},
+	}})
+
+	r := gin.New()
+	r.Use(ErrorMiddleware(zap.NewNop()))
+	r.POST("/posts", RateLimitMiddleware(limiter, ratelimit.EndpointPostCreate, zap.NewNop()), func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, strconv.Itoa(len(body)))
+	})
+	return r
+}
+
+func postJSON(r *gin.Engine, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(body))
+	req.Header.Set("Content-Type", gin.MIMEJSON)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	r := newRateLimitRouter()
+	body := `{"thread_id": 2, "content": "Привет"}`
+
+	first := postJSON(r, body)
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, strconv.Itoa(len(body)), first.Body.String(), "тело должно дойти до хендлера целиком")
+
+	second := postJSON(r, body)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+	retryAfter, err := strconv.Atoi(second.Header().Get("Retry-After"))
+	assert.NoError(t, err)
+	assert.Positive(t, retryAfter)
+
+	var resp ErrorResponse
+	assert.NoError(t, json.Unmarshal(second.Body.Bytes(), &resp))
+	assert.Equal(t, "rate_limited", resp.Code)
+
+	other := postJSON(r, `{"thread_id": 3}`)
+	assert.Equal(t, http.StatusOK, other.Code, "лимит другого треда не должен расходоваться")
+}
+
+func TestRateLimitMiddlewareLargeBody(t *testing.T) {
+	r := newRateLimitRouter()
+	body := `{"thread_id": 2, "content": "` + strings.Repeat("a", 2*maxThreadIDBody) + `"}`
+
+	for i := 0; i < 2; i++ {
+		rec := postJSON(r, body)
+		assert.Equal(t, http.StatusOK, rec.Code, "тред из слишком большого тела не разбирается")
+		assert.Equal(t, strconv.Itoa(len(body)), rec.Body.String(), "тело должно дойти до хендлера целиком")
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader - заголовок с ID запроса. Входящий ID сохраняется, чтобы
+// запрос можно было проследить через несколько сервисов.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength ограничивает входящий ID: он попадает в логи и ответы.
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware назначает запросу ID: берет его из X-Request-ID или
+// генерирует новый. ID возвращается в заголовке ответа, ErrorMiddleware
+// добавляет его в тело ответов с ошибкой, а в контекст запроса кладется
+// производный от log логгер с ID, маршрутом и методом.
+func RequestIDMiddleware(log *zap.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Set("requestID", id)
+		c.Header(RequestIDHeader, id)
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx := c.Request.Context()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+		ctx = logger.NewContext(ctx, log.With(
+			zap.String("request_id", id),
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+		))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// RequestID возвращает ID текущего запроса.
+func RequestID(c *gin.Context) string {
+	return c.GetString("requestID")
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
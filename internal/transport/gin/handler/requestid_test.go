@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		echoed   bool
+	}{
+		{"incoming id echoed", "trace-abc-123", true},
+		{"missing id generated", "", false},
+		{"id with spaces replaced", "два слова", false},
+		{"too long id replaced", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			r := gin.New()
+			r.Use(RequestIDMiddleware(zap.New(core)), ErrorMiddleware(zap.NewNop()))
+			r.GET("/threads/:id", func(c *gin.Context) {
+				logger.From(c.Request.Context(), zap.NewNop()).Info("Запрос обработан")
+				c.Error(models.ErrorNotFoundThread)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/threads/1", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if tt.echoed {
+				assert.Equal(t, tt.incoming, id)
+			} else {
+				assert.NotEmpty(t, id)
+				assert.NotEqual(t, tt.incoming, id)
+				assert.True(t, validRequestID(id), "сгенерированный ID должен быть допустимым: %q", id)
+			}
+
+			var body ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, id, body.RequestID, "ID запроса должен быть в теле ошибки")
+
+			entries := logs.FilterMessage("Запрос обработан").All()
+			if assert.Len(t, entries, 1) {
+				fields := entries[0].ContextMap()
+				assert.Equal(t, id, fields["request_id"])
+				assert.Equal(t, "/threads/:id", fields["route"])
+				assert.Equal(t, http.MethodGet, fields["method"])
+			}
+		})
+	}
+}
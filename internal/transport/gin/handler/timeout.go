@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"time"
+)
+
+// TimeoutMiddleware ограничивает время обработки запроса: по истечении
+// timeout или при отключении клиента контекст запроса отменяется, а вместе
+// с ним и запросы к базе данных.
+func TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,39 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/pkg/health"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *zap.Logger
+}
+
+func NewHealthHandler(checker *health.Checker, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{checker: checker, logger: logger}
+}
+
+// Healthz - проба живости: отвечает 200, пока процесс обслуживает запросы,
+// и не зависит от состояния внешних сервисов.
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readyz - проба готовности: проверяет базу, сервис авторизации, хаб
+// WebSocket и версию схемы. Отвечает 503 с деталями по каждой зависимости,
+// если хотя бы одна недоступна или сервер останавливается.
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	report := h.checker.Ready(c.Request.Context())
+	if !report.Ready() {
+		logger.From(c.Request.Context(), h.logger).Warn("Экземпляр не готов принимать трафик",
+			zap.String("status", report.Status),
+			zap.Any("checks", report.Checks))
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,333 @@
+package gin
+
+import (
+	"fmt"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ModerationHandler struct {
+	modCase usecase.ModerationUseCase
+	logger  *zap.Logger
+}
+
+func NewModerationHandler(M usecase.ModerationUseCase, logger *zap.Logger) *ModerationHandler {
+	return &ModerationHandler{modCase: M, logger: logger}
+}
+
+// currentUserID достает ID пользователя, установленный AuthMiddleware.
+// При ошибке она уже передана в c.Error.
+func currentUserID(c *gin.Context, log *zap.Logger) (int, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		logger.From(c.Request.Context(), log).Warn("Запрос без авторизации")
+		c.Error(handler.ErrUnauthorized)
+		return 0, false
+	}
+
+	uid, ok := userID.(int)
+	if !ok {
+		c.Error(fmt.Errorf("Неверный тип userID: %T", userID))
+		return 0, false
+	}
+	return uid, true
+}
+
+// @Summary Получить категории
+// @Description Получить список всех категорий
+// @Tags categories
+// @Produce json
+// @Success 200 {array} models.Category
+// @Failure 500 {object} object
+// @Router /categories [get]
+func (h *ModerationHandler) GetCategories(c *gin.Context) {
+	categories, err := h.modCase.GetCategories(c.Request.Context())
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения категорий", zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, categories)
+}
+
+// @Summary Создать категорию
+// @Description Создать новую категорию. Доступно администратору
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param category body models.Category true "Данные категории"
+// @Success 200 {object} models.Category
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /admin/categories [post]
+func (h *ModerationHandler) CreateCategory(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var category models.Category
+	if err := c.ShouldBindJSON(&category); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	created, err := h.modCase.CreateCategory(c.Request.Context(), category, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка создания категории",
+			zap.String("name", category.Name),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	logger.From(c.Request.Context(), h.logger).Info("Категория создана",
+		zap.Int("id", created.ID),
+		zap.Int("userID", uid))
+	c.JSON(http.StatusOK, created)
+}
+
+// @Summary Назначить модератора категории
+// @Description Выдать пользователю права модератора в пределах категории. Доступно администратору
+// @Tags categories
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID категории"
+// @Param moderator body object true "ID пользователя: {\"user_id\": 1}"
+// @Success 200 {object} object
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/categories/{id}/moderators [post]
+func (h *ModerationHandler) AddCategoryModerator(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var body struct {
+		UserID int `json:"user_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+	if body.UserID <= 0 {
+		c.Error(handler.ErrInvalidBody.WithField("user_id", "не указан пользователь"))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.modCase.AddCategoryModerator(c.Request.Context(), categoryID, body.UserID, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка назначения модератора категории",
+			zap.Int("categoryID", categoryID),
+			zap.Int("moderatorID", body.UserID),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": handler.Message(c, "category_moderator_added", "Модератор категории назначен")})
+}
+
+// @Summary Снять модератора категории
+// @Description Отозвать у пользователя права модератора категории. Доступно администратору
+// @Tags categories
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID категории"
+// @Param userID path int true "ID пользователя"
+// @Success 200 {object} object
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/categories/{id}/moderators/{userID} [delete]
+func (h *ModerationHandler) RemoveCategoryModerator(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+	moderatorID, err := strconv.Atoi(c.Param("userID"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.modCase.RemoveCategoryModerator(c.Request.Context(), categoryID, moderatorID, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка снятия модератора категории",
+			zap.Int("categoryID", categoryID),
+			zap.Int("moderatorID", moderatorID),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": handler.Message(c, "category_moderator_removed", "Модератор категории снят")})
+}
+
+// @Summary Объединить треды
+// @Description Перенести посты и чат треда в другой тред и удалить исходный тред с сохранением перенаправления
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID объединяемого треда"
+// @Param target body object true "ID треда, в который выполняется объединение: {\"target_id\": 1}"
+// @Success 200 {object} models.Thread
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/threads/{id}/merge [post]
+func (h *ModerationHandler) MergeThreads(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var body struct {
+		TargetID int `json:"target_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := h.modCase.MergeThreads(c.Request.Context(), id, body.TargetID, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка объединения тредов",
+			zap.Int("fromID", id),
+			zap.Int("toID", body.TargetID),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	logger.From(c.Request.Context(), h.logger).Info("Треды объединены",
+		zap.Int("fromID", id),
+		zap.Int("toID", body.TargetID),
+		zap.Int("userID", uid))
+	c.JSON(http.StatusOK, thread)
+}
+
+// @Summary Разделить тред
+// @Description Выделить диапазон постов треда в новый тред
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Param split body models.SplitRequest true "Диапазон постов и данные нового треда"
+// @Success 200 {object} models.Thread
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/threads/{id}/split [post]
+func (h *ModerationHandler) SplitThread(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var req models.SplitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+	req.ThreadID = id
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := h.modCase.SplitThread(c.Request.Context(), req, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка разделения треда",
+			zap.Int("threadID", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	logger.From(c.Request.Context(), h.logger).Info("Тред разделен",
+		zap.Int("threadID", id),
+		zap.Int("newThreadID", thread.ID),
+		zap.Int("userID", uid))
+	c.JSON(http.StatusOK, thread)
+}
+
+// @Summary Перенести тред
+// @Description Перенести тред в другую категорию. category_id = 0 убирает тред из категории
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Param category body object true "ID категории: {\"category_id\": 1}"
+// @Success 200 {object} models.Thread
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /admin/threads/{id}/category [put]
+func (h *ModerationHandler) MoveThread(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var body struct {
+		CategoryID int `json:"category_id"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := h.modCase.MoveThread(c.Request.Context(), id, body.CategoryID, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка переноса треда",
+			zap.Int("threadID", id),
+			zap.Int("categoryID", body.CategoryID),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	logger.From(c.Request.Context(), h.logger).Info("Тред перенесен",
+		zap.Int("threadID", id),
+		zap.Int("categoryID", body.CategoryID),
+		zap.Int("userID", uid))
+	c.JSON(http.StatusOK, thread)
+}
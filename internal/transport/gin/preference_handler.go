@@ -0,0 +1,82 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type PreferenceHandler struct {
+	preferenceCase usecase.PreferenceUseCase
+	logger         *zap.Logger
+}
+
+func NewPreferenceHandler(U usecase.PreferenceUseCase, logger *zap.Logger) *PreferenceHandler {
+	return &PreferenceHandler{preferenceCase: U, logger: logger}
+}
+
+// @Summary Язык ответов
+// @Description Получить язык ответов API, выбранный пользователем. Пустой язык означает, что он определяется по Accept-Language
+// @Tags preferences
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.LanguagePreference
+// @Failure 401 {object} object
+// @Router /me/language [get]
+func (h *PreferenceHandler) GetLanguage(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	language, err := h.preferenceCase.GetLanguage(c.Request.Context(), uid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, models.LanguagePreference{Language: language})
+}
+
+// @Summary Выбрать язык ответов
+// @Description Сохранить язык ответов API. Выбранный язык важнее заголовка Accept-Language, пустой язык сбрасывает выбор
+// @Tags preferences
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param language body models.LanguagePreference true "{\"language\": \"en\"}"
+// @Success 200 {object} models.LanguagePreference
+// @Failure 400 {object} object
+// @Failure 401 {object} object
+// @Router /me/language [put]
+func (h *PreferenceHandler) SetLanguage(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var body models.LanguagePreference
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	if err := h.preferenceCase.SetLanguage(c.Request.Context(), uid, body.Language); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка изменения языка пользователя",
+			zap.Int("userID", uid),
+			zap.String("language", body.Language),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	language, err := h.preferenceCase.GetLanguage(c.Request.Context(), uid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, models.LanguagePreference{Language: language})
+}
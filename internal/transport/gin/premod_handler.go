@@ -0,0 +1,200 @@
+package gin
+
+import (
+	"errors"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/fire9900/forum/pkg/wsserver"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+type PremodHandler struct {
+	premodCase usecase.PremodUseCase
+	hub        *wsserver.Hub
+	logger     *zap.Logger
+}
+
+func NewPremodHandler(Q usecase.PremodUseCase, hub *wsserver.Hub, logger *zap.Logger) *PremodHandler {
+	return &PremodHandler{premodCase: Q, hub: hub, logger: logger}
+}
+
+// @Summary Очередь премодерации
+// @Description Получить треды и посты, ожидающие проверки. Модератор категории видит только очередь своих категорий
+// @Tags premod
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.PendingQueue
+// @Failure 403 {object} object
+// @Router /mod/pending [get]
+func (h *PremodHandler) GetQueue(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	queue, err := h.premodCase.GetQueue(c.Request.Context(), uid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, queue)
+}
+
+// @Summary Одобрить пост
+// @Description Опубликовать пост из очереди премодерации. Пост рассылается подписчикам чата треда
+// @Tags premod
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID поста"
+// @Success 200 {object} models.Post
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Failure 409 {object} object
+// @Router /mod/posts/{id}/approve [post]
+func (h *PremodHandler) ApprovePost(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+
+	post, err := h.premodCase.ApprovePost(c.Request.Context(), id, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка одобрения поста",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	h.hub.BroadcastPost(post)
+	c.JSON(http.StatusOK, post)
+}
+
+// @Summary Отклонить пост
+// @Description Удалить пост из очереди премодерации. Автор получит уведомление с причиной
+// @Tags premod
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "ID поста"
+// @Param decision body object false "{\"reason\": \"...\"}"
+// @Success 204
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Failure 409 {object} object
+// @Router /mod/posts/{id}/reject [post]
+func (h *PremodHandler) RejectPost(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+	reason, ok := rejectReason(c)
+	if !ok {
+		return
+	}
+
+	if err := h.premodCase.RejectPost(c.Request.Context(), id, reason, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка отклонения поста",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Одобрить тред
+// @Description Опубликовать тред из очереди премодерации
+// @Tags premod
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Success 200 {object} models.Thread
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Failure 409 {object} object
+// @Router /mod/threads/{id}/approve [post]
+func (h *PremodHandler) ApproveThread(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := h.premodCase.ApproveThread(c.Request.Context(), id, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка одобрения треда",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, thread)
+}
+
+// @Summary Отклонить тред
+// @Description Удалить тред из очереди премодерации вместе с его постами. Автор получит уведомление с причиной
+// @Tags premod
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Param decision body object false "{\"reason\": \"...\"}"
+// @Success 204
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Failure 409 {object} object
+// @Router /mod/threads/{id}/reject [post]
+func (h *PremodHandler) RejectThread(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+	reason, ok := rejectReason(c)
+	if !ok {
+		return
+	}
+
+	if err := h.premodCase.RejectThread(c.Request.Context(), id, reason, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка отклонения треда",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// premodTarget достает ID объекта из пути и ID текущего пользователя.
+// При ошибке она уже передана в c.Error.
+func premodTarget(c *gin.Context, log *zap.Logger) (int, int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return 0, 0, false
+	}
+
+	uid, ok := currentUserID(c, log)
+	if !ok {
+		return 0, 0, false
+	}
+	return id, uid, true
+}
+
+// rejectReason читает необязательную причину отклонения из тела запроса.
+func rejectReason(c *gin.Context) (string, bool) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.Error(handler.InvalidBody(err))
+		return "", false
+	}
+	if len(body.Reason) > 1000 {
+		c.Error(handler.ErrInvalidBody.WithField("reason", "длиннее 1000 символов"))
+		return "", false
+	}
+	return body.Reason, true
+}
@@ -0,0 +1,262 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type ReportHandler struct {
+	reportCase usecase.ReportUseCase
+	logger     *zap.Logger
+}
+
+func NewReportHandler(R usecase.ReportUseCase, logger *zap.Logger) *ReportHandler {
+	return &ReportHandler{reportCase: R, logger: logger}
+}
+
+// @Summary Пожаловаться
+// @Description Отправить жалобу на пост или тред
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param report body models.Report true "Жалоба: target_type (post|thread), target_id, reason"
+// @Success 200 {object} models.Report
+// @Failure 400 {object} object
+// @Failure 401 {object} object
+// @Failure 404 {object} object
+// @Router /reports [post]
+func (h *ReportHandler) CreateReport(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		TargetType string `json:"target_type"`
+		TargetID   int    `json:"target_id"`
+		Reason     string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	report, err := h.reportCase.CreateReport(c.Request.Context(), models.Report{
+		ReporterID: uid,
+		TargetType: body.TargetType,
+		TargetID:   body.TargetID,
+		Reason:     body.Reason,
+	})
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка создания жалобы",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Очередь жалоб
+// @Description Получить жалобы с фильтрацией. Доступно модераторам
+// @Tags reports
+// @Produce json
+// @Security ApiKeyAuth
+// @Param status query string false "Статус: open, actioned, dismissed"
+// @Param target_type query string false "Тип объекта: post, thread"
+// @Param reporter_id query int false "ID автора жалобы"
+// @Param limit query int false "Количество записей"
+// @Param offset query int false "Смещение"
+// @Success 200 {array} models.Report
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /mod/reports [get]
+func (h *ReportHandler) GetReports(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	filter := models.ReportFilter{
+		Status:     c.Query("status"),
+		TargetType: c.Query("target_type"),
+	}
+	for name, dest := range map[string]*int{
+		"reporter_id": &filter.ReporterID,
+		"limit":       &filter.Limit,
+		"offset":      &filter.Offset,
+	} {
+		if value := c.Query(name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				c.Error(handler.InvalidQuery(name))
+				return
+			}
+			*dest = n
+		}
+	}
+
+	reports, err := h.reportCase.GetReports(c.Request.Context(), filter, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения жалоб",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+// @Summary Рассмотреть жалобу
+// @Description Перевести жалобу в статус actioned или dismissed. Автор жалобы получит уведомление
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID жалобы"
+// @Param decision body object true "Решение: {\"status\": \"dismissed\", \"resolution\": \"...\"}"
+// @Success 200 {object} models.Report
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Failure 409 {object} object
+// @Router /mod/reports/{id} [patch]
+func (h *ReportHandler) ResolveReport(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var body struct {
+		Status     string `json:"status"`
+		Resolution string `json:"resolution"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	report, err := h.reportCase.ResolveReport(c.Request.Context(), id, body.Status, body.Resolution, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка рассмотрения жалобы",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// @Summary Массовое действие над жалобами
+// @Description Удалить объекты жалоб (delete) или отклонить жалобы (dismiss)
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param bulk body object true "{\"ids\": [1, 2], \"action\": \"delete\", \"resolution\": \"...\"}"
+// @Success 200 {array} models.BulkReportResult
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /mod/reports/bulk [post]
+func (h *ReportHandler) BulkAction(c *gin.Context) {
+	var body struct {
+		IDs        []int  `json:"ids"`
+		Action     string `json:"action"`
+		Resolution string `json:"resolution"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+	if len(body.IDs) == 0 {
+		c.Error(handler.ErrInvalidBody.WithField("ids", "пустой список"))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	results, err := h.reportCase.BulkAction(c.Request.Context(), body.IDs, body.Action, body.Resolution, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка массового действия над жалобами",
+			zap.Ints("ids", body.IDs),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+// @Summary Уведомления
+// @Description Получить уведомления текущего пользователя
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {array} models.Notification
+// @Failure 401 {object} object
+// @Router /notifications [get]
+func (h *ReportHandler) GetNotifications(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	notifications, err := h.reportCase.GetNotifications(c.Request.Context(), uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения уведомлений",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// @Summary Прочитать уведомление
+// @Description Отметить уведомление прочитанным
+// @Tags notifications
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID уведомления"
+// @Success 200
+// @Failure 404 {object} object
+// @Router /notifications/{id}/read [post]
+func (h *ReportHandler) MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.reportCase.MarkNotificationRead(c.Request.Context(), id, uid); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, nil)
+}
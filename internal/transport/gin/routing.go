@@ -2,8 +2,12 @@ package gin
 
 import (
 	"github.com/fire9900/auth/pkg/client"
+	"github.com/fire9900/forum/internal/config"
+	"github.com/fire9900/forum/internal/metrics"
 	"github.com/fire9900/forum/internal/transport/gin/handler"
 	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/health"
+	"github.com/fire9900/forum/pkg/ratelimit"
 	"github.com/fire9900/forum/pkg/wsserver"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -13,33 +17,68 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
-func SetupRouter(P usecase.PostUseCase, T usecase.ThreadUseCase, authClient *client.AuthClient, hub *wsserver.Hub) *gin.Engine {
+func SetupRouter(cfg config.HTTPConfig, i18nCfg config.I18nConfig, P usecase.PostUseCase, T usecase.ThreadUseCase, M usecase.ModerationUseCase, R usecase.ReportUseCase, B usecase.BanUseCase, A usecase.AutomodUseCase, Q usecase.PremodUseCase, S usecase.SpamUseCase, L usecase.AuditUseCase, D usecase.TrashUseCase, U usecase.PreferenceUseCase, authClient *client.AuthClient, hub *wsserver.Hub, limiter *ratelimit.Limiter, checker *health.Checker, m *metrics.Metrics, log *zap.Logger) *gin.Engine {
 	router := gin.Default()
+	router.Use(handler.TracingMiddleware())
+	router.Use(handler.RequestIDMiddleware(log))
+	router.Use(handler.MetricsMiddleware(m))
 	router.Use(cors.New(cors.Config{
-		AllowOrigins:     []string{"http://localhost:3000"},
+		AllowOrigins:     cfg.CORSOrigins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "traceparent", "tracestate", handler.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", handler.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
+	// Стоит после CORS: тот перезаписывает заголовок Vary.
+	router.Use(handler.LanguageMiddleware(i18nCfg.DefaultLanguage))
+	// Стоит после метрик и трассировки: они должны видеть итоговый статус.
+	router.Use(handler.ErrorMiddleware(log))
+	router.NoRoute(func(c *gin.Context) {
+		c.Error(handler.ErrRouteNotFound)
+	})
 
-	forumHandler := NewForumHandler(P, T)
-	go hub.Run()
+	forumHandler := NewForumHandler(P, T, hub, log)
+	moderationHandler := NewModerationHandler(M, log)
+	reportHandler := NewReportHandler(R, log)
+	banHandler := NewBanHandler(B, log)
+	automodHandler := NewAutomodHandler(A, log)
+	premodHandler := NewPremodHandler(Q, hub, log)
+	spamHandler := NewSpamHandler(S, log)
+	auditHandler := NewAuditHandler(L, log)
+	trashHandler := NewTrashHandler(D, log)
+	preferenceHandler := NewPreferenceHandler(U, log)
+	healthHandler := NewHealthHandler(checker, log)
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/healthz", healthHandler.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/metrics", gin.WrapH(m.Handler()))
 	api := router.Group("/api/v2")
+	// WebSocket-соединение не подчиняется дедлайну: у каждого его сообщения
+	// свой таймаут.
+	api.Use(handler.TimeoutMiddleware(cfg.RequestTimeout))
 	{
-		api.GET("/threads", forumHandler.GetAllThread)
-		api.GET("/thread/:id", forumHandler.GetThreadByID)
+		optionalAuth := handler.OptionalAuthMiddleware(authClient)
+		api.GET("/threads", optionalAuth, forumHandler.GetAllThread)
+		api.GET("/thread/:id", optionalAuth, forumHandler.GetThreadByID)
+		api.GET("/categories", moderationHandler.GetCategories)
 
 		authGroup := api.Group("")
-		authGroup.Use(handler.AuthMiddleware(authClient))
+		authGroup.Use(handler.AuthMiddleware(authClient), handler.UserLanguageMiddleware(U, log))
 		{
-			authGroup.POST("/threads", forumHandler.CreateThread)
-			authGroup.POST("/threads/posts", forumHandler.CreatePost)
+			authGroup.GET("/me/language", preferenceHandler.GetLanguage)
+			authGroup.PUT("/me/language", preferenceHandler.SetLanguage)
+
+			authGroup.POST("/threads",
+				handler.RateLimitMiddleware(limiter, ratelimit.EndpointThreadCreate, log),
+				forumHandler.CreateThread)
+			authGroup.POST("/threads/posts",
+				handler.RateLimitMiddleware(limiter, ratelimit.EndpointPostCreate, log),
+				forumHandler.CreatePost)
 
 			authGroup.GET("/threads/user/:id", forumHandler.GetThreadsByUserID)
 			authGroup.GET("/posts/user/:id", forumHandler.GetPostsByUserID)
@@ -48,9 +87,56 @@ func SetupRouter(P usecase.PostUseCase, T usecase.ThreadUseCase, authClient *cli
 			authGroup.DELETE("/posts/:id", forumHandler.DeletePostByID)
 			authGroup.DELETE("/threads/:id", forumHandler.DeleteTheadByID)
 
+			authGroup.GET("/trash", trashHandler.GetTrash)
+			authGroup.POST("/posts/:id/restore", trashHandler.RestorePost)
+			authGroup.POST("/threads/:id/restore", trashHandler.RestoreThread)
+
 			authGroup.PUT("/threads", forumHandler.EditThread)
+			authGroup.PUT("/threads/:id/state", forumHandler.SetThreadState)
+
+			authGroup.POST("/reports",
+				handler.RateLimitMiddleware(limiter, ratelimit.EndpointReportCreate, log),
+				reportHandler.CreateReport)
+			authGroup.GET("/notifications", reportHandler.GetNotifications)
+			authGroup.POST("/notifications/:id/read", reportHandler.MarkNotificationRead)
+
+			authGroup.GET("/ws/threads/:id", hub.ThreadChat)
+
+			modGroup := authGroup.Group("/mod")
+			{
+				modGroup.GET("/reports", reportHandler.GetReports)
+				modGroup.PATCH("/reports/:id", reportHandler.ResolveReport)
+				modGroup.POST("/reports/bulk", reportHandler.BulkAction)
+
+				modGroup.GET("/pending", premodHandler.GetQueue)
+				modGroup.POST("/posts/:id/approve", premodHandler.ApprovePost)
+				modGroup.POST("/posts/:id/reject", premodHandler.RejectPost)
+				modGroup.POST("/threads/:id/approve", premodHandler.ApproveThread)
+				modGroup.POST("/threads/:id/reject", premodHandler.RejectThread)
+
+				modGroup.GET("/spam", spamHandler.GetScores)
+				modGroup.POST("/posts/:id/spam", spamHandler.MarkPost)
+			}
+
+			adminGroup := authGroup.Group("/admin")
+			{
+				adminGroup.POST("/categories", moderationHandler.CreateCategory)
+				adminGroup.POST("/categories/:id/moderators", moderationHandler.AddCategoryModerator)
+				adminGroup.DELETE("/categories/:id/moderators/:userID", moderationHandler.RemoveCategoryModerator)
+				adminGroup.POST("/threads/:id/merge", moderationHandler.MergeThreads)
+				adminGroup.POST("/threads/:id/split", moderationHandler.SplitThread)
+				adminGroup.PUT("/threads/:id/category", moderationHandler.MoveThread)
+
+				adminGroup.GET("/bans", banHandler.GetBans)
+				adminGroup.POST("/bans", banHandler.BanUser)
+				adminGroup.DELETE("/bans/:id", banHandler.LiftBan)
+
+				adminGroup.GET("/automod", automodHandler.GetConfig)
+				adminGroup.PUT("/automod", automodHandler.UpdateConfig)
+				adminGroup.GET("/duplicates", automodHandler.GetDuplicates)
 
-			api.GET("/ws/threads/:id", hub.ThreadChat)
+				adminGroup.GET("/audit", auditHandler.GetAuditLog)
+			}
 		}
 	}
 
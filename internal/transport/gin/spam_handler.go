@@ -0,0 +1,97 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/transport/gin/handler"
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+type SpamHandler struct {
+	spamCase usecase.SpamUseCase
+	logger   *zap.Logger
+}
+
+func NewSpamHandler(S usecase.SpamUseCase, logger *zap.Logger) *SpamHandler {
+	return &SpamHandler{spamCase: S, logger: logger}
+}
+
+// @Summary Разметить пост как спам
+// @Description Отметить пост как спам (spam) или не спам (ham). Классификатор спама дообучается сразу
+// @Tags spam
+// @Accept json
+// @Security ApiKeyAuth
+// @Param id path int true "ID поста"
+// @Param label body object true "{\"label\": \"spam\"}"
+// @Success 204
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /mod/posts/{id}/spam [post]
+func (h *SpamHandler) MarkPost(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(handler.ErrInvalidID)
+		return
+	}
+
+	var body struct {
+		Label string `json:"label"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.Error(handler.InvalidBody(err))
+		return
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	if err := h.spamCase.MarkPost(c.Request.Context(), id, body.Label, uid); err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка разметки поста",
+			zap.Int("id", id),
+			zap.String("label", body.Label),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Оценки спама
+// @Description Получить посты с оценкой классификатора спама не ниже min, начиная с самых подозрительных
+// @Tags spam
+// @Produce json
+// @Security ApiKeyAuth
+// @Param min query number false "Минимальная оценка от 0 до 1"
+// @Success 200 {array} models.SpamScore
+// @Failure 400 {object} object
+// @Failure 403 {object} object
+// @Router /mod/spam [get]
+func (h *SpamHandler) GetScores(c *gin.Context) {
+	var min float64
+	if value := c.Query("min"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			c.Error(handler.InvalidQuery("min"))
+			return
+		}
+		min = parsed
+	}
+
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	scores, err := h.spamCase.GetScores(c.Request.Context(), min, uid)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, scores)
+}
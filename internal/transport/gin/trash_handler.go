@@ -0,0 +1,97 @@
+package gin
+
+import (
+	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type TrashHandler struct {
+	trashCase usecase.TrashUseCase
+	logger    *zap.Logger
+}
+
+func NewTrashHandler(D usecase.TrashUseCase, logger *zap.Logger) *TrashHandler {
+	return &TrashHandler{trashCase: D, logger: logger}
+}
+
+// @Summary Корзина
+// @Description Получить удаленные треды и посты, которые еще можно восстановить. Модераторы видят всю корзину, остальные - свои сообщения и сообщения модерируемых категорий
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} models.Trash
+// @Failure 403 {object} object
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	uid, ok := currentUserID(c, h.logger)
+	if !ok {
+		return
+	}
+
+	trash, err := h.trashCase.GetTrash(c.Request.Context(), uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка получения корзины",
+			zap.Int("userID", uid),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, trash)
+}
+
+// @Summary Восстановить тред
+// @Description Вернуть тред из корзины вместе с его постами. Автор может восстановить только тред, который удалил сам
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID треда"
+// @Success 200 {object} models.Thread
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /threads/{id}/restore [post]
+func (h *TrashHandler) RestoreThread(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+
+	thread, err := h.trashCase.RestoreThread(c.Request.Context(), id, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка восстановления треда",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, thread)
+}
+
+// @Summary Восстановить пост
+// @Description Вернуть пост из корзины. Пост удаленного треда восстанавливается вместе с тредом
+// @Tags trash
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path int true "ID поста"
+// @Success 200 {object} models.Post
+// @Failure 403 {object} object
+// @Failure 404 {object} object
+// @Router /posts/{id}/restore [post]
+func (h *TrashHandler) RestorePost(c *gin.Context) {
+	id, uid, ok := premodTarget(c, h.logger)
+	if !ok {
+		return
+	}
+
+	post, err := h.trashCase.RestorePost(c.Request.Context(), id, uid)
+	if err != nil {
+		logger.From(c.Request.Context(), h.logger).Error("Ошибка восстановления поста",
+			zap.Int("id", id),
+			zap.Error(err))
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, post)
+}
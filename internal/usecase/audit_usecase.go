@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"go.uber.org/zap"
+)
+
+type AuditUseCase interface {
+	GetAuditLog(ctx context.Context, filter models.AuditFilter, actorID int) ([]models.AuditEntry, error)
+}
+
+type LUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+}
+
+func NewAuditUseCase(repo repository.ForumRepository, logger *zap.Logger) AuditUseCase {
+	return &LUseCase{repo: repo, logger: logger}
+}
+
+// GetAuditLog возвращает записи журнала аудита, начиная с новых.
+// Без лимита отдается models.DefaultAuditLimit записей, лимит выше
+// models.MaxAuditLimit урезается.
+func (f *LUseCase) GetAuditLog(ctx context.Context, filter models.AuditFilter, actorID int) ([]models.AuditEntry, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.AuditView, authz.Resource{}); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = models.DefaultAuditLimit
+	case filter.Limit > models.MaxAuditLimit:
+		filter.Limit = models.MaxAuditLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+	return f.repo.GetAuditLog(ctx, filter)
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestGetAuditLog(t *testing.T) {
+	t.Run("default limit", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetAuditLog", mock.Anything, models.AuditFilter{Action: models.AuditUserBan, Limit: models.DefaultAuditLimit}).
+			Return([]models.AuditEntry{{ID: 1}}, nil).Once()
+
+		u := NewAuditUseCase(mockRepo, zap.NewNop())
+		entries, err := u.GetAuditLog(context.Background(), models.AuditFilter{Action: models.AuditUserBan}, 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("limit clamped", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetAuditLog", mock.Anything, models.AuditFilter{Limit: models.MaxAuditLimit}).
+			Return([]models.AuditEntry{}, nil).Once()
+
+		u := NewAuditUseCase(mockRepo, zap.NewNop())
+		_, err := u.GetAuditLog(context.Background(), models.AuditFilter{Limit: models.MaxAuditLimit + 1}, 1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moderator forbidden", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleModerator}, nil).Once()
+
+		u := NewAuditUseCase(mockRepo, zap.NewNop())
+		_, err := u.GetAuditLog(context.Background(), models.AuditFilter{}, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNotCalled(t, "GetAuditLog", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// authorize загружает роль пользователя и проверяет право action на resource.
+// При отказе возвращает ошибку, оборачивающую models.ErrorForbidden.
+func authorize(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, actorID int, action authz.Permission, resource authz.Resource) error {
+	actor, err := repo.GetActor(ctx, actorID)
+	if err != nil {
+		return fmt.Errorf("%w: %v", models.ErrorForbidden, err)
+	}
+
+	if err := authz.Authorize(actor, action, resource); err != nil {
+		logger.From(ctx, log).Warn("Отказано в доступе",
+			zap.Int("userID", actorID),
+			zap.String("role", actor.Role),
+			zap.String("action", string(action)),
+			zap.Int("ownerID", resource.OwnerID),
+			zap.Int("categoryID", resource.CategoryID))
+		return err
+	}
+	return nil
+}
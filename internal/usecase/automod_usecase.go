@@ -0,0 +1,256 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/dedup"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"strings"
+	"time"
+)
+
+type AutomodUseCase interface {
+	GetConfig(ctx context.Context, actorID int) (automod.Config, error)
+	UpdateConfig(ctx context.Context, cfg automod.Config, actorID int) (automod.Config, error)
+	GetDuplicates(ctx context.Context, window time.Duration, actorID int) ([]models.DuplicateCluster, error)
+	ExpireFingerprints(ctx context.Context) (int64, error)
+}
+
+type AUseCase struct {
+	repo     repository.ForumRepository
+	logger   *zap.Logger
+	engine   *automod.Engine
+	detector *dedup.Detector
+}
+
+func NewAutomodUseCase(repo repository.ForumRepository, engine *automod.Engine, detector *dedup.Detector, logger *zap.Logger) AutomodUseCase {
+	return &AUseCase{repo: repo, logger: logger, engine: engine, detector: detector}
+}
+
+func (f *AUseCase) GetConfig(ctx context.Context, actorID int) (automod.Config, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.AutomodManage, authz.Resource{}); err != nil {
+		return automod.Config{}, err
+	}
+	return f.engine.Config(), nil
+}
+
+// UpdateConfig заменяет правила автомодерации. Если правила загружены из
+// файла, они действуют до следующего изменения файла.
+func (f *AUseCase) UpdateConfig(ctx context.Context, cfg automod.Config, actorID int) (automod.Config, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.AutomodManage, authz.Resource{}); err != nil {
+		return automod.Config{}, err
+	}
+	before := f.engine.Config()
+	if err := f.engine.Load(cfg); err != nil {
+		return automod.Config{}, err
+	}
+
+	// Правила хранятся только в памяти, поэтому запись в журнал не может
+	// откатить их замену: ошибку только логируем.
+	if err := f.repo.AddAuditEntry(ctx, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditAutomodUpdate,
+		TargetType: models.TargetAutomod,
+		Before:     models.Snapshot(before),
+		After:      models.Snapshot(f.engine.Config()),
+	}); err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка записи изменения автомодерации в журнал",
+			zap.Int("actorID", actorID),
+			zap.Error(err))
+	}
+
+	logger.From(ctx, f.logger).Info("Правила автомодерации изменены",
+		zap.Int("actorID", actorID),
+		zap.Int("rules", len(cfg.Rules)),
+		zap.Bool("dryRun", cfg.DryRun))
+	return f.engine.Config(), nil
+}
+
+// GetDuplicates возвращает группы повторяющихся сообщений за window.
+// Нулевое window означает глобальное окно детектора.
+func (f *AUseCase) GetDuplicates(ctx context.Context, window time.Duration, actorID int) ([]models.DuplicateCluster, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.AutomodManage, authz.Resource{}); err != nil {
+		return nil, err
+	}
+
+	cfg := f.detector.Config()
+	if window <= 0 {
+		window = cfg.GlobalWindow
+	}
+	fps, err := f.repo.GetFingerprints(ctx, time.Now().Add(-window), duplicatesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return dedup.Clusters(fps, cfg.MaxDistance), nil
+}
+
+// ExpireFingerprints удаляет отпечатки, вышедшие за окна поиска повторов.
+func (f *AUseCase) ExpireFingerprints(ctx context.Context) (int64, error) {
+	deleted, err := f.repo.DeleteFingerprintsBefore(ctx, f.detector.Since(time.Now()))
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		logger.From(ctx, f.logger).Debug("Старые отпечатки сообщений удалены", zap.Int64("count", deleted))
+	}
+	return deleted, nil
+}
+
+// moderate проверяет тексты сообщения правилами автомодерации и применяет
+// к ним замены. Возвращает действие над сообщением (пустое, если правила не
+// сработали или включен пробный режим) и сработавшие правила.
+func moderate(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, engine *automod.Engine, userID int, texts ...*string) (automod.Action, []automod.Match, error) {
+	if engine == nil {
+		return "", nil, nil
+	}
+
+	content := automod.Content{}
+	if engine.NeedsAuthorPosts() {
+		count, err := repo.CountUserContent(ctx, userID)
+		if err != nil {
+			return "", nil, err
+		}
+		content.AuthorPosts = count
+	}
+
+	var action automod.Action
+	var matches []automod.Match
+	replaced := make([]string, len(texts))
+	dryRun := false
+	for i, text := range texts {
+		content.Text = *text
+		d := engine.Check(content)
+		action = automod.Stronger(action, d.Action)
+		matches = append(matches, d.Matches...)
+		replaced[i] = d.Text
+		dryRun = d.DryRun
+	}
+	if action == "" {
+		return "", nil, nil
+	}
+
+	logger.From(ctx, log).Info("Сработала автомодерация",
+		zap.Int("userID", userID),
+		zap.String("action", string(action)),
+		zap.Strings("rules", matchedRules(matches)),
+		zap.Bool("dryRun", dryRun))
+	if dryRun {
+		return "", nil, nil
+	}
+
+	if action == automod.ActionReject {
+		return action, matches, models.ErrorContentRejected
+	}
+	for i, text := range texts {
+		*text = replaced[i]
+	}
+	return action, matches, nil
+}
+
+// Ограничения выборок отпечатков: всплеск одинаковых сообщений не должен
+// заставлять читать всю таблицу при каждой публикации и в отчете о повторах.
+const (
+	dedupCandidates = 500
+	duplicatesLimit = 10000
+)
+
+// findDuplicates ищет недавние повторы текста автора. При действии reject
+// возвращает models.ErrorDuplicateContent.
+func findDuplicates(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, detector *dedup.Detector, userID int, text string) (dedup.Result, error) {
+	if detector == nil {
+		return dedup.Result{}, nil
+	}
+	hash, ok := detector.Prepare(text)
+	if !ok {
+		return dedup.Result{}, nil
+	}
+
+	now := time.Now()
+	own, err := repo.GetUserFingerprints(ctx, userID, hash, now.Add(-detector.Config().UserWindow))
+	if err != nil {
+		return dedup.Result{}, err
+	}
+	similar, err := repo.GetSimilarFingerprints(ctx, hash, detector.Since(now), dedupCandidates)
+	if err != nil {
+		return dedup.Result{}, err
+	}
+
+	// Точные копии автора ищутся отдельно, чтобы их не вытеснили из выборки
+	// кандидатов чужие сообщения.
+	recent := own
+	for _, fp := range similar {
+		if fp.UserID != userID || fp.Hash != hash {
+			recent = append(recent, fp)
+		}
+	}
+	result := detector.Check(hash, userID, now, recent)
+	if result.Action == "" {
+		return result, nil
+	}
+
+	logger.From(ctx, log).Info("Найден повтор сообщения",
+		zap.Int("userID", userID),
+		zap.String("action", string(result.Action)),
+		zap.Strings("rules", matchedRules(result.Matches)),
+		zap.Bool("exact", result.Exact),
+		zap.Int("duplicates", len(result.Duplicates)))
+	if result.Action == automod.ActionReject {
+		return result, models.ErrorDuplicateContent
+	}
+	return result, nil
+}
+
+// saveFingerprint сохраняет отпечаток созданного сообщения. Ошибка только
+// логируется: без отпечатка пропустится лишь поиск повторов этого сообщения.
+func saveFingerprint(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, result dedup.Result, targetType string, targetID, userID int) {
+	if !result.Checked {
+		return
+	}
+	if err := repo.SaveFingerprint(ctx, models.Fingerprint{
+		TargetType: targetType,
+		TargetID:   targetID,
+		UserID:     userID,
+		Hash:       result.Hash,
+		CreateAt:   time.Now(),
+	}); err != nil {
+		logger.From(ctx, log).Error("Ошибка сохранения отпечатка сообщения",
+			zap.String("targetType", targetType),
+			zap.Int("targetID", targetID),
+			zap.Error(err))
+	}
+}
+
+// reportAutomod отправляет сообщение в очередь модерации, если этого
+// требует действие автомодерации. Ошибка только логируется: сообщение
+// к этому моменту уже сохранено.
+func reportAutomod(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, action automod.Action, matches []automod.Match, targetType string, targetID int) {
+	if action != automod.ActionHold && action != automod.ActionFlag {
+		return
+	}
+
+	_, err := repo.CreateReport(ctx, models.Report{
+		ReporterID: models.AutomodReporterID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Reason:     fmt.Sprintf("Автомодерация (%s): %s", action, strings.Join(matchedRules(matches), ", ")),
+	})
+	if err != nil {
+		logger.From(ctx, log).Error("Ошибка создания жалобы автомодерации",
+			zap.String("targetType", targetType),
+			zap.Int("targetID", targetID),
+			zap.Error(err))
+	}
+}
+
+func matchedRules(matches []automod.Match) []string {
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, m.Rule)
+	}
+	return names
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/dedup"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func newTestEngine(t *testing.T, cfg automod.Config) *automod.Engine {
+	t.Helper()
+	engine, err := automod.NewEngine(cfg)
+	if err != nil {
+		t.Fatalf("NewEngine() error = %v", err)
+	}
+	return engine
+}
+
+func TestCreatePostAutomod(t *testing.T) {
+	engine := newTestEngine(t, automod.Config{Rules: []automod.Rule{
+		{Name: "spam", Kind: automod.KindWords, Action: automod.ActionReject, Words: []string{"спам"}},
+		{Name: "casino", Kind: automod.KindRegex, Action: automod.ActionHold, Pattern: "казино"},
+		{Name: "links", Kind: automod.KindLinks, Action: automod.ActionFlag, NewAccountPosts: 3},
+	}})
+
+	t.Run("reject", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CountUserContent", mock.Anything, 1).Return(10, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetAutomod(engine)
+		_, err := u.CreatePost(context.Background(), models.Post{Content: "купи спам", ThreadID: 1, UserID: 1})
+
+		assert.ErrorIs(t, err, models.ErrorContentRejected)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("hold", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CountUserContent", mock.Anything, 1).Return(10, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p models.Post) bool {
+			return p.Pending
+		})).Return(models.Post{ID: 5, ThreadID: 1, UserID: 1, Pending: true}, nil).Once()
+		mockRepo.On("CreateReport", mock.Anything, mock.MatchedBy(func(r models.Report) bool {
+			return r.ReporterID == models.AutomodReporterID && r.TargetType == models.TargetPost && r.TargetID == 5
+		})).Return(models.Report{ID: 1}, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetAutomod(engine)
+		result, err := u.CreatePost(context.Background(), models.Post{Content: "лучшее казино", ThreadID: 1, UserID: 1})
+
+		assert.NoError(t, err)
+		assert.True(t, result.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("flag for new account", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CountUserContent", mock.Anything, 1).Return(0, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p models.Post) bool {
+			return !p.Pending
+		})).Return(models.Post{ID: 6, ThreadID: 1, UserID: 1}, nil).Once()
+		mockRepo.On("CreateReport", mock.Anything, mock.MatchedBy(func(r models.Report) bool {
+			return r.TargetID == 6
+		})).Return(models.Report{ID: 2}, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetAutomod(engine)
+		result, err := u.CreatePost(context.Background(), models.Post{Content: "https://spam.io", ThreadID: 1, UserID: 1})
+
+		assert.NoError(t, err)
+		assert.False(t, result.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		dryRun := newTestEngine(t, automod.Config{DryRun: true, Rules: []automod.Rule{
+			{Name: "spam", Kind: automod.KindWords, Action: automod.ActionReject, Words: []string{"спам"}},
+		}})
+		post := models.Post{Content: "купи спам", ThreadID: 1, UserID: 1}
+
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, post).Return(models.Post{ID: 7}, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetAutomod(dryRun)
+		_, err := u.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreateThreadAutomodReplace(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+	mockRepo.On("CreateThread", mock.Anything, mock.MatchedBy(func(th models.Thread) bool {
+		return th.Title == "почему не работает" && th.Content == "сам *** а я нет" && !th.Pending
+	})).Return(models.Thread{ID: 3}, nil).Once()
+
+	u := NewThreadUseCase(mockRepo, zap.NewNop())
+	u.SetAutomod(newTestEngine(t, automod.Config{Rules: []automod.Rule{
+		{Name: "caps", Kind: automod.KindCaps, Action: automod.ActionReplace},
+		{Name: "rude", Kind: automod.KindWords, Action: automod.ActionReplace, Words: []string{"дурак"}},
+	}}))
+	_, err := u.CreateThread(context.Background(), models.Thread{Title: "ПОЧЕМУ НЕ РАБОТАЕТ", Content: "сам дурак а я нет", UserID: 1})
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+}
+
+func TestUpdateAutomodConfig(t *testing.T) {
+	valid := automod.Config{Rules: []automod.Rule{
+		{Name: "caps", Kind: automod.KindCaps, Action: automod.ActionFlag},
+	}}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("AddAuditEntry", mock.Anything, mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.Action == models.AuditAutomodUpdate && e.ActorID == 1 &&
+				string(e.Before) == `{"dry_run":false,"rules":null}` && len(e.After) > len(e.Before)
+		})).Return(errors.New("db error")).Once()
+
+		engine := newTestEngine(t, automod.Config{})
+		u := NewAutomodUseCase(mockRepo, engine, nil, zap.NewNop())
+		result, err := u.UpdateConfig(context.Background(), valid, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, valid, result)
+		assert.Equal(t, valid, engine.Config())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moderator forbidden", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleModerator}, nil).Once()
+
+		engine := newTestEngine(t, automod.Config{})
+		u := NewAutomodUseCase(mockRepo, engine, nil, zap.NewNop())
+		_, err := u.UpdateConfig(context.Background(), valid, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		assert.Empty(t, engine.Config().Rules)
+	})
+
+	t.Run("invalid rule", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+
+		u := NewAutomodUseCase(mockRepo, newTestEngine(t, automod.Config{}), nil, zap.NewNop())
+		_, err := u.UpdateConfig(context.Background(), automod.Config{Rules: []automod.Rule{{Name: "x", Kind: "magic"}}}, 1)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidAutomodRule)
+	})
+}
+
+func TestCreatePostDuplicates(t *testing.T) {
+	detector := dedup.NewDetector(dedup.DefaultConfig())
+	text := "Лучшие ставки на спорт только у нас, переходите по ссылке в профиле"
+	hash, _ := detector.Prepare(text)
+
+	t.Run("own repost rejected", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetUserFingerprints", mock.Anything, 1, hash, mock.Anything).
+			Return([]models.Fingerprint{{ID: 3, UserID: 1, Hash: hash, CreateAt: time.Now()}}, nil).Once()
+		mockRepo.On("GetSimilarFingerprints", mock.Anything, hash, mock.Anything, dedupCandidates).
+			Return([]models.Fingerprint(nil), nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetDedup(detector)
+		_, err := u.CreatePost(context.Background(), models.Post{Content: text, ThreadID: 1, UserID: 1})
+
+		assert.ErrorIs(t, err, models.ErrorDuplicateContent)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("new text fingerprinted", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetUserFingerprints", mock.Anything, 1, hash, mock.Anything).Return([]models.Fingerprint(nil), nil).Once()
+		mockRepo.On("GetSimilarFingerprints", mock.Anything, hash, mock.Anything, dedupCandidates).Return([]models.Fingerprint(nil), nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.Anything).Return(models.Post{ID: 5, ThreadID: 1, UserID: 1}, nil).Once()
+		mockRepo.On("SaveFingerprint", mock.Anything, mock.MatchedBy(func(fp models.Fingerprint) bool {
+			return fp.TargetType == models.TargetPost && fp.TargetID == 5 && fp.Hash == hash
+		})).Return(nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetDedup(detector)
+		_, err := u.CreatePost(context.Background(), models.Post{Content: text, ThreadID: 1, UserID: 1})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("other authors' near copies flagged", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetUserFingerprints", mock.Anything, 1, hash, mock.Anything).Return([]models.Fingerprint(nil), nil).Once()
+		mockRepo.On("GetSimilarFingerprints", mock.Anything, hash, mock.Anything, dedupCandidates).Return([]models.Fingerprint{
+			{ID: 7, UserID: 2, Hash: hash ^ 1, CreateAt: time.Now()},
+			{ID: 8, UserID: 3, Hash: hash, CreateAt: time.Now()},
+		}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.Anything).Return(models.Post{ID: 5, ThreadID: 1, UserID: 1}, nil).Once()
+		mockRepo.On("SaveFingerprint", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+		mockRepo.On("CreateReport", mock.Anything, mock.MatchedBy(func(r models.Report) bool {
+			return r.ReporterID == models.AutomodReporterID && r.TargetID == 5
+		})).Return(models.Report{ID: 1}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetDedup(detector)
+		_, err := u.CreatePost(context.Background(), models.Post{Content: text, ThreadID: 1, UserID: 1})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestGetDuplicates(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+	mockRepo.On("GetFingerprints", mock.Anything, mock.Anything, duplicatesLimit).Return([]models.Fingerprint{
+		{TargetID: 1, UserID: 2, Hash: 42},
+		{TargetID: 2, UserID: 3, Hash: 42},
+		{TargetID: 3, UserID: 4, Hash: ^uint64(42)},
+	}, nil).Once()
+
+	u := NewAutomodUseCase(mockRepo, newTestEngine(t, automod.Config{}), dedup.NewDetector(dedup.DefaultConfig()), zap.NewNop())
+	clusters, err := u.GetDuplicates(context.Background(), 0, 1)
+
+	assert.NoError(t, err)
+	assert.Len(t, clusters, 1)
+	assert.Equal(t, 2, clusters[0].Users)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+type BanUseCase interface {
+	BanUser(ctx context.Context, ban models.Ban, actorID int) (models.Ban, error)
+	GetBans(ctx context.Context, userID, actorID int) ([]models.Ban, error)
+	LiftBan(ctx context.Context, id, actorID int) error
+	ExpireBans(ctx context.Context) (int64, error)
+}
+
+type BUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+}
+
+func NewBanUseCase(repo repository.ForumRepository, logger *zap.Logger) BanUseCase {
+	return &BUseCase{repo: repo, logger: logger}
+}
+
+// checkBan возвращает models.ErrorUserBanned, если пользователю запрещено
+// писать в категории. shadow сообщает о теневой блокировке: писать можно,
+// но посты будут видны только автору.
+func checkBan(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, userID, categoryID int) (shadow bool, err error) {
+	bans, err := repo.GetActiveBans(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, ban := range bans {
+		if !ban.Applies(categoryID) {
+			continue
+		}
+		if !ban.Shadow {
+			logger.From(ctx, log).Warn("Попытка записи заблокированным пользователем",
+				zap.Int("userID", userID),
+				zap.Int("categoryID", categoryID),
+				zap.Int("banID", ban.ID))
+			return false, models.ErrorUserBanned
+		}
+		shadow = true
+	}
+	return shadow, nil
+}
+
+func (f *BUseCase) BanUser(ctx context.Context, ban models.Ban, actorID int) (models.Ban, error) {
+	if ban.UserID <= 0 {
+		return models.Ban{}, models.ErrorInvalidBan.WithField("user_id", "не указан пользователь")
+	}
+	if ban.ExpiresAt != nil && !ban.ExpiresAt.After(time.Now()) {
+		return models.Ban{}, models.ErrorInvalidBan.WithField("expires_at", "срок блокировки уже истек")
+	}
+	if ban.Shadow && ban.CategoryID != 0 {
+		return models.Ban{}, models.ErrorInvalidBan.WithField("category_id", "теневая блокировка действует на всем форуме")
+	}
+	if len(ban.Reason) > 1000 {
+		return models.Ban{}, models.ErrorInvalidBan.WithField("reason", "длиннее 1000 символов")
+	}
+
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.UserBan, authz.Resource{CategoryID: ban.CategoryID}); err != nil {
+		return models.Ban{}, err
+	}
+	if ban.CategoryID != 0 {
+		if _, err := f.repo.GetCategoryByID(ctx, ban.CategoryID); err != nil {
+			return models.Ban{}, err
+		}
+	}
+
+	ban.ModeratorID = actorID
+	return f.repo.CreateBan(ctx, ban, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditUserBan,
+		TargetType: models.TargetUser,
+		TargetID:   ban.UserID,
+		Reason:     ban.Reason,
+		Details:    banDetails(ban),
+		After:      models.Snapshot(ban),
+	})
+}
+
+func banDetails(ban models.Ban) string {
+	details := "на всем форуме"
+	if ban.CategoryID != 0 {
+		details = fmt.Sprintf("в категории #%d", ban.CategoryID)
+	}
+	if ban.Shadow {
+		details = "теневая, " + details
+	}
+	if ban.ExpiresAt != nil {
+		details += ", до " + ban.ExpiresAt.Format(time.RFC3339)
+	} else {
+		details += ", бессрочно"
+	}
+	return details
+}
+
+func (f *BUseCase) GetBans(ctx context.Context, userID, actorID int) ([]models.Ban, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.UserBan, authz.Resource{}); err != nil {
+		return nil, err
+	}
+	return f.repo.GetActiveBans(ctx, userID)
+}
+
+func (f *BUseCase) LiftBan(ctx context.Context, id, actorID int) error {
+	ban, err := f.repo.GetBanByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.UserBan, authz.Resource{CategoryID: ban.CategoryID}); err != nil {
+		return err
+	}
+
+	return f.repo.DeleteBan(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditUserUnban,
+		TargetType: models.TargetUser,
+		TargetID:   ban.UserID,
+		Details:    fmt.Sprintf("снята блокировка #%d", ban.ID),
+		Before:     models.Snapshot(ban),
+	})
+}
+
+func (f *BUseCase) ExpireBans(ctx context.Context) (int64, error) {
+	deleted, err := f.repo.DeleteExpiredBans(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if deleted > 0 {
+		logger.From(ctx, f.logger).Info("Истекшие блокировки удалены", zap.Int64("count", deleted))
+	}
+	return deleted, nil
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestBanUser(t *testing.T) {
+	t.Run("timed ban in category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		expires := time.Now().Add(24 * time.Hour)
+		ban := models.Ban{UserID: 5, CategoryID: 3, Reason: "флуд", ExpiresAt: &expires}
+
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+		mockRepo.On("GetCategoryByID", mock.Anything, 3).Return(models.Category{ID: 3}, nil).Once()
+		mockRepo.On("CreateBan", mock.Anything, mock.MatchedBy(func(b models.Ban) bool {
+			return b.UserID == 5 && b.ModeratorID == 2
+		}), mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.Action == models.AuditUserBan && e.TargetID == 5
+		})).Return(models.Ban{ID: 1, UserID: 5, CategoryID: 3}, nil).Once()
+
+		u := NewBanUseCase(mockRepo, zap.NewNop())
+		created, err := u.BanUser(context.Background(), ban, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, created.ID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("category moderator cannot ban forum-wide", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+
+		u := NewBanUseCase(mockRepo, zap.NewNop())
+		_, err := u.BanUser(context.Background(), models.Ban{UserID: 5}, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNotCalled(t, "CreateBan", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		past := time.Now().Add(-time.Hour)
+
+		u := NewBanUseCase(mockRepo, zap.NewNop())
+		_, err := u.BanUser(context.Background(), models.Ban{UserID: 5, ExpiresAt: &past}, 1)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidBan)
+	})
+
+	t.Run("shadow ban in category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+
+		u := NewBanUseCase(mockRepo, zap.NewNop())
+		_, err := u.BanUser(context.Background(), models.Ban{UserID: 5, CategoryID: 3, Shadow: true}, 1)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidBan)
+	})
+}
+
+func TestLiftBan(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetBanByID", mock.Anything, 7).Return(models.Ban{ID: 7, UserID: 5}, nil).Once()
+	mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleModerator}, nil).Once()
+	mockRepo.On("DeleteBan", mock.Anything, 7, mock.MatchedBy(func(e models.AuditEntry) bool {
+		return e.Action == models.AuditUserUnban && e.TargetID == 5
+	})).Return(nil).Once()
+
+	u := NewBanUseCase(mockRepo, zap.NewNop())
+	err := u.LiftBan(context.Background(), 7, 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/metrics"
+	"github.com/fire9900/forum/internal/models"
+)
+
+// instrumentedPostUseCase считает созданные посты. Остальные методы
+// достаются от встроенного PostUseCase без изменений.
+type instrumentedPostUseCase struct {
+	PostUseCase
+	metrics *metrics.Metrics
+}
+
+// InstrumentPostUseCase оборачивает p так, что успешно созданные посты
+// попадают в метрику forum_posts_created_total.
+func InstrumentPostUseCase(p PostUseCase, m *metrics.Metrics) PostUseCase {
+	return &instrumentedPostUseCase{PostUseCase: p, metrics: m}
+}
+
+func (u *instrumentedPostUseCase) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	created, err := u.PostUseCase.CreatePost(ctx, post)
+	if err == nil {
+		u.metrics.PostCreated(created.Hidden)
+	}
+	return created, err
+}
+
+// instrumentedThreadUseCase считает созданные треды.
+type instrumentedThreadUseCase struct {
+	ThreadUseCase
+	metrics *metrics.Metrics
+}
+
+// InstrumentThreadUseCase оборачивает t так, что успешно созданные треды
+// попадают в метрику forum_threads_created_total.
+func InstrumentThreadUseCase(t ThreadUseCase, m *metrics.Metrics) ThreadUseCase {
+	return &instrumentedThreadUseCase{ThreadUseCase: t, metrics: m}
+}
+
+func (u *instrumentedThreadUseCase) CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	created, err := u.ThreadUseCase.CreateThread(ctx, thread)
+	if err == nil {
+		u.metrics.ThreadCreated()
+	}
+	return created, err
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ModerationUseCase interface {
+	GetCategories(ctx context.Context) ([]models.Category, error)
+	CreateCategory(ctx context.Context, category models.Category, actorID int) (models.Category, error)
+	AddCategoryModerator(ctx context.Context, categoryID, userID, actorID int) error
+	RemoveCategoryModerator(ctx context.Context, categoryID, userID, actorID int) error
+	MergeThreads(ctx context.Context, fromID, toID, actorID int) (models.Thread, error)
+	SplitThread(ctx context.Context, req models.SplitRequest, actorID int) (models.Thread, error)
+	MoveThread(ctx context.Context, threadID, categoryID, actorID int) (models.Thread, error)
+}
+
+type MUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+}
+
+func NewModerationUseCase(repo repository.ForumRepository, logger *zap.Logger) ModerationUseCase {
+	return &MUseCase{repo: repo, logger: logger}
+}
+
+func (f *MUseCase) GetCategories(ctx context.Context) ([]models.Category, error) {
+	return f.repo.GetAllCategories(ctx)
+}
+
+func (f *MUseCase) CreateCategory(ctx context.Context, category models.Category, actorID int) (models.Category, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.CategoryManage, authz.Resource{}); err != nil {
+		return models.Category{}, err
+	}
+	if category.Name == "" || len(category.Name) > 100 {
+		return models.Category{}, models.ErrorInvalidCategory.WithField("name", "пустое или длиннее 100 символов")
+	}
+	return f.repo.CreateCategory(ctx, category, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditCategoryCreate,
+		TargetType: models.TargetCategory,
+	})
+}
+
+func (f *MUseCase) AddCategoryModerator(ctx context.Context, categoryID, userID, actorID int) error {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.CategoryManage, authz.Resource{}); err != nil {
+		return err
+	}
+	category, err := f.repo.GetCategoryByID(ctx, categoryID)
+	if err != nil {
+		return err
+	}
+	return f.repo.AddCategoryModerator(ctx, categoryID, userID, moderatorEntry(models.AuditModeratorAdd, category.ID, userID, actorID))
+}
+
+func (f *MUseCase) RemoveCategoryModerator(ctx context.Context, categoryID, userID, actorID int) error {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.CategoryManage, authz.Resource{}); err != nil {
+		return err
+	}
+	return f.repo.RemoveCategoryModerator(ctx, categoryID, userID, moderatorEntry(models.AuditModeratorRemove, categoryID, userID, actorID))
+}
+
+func moderatorEntry(action string, categoryID, userID, actorID int) models.AuditEntry {
+	return models.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: models.TargetUser,
+		TargetID:   userID,
+		Details:    fmt.Sprintf("категория #%d", categoryID),
+	}
+}
+
+func (f *MUseCase) MergeThreads(ctx context.Context, fromID, toID, actorID int) (models.Thread, error) {
+	logger.From(ctx, f.logger).Info("Объединение тредов",
+		zap.Int("fromID", fromID),
+		zap.Int("toID", toID),
+		zap.Int("actorID", actorID))
+
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ThreadMerge, authz.Resource{}); err != nil {
+		return models.Thread{}, err
+	}
+	if fromID == toID {
+		return models.Thread{}, models.ErrorSameThread
+	}
+
+	if err := f.repo.MergeThreads(ctx, fromID, toID, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadMerge,
+		TargetType: models.TargetThread,
+		TargetID:   toID,
+		Details:    fmt.Sprintf("тред #%d объединен с тредом #%d", fromID, toID),
+	}); err != nil {
+		return models.Thread{}, err
+	}
+
+	return f.repo.GetThreadByID(ctx, toID)
+}
+
+func (f *MUseCase) SplitThread(ctx context.Context, req models.SplitRequest, actorID int) (models.Thread, error) {
+	logger.From(ctx, f.logger).Info("Разделение треда",
+		zap.Int("threadID", req.ThreadID),
+		zap.Int("fromPostID", req.FromPostID),
+		zap.Int("toPostID", req.ToPostID),
+		zap.Int("actorID", actorID))
+
+	if req.FromPostID <= 0 || req.ToPostID < req.FromPostID {
+		return models.Thread{}, models.ErrorInvalidPostRange
+	}
+
+	source, err := f.repo.GetThreadByID(ctx, req.ThreadID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ThreadSplit, authz.Resource{CategoryID: source.CategoryID}); err != nil {
+		return models.Thread{}, err
+	}
+
+	thread := models.Thread{
+		Title:      req.Title,
+		Content:    req.Content,
+		UserID:     actorID,
+		CategoryID: source.CategoryID,
+	}
+	if thread.Content == "" {
+		thread.Content = fmt.Sprintf("Выделено из треда #%d", source.ID)
+	}
+	if err := validateThread(ctx, f.logger, thread); err != nil {
+		return models.Thread{}, err
+	}
+
+	return f.repo.SplitThread(ctx, req, thread, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadSplit,
+		TargetType: models.TargetThread,
+		Details: fmt.Sprintf("посты #%d-#%d выделены из треда #%d",
+			req.FromPostID, req.ToPostID, source.ID),
+	})
+}
+
+func (f *MUseCase) MoveThread(ctx context.Context, threadID, categoryID, actorID int) (models.Thread, error) {
+	logger.From(ctx, f.logger).Info("Перенос треда",
+		zap.Int("threadID", threadID),
+		zap.Int("categoryID", categoryID),
+		zap.Int("actorID", actorID))
+
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ThreadMove, authz.Resource{}); err != nil {
+		return models.Thread{}, err
+	}
+
+	thread, err := f.repo.GetThreadByID(ctx, threadID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if err := f.repo.MoveThread(ctx, threadID, categoryID, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadMove,
+		TargetType: models.TargetThread,
+		TargetID:   threadID,
+		Details: fmt.Sprintf("категория #%d -> #%d",
+			thread.CategoryID, categoryID),
+	}); err != nil {
+		return models.Thread{}, err
+	}
+
+	thread.CategoryID = categoryID
+	return thread, nil
+}
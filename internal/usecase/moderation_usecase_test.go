@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestMergeThreads(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		target := models.Thread{ID: 2, Title: "Target"}
+
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("MergeThreads", mock.Anything, 3, 2, mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.ActorID == 1 && e.Action == models.AuditThreadMerge && e.TargetID == 2
+		})).Return(nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(target, nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		result, err := u.MergeThreads(context.Background(), 3, 2, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, target, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("not admin", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser}, nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		_, err := u.MergeThreads(context.Background(), 3, 2, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNotCalled(t, "MergeThreads", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("same thread", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		_, err := u.MergeThreads(context.Background(), 2, 2, 1)
+
+		assert.ErrorIs(t, err, models.ErrorSameThread)
+	})
+}
+
+func TestSplitThread(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		req := models.SplitRequest{ThreadID: 1, FromPostID: 10, ToPostID: 12, Title: "Offtopic"}
+		created := models.Thread{ID: 5, Title: "Offtopic", CategoryID: 4}
+
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, CategoryID: 4}, nil).Once()
+		mockRepo.On("SplitThread", mock.Anything, req, mock.MatchedBy(func(th models.Thread) bool {
+			return th.CategoryID == 4 && th.UserID == 1 && th.Content != ""
+		}), mock.Anything).Return(created, nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		result, err := u.SplitThread(context.Background(), req, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, created, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		_, err := u.SplitThread(context.Background(), models.SplitRequest{ThreadID: 1, FromPostID: 12, ToPostID: 10, Title: "T"}, 1)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidPostRange)
+		mockRepo.AssertNotCalled(t, "SplitThread", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("moderator of another category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, CategoryID: 4}, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		_, err := u.SplitThread(context.Background(), models.SplitRequest{ThreadID: 1, FromPostID: 10, ToPostID: 12, Title: "T"}, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestMoveThread(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	thread := models.Thread{ID: 1, CategoryID: 1}
+
+	t.Run("success", func(t *testing.T) {
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("MoveThread", mock.Anything, 1, 2, mock.Anything).Return(nil).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		result, err := u.MoveThread(context.Background(), 1, 2, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, result.CategoryID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("category not found", func(t *testing.T) {
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("MoveThread", mock.Anything, 1, 9, mock.Anything).Return(models.ErrorNotFoundCategory).Once()
+
+		u := NewModerationUseCase(mockRepo, zap.NewNop())
+		_, err := u.MoveThread(context.Background(), 1, 9, 1)
+
+		assert.True(t, errors.Is(err, models.ErrorNotFoundCategory))
+		mockRepo.AssertExpectations(t)
+	})
+}
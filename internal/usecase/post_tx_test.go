@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// newSQLPostUseCase создает PostUseCase поверх настоящего репозитория
+// с заглушкой базы, чтобы проверять транзакции CreatePost на уровне SQL.
+func newSQLPostUseCase(t *testing.T) (*PUseCase, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("ошибка '%s' не ожидалась при открытии заглушки подключения к базе данных", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewPostUseCase(repository.NewForumRepository(db, zap.NewNop()), zap.NewNop()), mock
+}
+
+// expectPostChecks ожидает чтения треда 2 и блокировок автора перед созданием поста.
+func expectPostChecks(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery("FROM threads WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "title", "content", "create_at", "user_id", "pinned", "locked", "archived", "category_id", "slow_mode", "pending", "deleted_at", "deleted_by"}).
+			AddRow(2, "Тред", "Текст", time.Now(), 1, false, false, false, 0, 0, false, nil, 0))
+	mock.ExpectQuery("FROM bans").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+}
+
+func TestCreatePostTransaction(t *testing.T) {
+	post := models.Post{Content: "Привет", ThreadID: 2, UserID: 5}
+	postRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "content", "create_at", "thread_id", "user_id", "pending", "deleted_at", "deleted_by"}).
+			AddRow(7, post.Content, time.Now(), 2, 5, false, nil, 0)
+	}
+
+	t.Run("commit", func(t *testing.T) {
+		u, mock := newSQLPostUseCase(t)
+		expectPostChecks(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO posts").WillReturnRows(postRow())
+		mock.ExpectExec("INSERT INTO chat").
+			WithArgs(2, 5, 7).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		created, err := u.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 7, created.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("chat failure rolls back post", func(t *testing.T) {
+		u, mock := newSQLPostUseCase(t)
+		failure := errors.New("disk I/O error")
+		expectPostChecks(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO posts").WillReturnRows(postRow())
+		mock.ExpectExec("INSERT INTO chat").
+			WithArgs(2, 5, 7).
+			WillReturnError(failure)
+		mock.ExpectRollback()
+
+		_, err := u.CreatePost(context.Background(), post)
+
+		assert.ErrorIs(t, err, failure)
+		assert.NoError(t, mock.ExpectationsWereMet(), "пост должен откатиться вместе с чатом")
+	})
+
+	t.Run("post failure skips chat", func(t *testing.T) {
+		u, mock := newSQLPostUseCase(t)
+		failure := errors.New("database is locked")
+		expectPostChecks(mock)
+		mock.ExpectBegin()
+		mock.ExpectQuery("INSERT INTO posts").WillReturnError(failure)
+		mock.ExpectRollback()
+
+		_, err := u.CreatePost(context.Background(), post)
+
+		assert.ErrorIs(t, err, failure)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
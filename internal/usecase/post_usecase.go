@@ -1,101 +1,250 @@
 package usecase
 
 import (
+	"context"
 	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/dedup"
 	entity "github.com/fire9900/forum/internal/models"
 	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/spam"
 	"github.com/fire9900/forum/pkg/logger"
 	"go.uber.org/zap"
+	"time"
 )
 
 type PostUseCase interface {
-	CreatePost(post entity.Post) (entity.Post, error)
-	GetChatPosts(threadID int) ([]entity.Post, error)
-	GetPostByThreadID(threadID int) ([]entity.Post, error)
-	DeletePostByID(id int, userID int) error
-	CheckUserByID(any entity.User, id int) (bool, error)
-	GetPostsByUserID(id int) ([]entity.Post, error)
+	CreatePost(ctx context.Context, post entity.Post) (entity.Post, error)
+	GetChatPosts(ctx context.Context, threadID, viewerID int) ([]entity.Post, error)
+	GetPostByThreadID(ctx context.Context, threadID, viewerID int) ([]entity.Post, error)
+	DeletePostByID(ctx context.Context, id int, userID int) error
+	GetPostsByUserID(ctx context.Context, id, viewerID int) ([]entity.Post, error)
 }
 
 type PUseCase struct {
-	repo repository.ForumRepository
+	repo           repository.ForumRepository
+	logger         *zap.Logger
+	automod        *automod.Engine
+	dedup          *dedup.Detector
+	spam           *spam.Classifier
+	trustThreshold int
 }
 
-func NewPostUseCase(repo repository.ForumRepository) *PUseCase {
-	return &PUseCase{repo: repo}
+func NewPostUseCase(repo repository.ForumRepository, logger *zap.Logger) *PUseCase {
+	return &PUseCase{repo: repo, logger: logger}
 }
 
-func (f *PUseCase) CreatePost(post entity.Post) (entity.Post, error) {
+// SetAutomod включает проверку новых постов правилами автомодерации.
+func (f *PUseCase) SetAutomod(engine *automod.Engine) {
+	f.automod = engine
+}
+
+// SetDedup включает поиск повторов среди новых постов.
+func (f *PUseCase) SetDedup(detector *dedup.Detector) {
+	f.dedup = detector
+}
+
+// SetSpamClassifier включает оценку новых постов классификатором спама.
+func (f *PUseCase) SetSpamClassifier(classifier *spam.Classifier) {
+	f.spam = classifier
+}
+
+// SetTrustThreshold включает премодерацию постов пользователей, у которых
+// меньше threshold опубликованных сообщений.
+func (f *PUseCase) SetTrustThreshold(threshold int) {
+	f.trustThreshold = threshold
+}
+
+func (f *PUseCase) CreatePost(ctx context.Context, post entity.Post) (entity.Post, error) {
 	if post.Content == "" || len(post.Content) > 5000 {
-		err := fmt.Errorf("Недопустимый размер описания! Описание == 0 || > 5000")
-		logger.Logger.Error("Невалидное содержание поста",
+		err := entity.ErrorInvalidPost.WithField("content", "пустое или длиннее 5000 символов")
+		logger.From(ctx, f.logger).Error("Невалидное содержание поста",
 			zap.Error(err),
 			zap.Int("contentLength", len(post.Content)))
 		return entity.Post{}, err
 	}
 
-	createdPost, err := f.repo.CreatePost(post)
+	thread, err := f.repo.GetThreadByID(ctx, post.ThreadID)
 	if err != nil {
 		return entity.Post{}, err
 	}
+	if err := thread.CanReply(); err != nil {
+		logger.From(ctx, f.logger).Warn("Попытка написать в закрытый тред",
+			zap.Int("threadID", post.ThreadID),
+			zap.Int("userID", post.UserID),
+			zap.Error(err))
+		return entity.Post{}, err
+	}
+	// Тред на проверке не виден никому, кроме автора.
+	if thread.Pending && thread.UserID != post.UserID {
+		return entity.Post{}, entity.ErrorNotFoundThread
+	}
 
-	if err := f.repo.LinkPostToChat(entity.Chat{
-		post.ThreadID,
-		post.UserID,
-		createdPost.ID,
-	}); err != nil {
-		return entity.Post{}, fmt.Errorf("Ошибка создания поста в чат: %w", err)
+	shadow, err := checkBan(ctx, f.logger, f.repo, post.UserID, thread.CategoryID)
+	if err != nil {
+		return entity.Post{}, err
+	}
+	if err := f.checkSlowMode(ctx, thread, post); err != nil {
+		return entity.Post{}, err
+	}
+	action, matches, err := moderate(ctx, f.logger, f.repo, f.automod, post.UserID, &post.Content)
+	if err != nil {
+		return entity.Post{}, err
+	}
+	dup, err := findDuplicates(ctx, f.logger, f.repo, f.dedup, post.UserID, post.Content)
+	if err != nil {
+		return entity.Post{}, err
+	}
+	action = automod.Stronger(action, dup.Action)
+	matches = append(matches, dup.Matches...)
+	verdict := scoreSpam(ctx, f.logger, f.spam, post.UserID, post.Content)
+	action = automod.Stronger(action, verdict.Action)
+	matches = append(matches, verdict.Matches...)
+	post.Pending = action == automod.ActionHold
+	if !post.Pending {
+		post.Pending, err = requiresApproval(ctx, f.logger, f.repo, f.trustThreshold, post.UserID, thread.CategoryID)
+		if err != nil {
+			return entity.Post{}, err
+		}
+	}
+
+	// Пост без строки чата не виден в чате треда, поэтому они создаются
+	// одной транзакцией.
+	var createdPost entity.Post
+	err = f.repo.WithTx(ctx, func(repo repository.ForumRepository) error {
+		createdPost, err = repo.CreatePost(ctx, post)
+		if err != nil {
+			return err
+		}
+		if err := repo.LinkPostToChat(ctx, entity.Chat{
+			ThreadID: post.ThreadID,
+			UserID:   post.UserID,
+			PostID:   createdPost.ID,
+		}); err != nil {
+			return fmt.Errorf("Ошибка создания поста в чат: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return entity.Post{}, err
+	}
+
+	createdPost.Hidden = shadow || createdPost.Pending
+	saveFingerprint(ctx, f.logger, f.repo, dup, entity.TargetPost, createdPost.ID, post.UserID)
+	if verdict.Scored {
+		saveSpamScore(ctx, f.logger, f.repo, createdPost.ID, verdict.Score)
 	}
+	reportAutomod(ctx, f.logger, f.repo, action, matches, entity.TargetPost, createdPost.ID)
 	return createdPost, nil
 }
 
-func (f *PUseCase) GetChatPosts(threadID int) ([]entity.Post, error) {
-	return f.repo.GetChatPosts(threadID)
+// checkSlowMode проверяет, что с последнего поста автора в треде прошло
+// не меньше thread.SlowMode секунд. Модераторы треда ограничению не подлежат.
+func (f *PUseCase) checkSlowMode(ctx context.Context, thread entity.Thread, post entity.Post) error {
+	if thread.SlowMode <= 0 {
+		return nil
+	}
+
+	last, err := f.repo.GetLastPostTime(ctx, post.ThreadID, post.UserID)
+	if err != nil {
+		return err
+	}
+	if last.IsZero() {
+		return nil
+	}
+	wait := time.Until(last.Add(time.Duration(thread.SlowMode) * time.Second))
+	if wait <= 0 {
+		return nil
+	}
+
+	actor, err := f.repo.GetActor(ctx, post.UserID)
+	if err == nil && authz.Authorize(actor, authz.ThreadLock, authz.Resource{CategoryID: thread.CategoryID}) == nil {
+		return nil
+	}
+
+	logger.From(ctx, f.logger).Warn("Пост отклонен медленным режимом",
+		zap.Int("threadID", post.ThreadID),
+		zap.Int("userID", post.UserID),
+		zap.Duration("wait", wait))
+	return &entity.SlowModeError{Wait: wait}
 }
 
-func (f *PUseCase) GetPostByThreadID(threadID int) ([]entity.Post, error) {
-	logger.Logger.Debug("Получение постов по ID треда", zap.Int("threadID", threadID))
-	posts, err := f.repo.GetPostsByThreadID(threadID)
+// threadViewer возвращает читателя треда threadID. Посты и чат треда,
+// скрытого от читателя (на проверке или под теневой блокировкой),
+// не отдаются: возвращается ErrorNotFoundThread.
+func (f *PUseCase) threadViewer(ctx context.Context, threadID, viewerID int) (entity.Viewer, error) {
+	viewer := viewerFor(ctx, f.repo, viewerID, threadID)
+	if _, err := f.repo.GetThreadForViewer(ctx, threadID, viewer); err != nil {
+		return entity.Viewer{}, err
+	}
+	return viewer, nil
+}
+
+func (f *PUseCase) GetChatPosts(ctx context.Context, threadID, viewerID int) ([]entity.Post, error) {
+	viewer, err := f.threadViewer(ctx, threadID, viewerID)
 	if err != nil {
-		logger.Logger.Error("Ошибка при получении постов треда",
+		return nil, err
+	}
+	return f.repo.GetChatPosts(ctx, threadID, viewer)
+}
+
+func (f *PUseCase) GetPostByThreadID(ctx context.Context, threadID, viewerID int) ([]entity.Post, error) {
+	logger.From(ctx, f.logger).Debug("Получение постов по ID треда", zap.Int("threadID", threadID))
+	viewer, err := f.threadViewer(ctx, threadID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	posts, err := f.repo.GetPostsByThreadID(ctx, threadID, viewer)
+	if err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка при получении постов треда",
 			zap.Int("threadID", threadID),
 			zap.Error(err))
 		return nil, err
 	}
-	logger.Logger.Debug("Посты треда успешно получены",
+	logger.From(ctx, f.logger).Debug("Посты треда успешно получены",
 		zap.Int("threadID", threadID),
 		zap.Int("count", len(posts)))
 	return posts, nil
 }
 
-func (f *PUseCase) DeletePostByID(id int, userID int) error {
-	logger.Logger.Info("Удаление поста", zap.Int("id", id))
+func (f *PUseCase) DeletePostByID(ctx context.Context, id int, userID int) error {
+	logger.From(ctx, f.logger).Info("Удаление поста", zap.Int("id", id))
+
+	post, err := f.repo.GetPostByID(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	post, err := f.repo.GetPostByID(id)
+	thread, err := f.repo.GetThreadByID(ctx, post.ThreadID)
 	if err != nil {
 		return err
 	}
 
-	valid, err := f.repo.CheckUserByID(post, userID)
-	if !valid || err != nil {
-		return fmt.Errorf("Нет прав: %w", err)
+	if err := authorize(ctx, f.logger, f.repo, userID, authz.PostDeleteAny, authz.Resource{
+		OwnerID:    post.UserID,
+		CategoryID: thread.CategoryID,
+	}); err != nil {
+		return err
 	}
 
-	err = f.repo.DeletePostByID(id)
+	err = f.repo.DeletePostByID(ctx, id, entity.AuditEntry{
+		ActorID:    userID,
+		Action:     entity.AuditPostDelete,
+		TargetType: entity.TargetPost,
+		TargetID:   id,
+		Before:     entity.Snapshot(post),
+	})
 	if err != nil {
-		logger.Logger.Error("Ошибка при удалении поста",
+		logger.From(ctx, f.logger).Error("Ошибка при удалении поста",
 			zap.Int("id", id),
 			zap.Error(err))
 		return err
 	}
-	logger.Logger.Info("Пост успешно удален", zap.Int("id", id))
+	logger.From(ctx, f.logger).Info("Пост успешно удален", zap.Int("id", id))
 	return nil
 }
 
-func (f *PUseCase) CheckUserByID(any entity.User, id int) (bool, error) {
-	return f.repo.CheckUserByID(any, id)
-}
-
-func (f *PUseCase) GetPostsByUserID(id int) ([]entity.Post, error) {
-	return f.repo.GetPostsByUserID(id)
+func (f *PUseCase) GetPostsByUserID(ctx context.Context, id, viewerID int) ([]entity.Post, error) {
+	return f.repo.GetPostsByUserID(ctx, id, viewerFor(ctx, f.repo, viewerID, 0))
 }
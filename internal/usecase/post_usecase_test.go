@@ -1,20 +1,18 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"github.com/fire9900/forum/internal/models"
-	"github.com/fire9900/forum/pkg/logger"
 	"github.com/fire9900/forum/pkg/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
+	"strings"
 	"testing"
+	"time"
 )
 
-func init() {
-	logger.Logger = zap.NewNop()
-}
-
 func TestGetAllThreads(t *testing.T) {
 	mockRepo := new(mocks.ForumRepository)
 	mockThreads := []models.Thread{
@@ -23,10 +21,10 @@ func TestGetAllThreads(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetAllThreads").Return(mockThreads, nil).Once()
+		mockRepo.On("GetAllThreads", mock.Anything, models.Viewer{}).Return(mockThreads, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		threads, err := u.GetAllThreads()
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		threads, err := u.GetAllThreads(context.Background(), 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockThreads, threads)
@@ -39,10 +37,10 @@ func TestGetThreadByID(t *testing.T) {
 	mockThread := models.Thread{ID: 1, Title: "Test Thread"}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetThreadByID", 1).Return(mockThread, nil).Once()
+		mockRepo.On("GetThreadForViewer", mock.Anything, 1, models.Viewer{}).Return(mockThread, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		thread, err := u.GetThreadByID(1)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		thread, err := u.GetThreadByID(context.Background(), 1, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockThread, thread)
@@ -50,15 +48,27 @@ func TestGetThreadByID(t *testing.T) {
 	})
 
 	t.Run("error", func(t *testing.T) {
-		mockRepo.On("GetThreadByID", 2).Return(models.Thread{}, errors.New("error")).Once()
+		mockRepo.On("GetThreadForViewer", mock.Anything, 2, models.Viewer{}).Return(models.Thread{}, errors.New("error")).Once()
 
-		u := NewPostUseCase(mockRepo)
-		thread, err := u.GetThreadByID(2)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		thread, err := u.GetThreadByID(context.Background(), 2, 0)
 
 		assert.Error(t, err)
 		assert.Equal(t, models.Thread{}, thread)
 		mockRepo.AssertExpectations(t)
 	})
+	t.Run("merged thread redirect", func(t *testing.T) {
+		mockRepo.On("GetThreadForViewer", mock.Anything, 3, models.Viewer{}).Return(models.Thread{}, models.ErrorNotFoundThread).Once()
+		mockRepo.On("GetThreadRedirect", mock.Anything, 3).Return(1, nil).Once()
+		mockRepo.On("GetThreadForViewer", mock.Anything, 1, models.Viewer{}).Return(mockThread, nil).Once()
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		thread, err := u.GetThreadByID(context.Background(), 3, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, mockThread, thread)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 func TestCreateThread(t *testing.T) {
@@ -76,10 +86,11 @@ func TestCreateThread(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("CreateThread", validThread).Return(createdThread, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CreateThread", mock.Anything, validThread).Return(createdThread, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		result, err := u.CreateThread(validThread)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		result, err := u.CreateThread(context.Background(), validThread)
 
 		assert.NoError(t, err)
 		assert.Equal(t, createdThread, result)
@@ -93,11 +104,15 @@ func TestCreateThread(t *testing.T) {
 			UserID:  1,
 		}
 
-		u := NewPostUseCase(mockRepo)
-		_, err := u.CreateThread(invalidThread)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreateThread(context.Background(), invalidThread)
 
-		assert.Error(t, err)
-		mockRepo.AssertNotCalled(t, "CreateThread")
+		assert.ErrorIs(t, err, models.ErrorInvalidThread)
+		var domain *models.Error
+		if assert.ErrorAs(t, err, &domain) {
+			assert.Contains(t, domain.Fields, "title")
+		}
+		mockRepo.AssertNumberOfCalls(t, "CreateThread", 1)
 	})
 
 	t.Run("empty content", func(t *testing.T) {
@@ -107,11 +122,15 @@ func TestCreateThread(t *testing.T) {
 			UserID:  1,
 		}
 
-		u := NewPostUseCase(mockRepo)
-		_, err := u.CreateThread(invalidThread)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreateThread(context.Background(), invalidThread)
 
-		assert.Error(t, err)
-		mockRepo.AssertNotCalled(t, "CreateThread")
+		assert.ErrorIs(t, err, models.ErrorInvalidThread)
+		var domain *models.Error
+		if assert.ErrorAs(t, err, &domain) {
+			assert.Contains(t, domain.Fields, "content")
+		}
+		mockRepo.AssertNumberOfCalls(t, "CreateThread", 1)
 	})
 }
 
@@ -120,27 +139,27 @@ func TestDeleteThreadByID(t *testing.T) {
 	thread := models.Thread{ID: 1, UserID: 1}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetThreadByID", 1).Return(thread, nil).Once()
-		mockRepo.On("CheckUserByID", mock.Anything, 1).Return(true, nil).Once()
-		mockRepo.On("DeleteThreadByID", 1).Return(nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("DeleteThreadByID", mock.Anything, 1, mock.Anything).Return(nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.DeleteThreadByID(1, 1)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		err := u.DeleteThreadByID(context.Background(), 1, 1)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("no permissions", func(t *testing.T) {
-		mockRepo.On("GetThreadByID", 1).Return(thread, nil).Once()
-		mockRepo.On("CheckUserByID", mock.Anything, 2).Return(false, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser}, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.DeleteThreadByID(1, 2)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		err := u.DeleteThreadByID(context.Background(), 1, 2)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, models.ErrorForbidden)
 		mockRepo.AssertExpectations(t)
-		mockRepo.AssertNotCalled(t, "DeleteThreadByID")
+		mockRepo.AssertNumberOfCalls(t, "DeleteThreadByID", 1)
 	})
 }
 
@@ -159,17 +178,123 @@ func TestCreatePost(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("CreatePost", validPost).Return(createdPost, nil).Once()
-		mockRepo.On("LinkPostToChat", mock.Anything).Return(nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		result, err := u.CreatePost(validPost)
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		result, err := u.CreatePost(context.Background(), validPost)
 
 		assert.NoError(t, err)
 		assert.Equal(t, createdPost, result)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("chat link failure", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(errors.New("disk I/O error")).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.Error(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("banned in category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, CategoryID: 4}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban{{ID: 9, UserID: 1, CategoryID: 4}}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.ErrorIs(t, err, models.ErrorUserBanned)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("banned in other category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, CategoryID: 5}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban{{ID: 9, UserID: 1, CategoryID: 4}}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		result, err := u.CreatePost(context.Background(), validPost)
+
+		assert.NoError(t, err)
+		assert.False(t, result.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("shadowbanned", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban{{ID: 9, UserID: 1, Shadow: true}}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		result, err := u.CreatePost(context.Background(), validPost)
+
+		assert.NoError(t, err)
+		assert.True(t, result.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("slow mode", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, SlowMode: 60}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetLastPostTime", mock.Anything, 1, 1).Return(time.Now().Add(-10*time.Second), nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		var slow *models.SlowModeError
+		assert.ErrorIs(t, err, models.ErrorSlowMode)
+		assert.True(t, errors.As(err, &slow))
+		assert.InDelta(t, 50, slow.RetryAfterSeconds(), 1)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("slow mode interval passed", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, SlowMode: 60}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetLastPostTime", mock.Anything, 1, 1).Return(time.Now().Add(-2*time.Minute), nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("slow mode skips category moderator", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, CategoryID: 4, SlowMode: 60}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("GetLastPostTime", mock.Anything, 1, 1).Return(time.Now(), nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser, Categories: []int{4}}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, validPost).Return(createdPost, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
 	t.Run("empty content", func(t *testing.T) {
 		invalidPost := models.Post{
 			Content:  "",
@@ -177,11 +302,35 @@ func TestCreatePost(t *testing.T) {
 			UserID:   1,
 		}
 
-		u := NewPostUseCase(mockRepo)
-		_, err := u.CreatePost(invalidPost)
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), invalidPost)
 
-		assert.Error(t, err)
-		mockRepo.AssertNotCalled(t, "CreatePost")
+		assert.ErrorIs(t, err, models.ErrorInvalidPost)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything)
+	})
+
+	t.Run("locked thread", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, Locked: true}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.ErrorIs(t, err, models.ErrorThreadLocked)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("archived thread", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1, Archived: true}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), validPost)
+
+		assert.ErrorIs(t, err, models.ErrorThreadArchived)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
 	})
 }
 
@@ -193,43 +342,98 @@ func TestGetChatPosts(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetChatPosts", 1).Return(mockPosts, nil).Once()
+		mockRepo.On("GetThreadForViewer", mock.Anything, 1, models.Viewer{}).Return(models.Thread{ID: 1}, nil).Once()
+		mockRepo.On("GetChatPosts", mock.Anything, 1, models.Viewer{}).Return(mockPosts, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		posts, err := u.GetChatPosts(1)
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		posts, err := u.GetChatPosts(context.Background(), 1, 0)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockPosts, posts)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("hidden thread", func(t *testing.T) {
+		mockRepo.On("GetThreadForViewer", mock.Anything, 2, models.Viewer{}).Return(models.Thread{}, models.ErrorNotFoundThread).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.GetChatPosts(context.Background(), 2, 0)
+
+		assert.ErrorIs(t, err, models.ErrorNotFoundThread)
+		mockRepo.AssertNotCalled(t, "GetChatPosts", mock.Anything, 2, mock.Anything)
+	})
+}
+
+func TestGetPostByThreadID(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	author := models.Viewer{ID: 5}
+
+	t.Run("author sees pending thread", func(t *testing.T) {
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("GetThreadForViewer", mock.Anything, 1, author).Return(models.Thread{ID: 1, UserID: 5, Pending: true}, nil).Once()
+		mockRepo.On("GetPostsByThreadID", mock.Anything, 1, author).Return([]models.Post{{ID: 1}}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		posts, err := u.GetPostByThreadID(context.Background(), 1, 5)
+
+		assert.NoError(t, err)
+		assert.Len(t, posts, 1)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("pending thread hidden from others", func(t *testing.T) {
+		mockRepo.On("GetThreadForViewer", mock.Anything, 1, models.Viewer{}).Return(models.Thread{}, models.ErrorNotFoundThread).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.GetPostByThreadID(context.Background(), 1, 0)
+
+		assert.ErrorIs(t, err, models.ErrorNotFoundThread)
+		mockRepo.AssertNotCalled(t, "GetPostsByThreadID", mock.Anything, 1, models.Viewer{})
+	})
 }
 
 func TestDeletePostByID(t *testing.T) {
 	mockRepo := new(mocks.ForumRepository)
-	post := models.Post{ID: 1, UserID: 1}
+	post := models.Post{ID: 1, UserID: 1, ThreadID: 3}
+	thread := models.Thread{ID: 3, UserID: 5, CategoryID: 7}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetPostByID", 1).Return(post, nil).Once()
-		mockRepo.On("CheckUserByID", mock.Anything, 1).Return(true, nil).Once()
-		mockRepo.On("DeletePostByID", 1).Return(nil).Once()
+		mockRepo.On("GetPostByID", mock.Anything, 1).Return(post, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 3).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("DeletePostByID", mock.Anything, 1, mock.Anything).Return(nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.DeletePostByID(1, 1)
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		err := u.DeletePostByID(context.Background(), 1, 1)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("no permissions", func(t *testing.T) {
-		mockRepo.On("GetPostByID", 1).Return(post, nil).Once()
-		mockRepo.On("CheckUserByID", mock.Anything, 2).Return(false, nil).Once()
+		mockRepo.On("GetPostByID", mock.Anything, 1).Return(post, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 3).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser}, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.DeletePostByID(1, 2)
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		err := u.DeletePostByID(context.Background(), 1, 2)
 
-		assert.Error(t, err)
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertExpectations(t)
+		mockRepo.AssertNumberOfCalls(t, "DeletePostByID", 1)
+	})
+
+	t.Run("category moderator", func(t *testing.T) {
+		mockRepo.On("GetPostByID", mock.Anything, 1).Return(post, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 3).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 4).Return(models.Actor{ID: 4, Role: models.RoleUser, Categories: []int{7}}, nil).Once()
+		mockRepo.On("DeletePostByID", mock.Anything, 1, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		err := u.DeletePostByID(context.Background(), 1, 4)
+
+		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
-		mockRepo.AssertNotCalled(t, "DeletePostByID")
 	})
 }
 
@@ -241,10 +445,11 @@ func TestGetUserThreads(t *testing.T) {
 	}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("GetThreadsByUserID", 1).Return(mockThreads, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("GetThreadsByUserID", mock.Anything, 1, models.Viewer{ID: 1}).Return(mockThreads, nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		threads, err := u.GetUserThreads(1)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		threads, err := u.GetUserThreads(context.Background(), 1, 1)
 
 		assert.NoError(t, err)
 		assert.Equal(t, mockThreads, threads)
@@ -257,22 +462,102 @@ func TestEditThread(t *testing.T) {
 	thread := models.Thread{ID: 1, Title: "New Title", Content: "New Content", UserID: 1}
 
 	t.Run("success", func(t *testing.T) {
-		mockRepo.On("EditThread", thread, 1).Return(nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("EditThread", mock.Anything, thread, mock.Anything).Return(nil).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.EditThread(thread, 1)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		err := u.EditThread(context.Background(), thread, 1)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("error", func(t *testing.T) {
-		mockRepo.On("EditThread", thread, 2).Return(errors.New("error")).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 3).Return(models.Actor{ID: 3, Role: models.RoleModerator}, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 3).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("EditThread", mock.Anything, thread, mock.Anything).Return(errors.New("error")).Once()
 
-		u := NewPostUseCase(mockRepo)
-		err := u.EditThread(thread, 2)
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		err := u.EditThread(context.Background(), thread, 3)
 
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("no permissions", func(t *testing.T) {
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser}, nil).Once()
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		err := u.EditThread(context.Background(), thread, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+func TestSetThreadState(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	thread := models.Thread{ID: 1, Title: "Title", UserID: 1}
+	locked := true
+
+	t.Run("success", func(t *testing.T) {
+		expected := thread
+		expected.Locked = true
+
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 3).Return(models.Actor{ID: 3, Role: models.RoleModerator}, nil).Once()
+		mockRepo.On("UpdateThreadState", mock.Anything, expected, mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.ActorID == 3 && e.Action == models.AuditThreadState && e.TargetID == 1 &&
+				strings.Contains(string(e.Before), `"locked":false`) &&
+				strings.Contains(string(e.After), `"locked":true`)
+		})).Return(nil).Once()
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		result, err := u.SetThreadState(context.Background(), 1, models.ThreadState{Locked: &locked}, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("no permissions", func(t *testing.T) {
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleUser}, nil).Once()
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		_, err := u.SetThreadState(context.Background(), 1, models.ThreadState{Locked: &locked}, 1)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("slow mode", func(t *testing.T) {
+		slowMode := 30
+		expected := thread
+		expected.SlowMode = slowMode
+
+		mockRepo.On("GetThreadByID", mock.Anything, 1).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 3).Return(models.Actor{ID: 3, Role: models.RoleModerator}, nil).Once()
+		mockRepo.On("UpdateThreadState", mock.Anything, expected, mock.Anything).Return(nil).Once()
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		result, err := u.SetThreadState(context.Background(), 1, models.ThreadState{SlowMode: &slowMode}, 3)
+
+		assert.NoError(t, err)
+		assert.Equal(t, slowMode, result.SlowMode)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid slow mode", func(t *testing.T) {
+		slowMode := -5
+
+		u := NewThreadUseCase(mockRepo, zap.NewNop())
+		_, err := u.SetThreadState(context.Background(), 1, models.ThreadState{SlowMode: &slowMode}, 3)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidSlowMode)
+	})
 }
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/i18n"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"strings"
+)
+
+type PreferenceUseCase interface {
+	GetLanguage(ctx context.Context, userID int) (string, error)
+	SetLanguage(ctx context.Context, userID int, language string) error
+}
+
+type UUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+}
+
+func NewPreferenceUseCase(repo repository.ForumRepository, logger *zap.Logger) PreferenceUseCase {
+	return &UUseCase{repo: repo, logger: logger}
+}
+
+// GetLanguage возвращает язык, выбранный пользователем, или пустую строку.
+func (f *UUseCase) GetLanguage(ctx context.Context, userID int) (string, error) {
+	return f.repo.GetUserLanguage(ctx, userID)
+}
+
+// SetLanguage сохраняет язык ответов API для пользователя. Пустой language
+// сбрасывает выбор.
+func (f *UUseCase) SetLanguage(ctx context.Context, userID int, language string) error {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language != "" && !i18n.Supported(language) {
+		return models.ErrorInvalidLanguage.WithField("language", "поддерживаются: "+strings.Join(i18n.Languages(), ", "))
+	}
+
+	if err := f.repo.SetUserLanguage(ctx, userID, language); err != nil {
+		return err
+	}
+	logger.From(ctx, f.logger).Info("Язык пользователя изменен",
+		zap.Int("userID", userID),
+		zap.String("language", language))
+	return nil
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestSetLanguage(t *testing.T) {
+	t.Run("supported", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("SetUserLanguage", mock.Anything, 1, "en").Return(nil).Once()
+
+		u := NewPreferenceUseCase(mockRepo, zap.NewNop())
+		err := u.SetLanguage(context.Background(), 1, " EN ")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("SetUserLanguage", mock.Anything, 1, "").Return(nil).Once()
+
+		u := NewPreferenceUseCase(mockRepo, zap.NewNop())
+		err := u.SetLanguage(context.Background(), 1, "")
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+
+		u := NewPreferenceUseCase(mockRepo, zap.NewNop())
+		err := u.SetLanguage(context.Background(), 1, "de")
+
+		assert.ErrorIs(t, err, models.ErrorInvalidLanguage)
+		var domain *models.Error
+		if assert.ErrorAs(t, err, &domain) {
+			assert.Contains(t, domain.Fields, "language")
+		}
+		mockRepo.AssertNotCalled(t, "SetUserLanguage", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
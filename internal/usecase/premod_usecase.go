@@ -0,0 +1,251 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type PremodUseCase interface {
+	GetQueue(ctx context.Context, actorID int) (models.PendingQueue, error)
+	ApprovePost(ctx context.Context, id, actorID int) (models.Post, error)
+	RejectPost(ctx context.Context, id int, reason string, actorID int) error
+	ApproveThread(ctx context.Context, id, actorID int) (models.Thread, error)
+	RejectThread(ctx context.Context, id int, reason string, actorID int) error
+}
+
+type QUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+}
+
+func NewPremodUseCase(repo repository.ForumRepository, logger *zap.Logger) PremodUseCase {
+	return &QUseCase{repo: repo, logger: logger}
+}
+
+// requiresApproval сообщает, нужно ли отправить сообщение пользователя на
+// премодерацию: у него меньше threshold опубликованных сообщений и он не
+// модерирует категорию. threshold = 0 отключает премодерацию.
+func requiresApproval(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, threshold, userID, categoryID int) (bool, error) {
+	if threshold <= 0 {
+		return false, nil
+	}
+
+	count, err := repo.CountUserContent(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if count >= threshold {
+		return false, nil
+	}
+
+	actor, err := repo.GetActor(ctx, userID)
+	if err == nil && authz.Authorize(actor, authz.ContentApprove, authz.Resource{CategoryID: categoryID}) == nil {
+		return false, nil
+	}
+
+	logger.From(ctx, log).Info("Сообщение нового пользователя отправлено на премодерацию",
+		zap.Int("userID", userID),
+		zap.Int("published", count),
+		zap.Int("threshold", threshold))
+	return true, nil
+}
+
+// viewerFor определяет, видит ли читатель ожидающие проверки посты треда
+// threadID. threadID = 0 означает посты из разных тредов: тогда их видят
+// только модераторы всего форума.
+func viewerFor(ctx context.Context, repo repository.ForumRepository, viewerID, threadID int) models.Viewer {
+	viewer := models.Viewer{ID: viewerID}
+	if viewerID == 0 {
+		return viewer
+	}
+
+	actor, err := repo.GetActor(ctx, viewerID)
+	if err != nil {
+		return viewer
+	}
+	if authz.HasPermission(actor.Role, authz.ContentApprove) {
+		viewer.Moderator = true
+		return viewer
+	}
+	if len(actor.Categories) == 0 || threadID == 0 {
+		return viewer
+	}
+
+	thread, err := repo.GetThreadByID(ctx, threadID)
+	if err == nil {
+		viewer.Moderator = actor.Moderates(thread.CategoryID)
+	}
+	return viewer
+}
+
+func (f *QUseCase) GetQueue(ctx context.Context, actorID int) (models.PendingQueue, error) {
+	actor, err := f.repo.GetActor(ctx, actorID)
+	if err != nil {
+		return models.PendingQueue{}, fmt.Errorf("%w: %v", models.ErrorForbidden, err)
+	}
+	global := authz.HasPermission(actor.Role, authz.ContentApprove)
+	if !global && len(actor.Categories) == 0 {
+		return models.PendingQueue{}, models.ErrorForbidden
+	}
+
+	threads, err := f.repo.GetPendingThreads(ctx)
+	if err != nil {
+		return models.PendingQueue{}, err
+	}
+	posts, err := f.repo.GetPendingPosts(ctx)
+	if err != nil {
+		return models.PendingQueue{}, err
+	}
+
+	// Модератор категории видит только очередь своих категорий.
+	queue := models.PendingQueue{Threads: []models.Thread{}, Posts: []models.PendingPost{}}
+	for _, thread := range threads {
+		if global || actor.Moderates(thread.CategoryID) {
+			queue.Threads = append(queue.Threads, thread)
+		}
+	}
+	for _, post := range posts {
+		if global || actor.Moderates(post.CategoryID) {
+			queue.Posts = append(queue.Posts, post)
+		}
+	}
+	return queue, nil
+}
+
+func (f *QUseCase) ApprovePost(ctx context.Context, id, actorID int) (models.Post, error) {
+	post, err := f.pendingPost(ctx, id, actorID)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if err := f.repo.ApprovePost(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditPostApprove,
+		TargetType: models.TargetPost,
+		TargetID:   id,
+	}); err != nil {
+		return models.Post{}, err
+	}
+
+	f.notify(ctx, post.UserID, fmt.Sprintf("Ваш пост #%d одобрен модератором и опубликован.", id))
+	post.Pending = false
+	return post, nil
+}
+
+func (f *QUseCase) RejectPost(ctx context.Context, id int, reason string, actorID int) error {
+	post, err := f.pendingPost(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+
+	if err := f.repo.RejectPost(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditPostReject,
+		TargetType: models.TargetPost,
+		TargetID:   id,
+		Reason:     reason,
+		Before:     models.Snapshot(post),
+	}); err != nil {
+		return err
+	}
+
+	f.notify(ctx, post.UserID, rejectMessage(fmt.Sprintf("Ваш пост #%d отклонен модератором.", id), reason))
+	return nil
+}
+
+func (f *QUseCase) ApproveThread(ctx context.Context, id, actorID int) (models.Thread, error) {
+	thread, err := f.pendingThread(ctx, id, actorID)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if err := f.repo.ApproveThread(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadApprove,
+		TargetType: models.TargetThread,
+		TargetID:   id,
+	}); err != nil {
+		return models.Thread{}, err
+	}
+
+	f.notify(ctx, thread.UserID, fmt.Sprintf("Ваш тред «%s» одобрен модератором и опубликован.", thread.Title))
+	thread.Pending = false
+	return thread, nil
+}
+
+func (f *QUseCase) RejectThread(ctx context.Context, id int, reason string, actorID int) error {
+	thread, err := f.pendingThread(ctx, id, actorID)
+	if err != nil {
+		return err
+	}
+
+	if err := f.repo.RejectThread(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadReject,
+		TargetType: models.TargetThread,
+		TargetID:   id,
+		Reason:     reason,
+		Before:     models.Snapshot(thread),
+	}); err != nil {
+		return err
+	}
+
+	f.notify(ctx, thread.UserID, rejectMessage(fmt.Sprintf("Ваш тред «%s» отклонен модератором.", thread.Title), reason))
+	return nil
+}
+
+// pendingPost загружает ожидающий проверки пост и проверяет права модератора.
+func (f *QUseCase) pendingPost(ctx context.Context, id, actorID int) (models.Post, error) {
+	post, err := f.repo.GetPostByID(ctx, id)
+	if err != nil {
+		return models.Post{}, err
+	}
+	thread, err := f.repo.GetThreadByID(ctx, post.ThreadID)
+	if err != nil {
+		return models.Post{}, err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ContentApprove, authz.Resource{CategoryID: thread.CategoryID}); err != nil {
+		return models.Post{}, err
+	}
+	if !post.Pending {
+		return models.Post{}, models.ErrorNotPending
+	}
+	return post, nil
+}
+
+// pendingThread загружает ожидающий проверки тред и проверяет права модератора.
+func (f *QUseCase) pendingThread(ctx context.Context, id, actorID int) (models.Thread, error) {
+	thread, err := f.repo.GetThreadByID(ctx, id)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ContentApprove, authz.Resource{CategoryID: thread.CategoryID}); err != nil {
+		return models.Thread{}, err
+	}
+	if !thread.Pending {
+		return models.Thread{}, models.ErrorNotPending
+	}
+	return thread, nil
+}
+
+// notify уведомляет автора о решении. Решение уже сохранено, поэтому
+// ошибка только логируется.
+func (f *QUseCase) notify(ctx context.Context, userID int, message string) {
+	if err := f.repo.CreateNotification(ctx, models.Notification{UserID: userID, Message: message}); err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка уведомления автора о решении премодерации",
+			zap.Int("userID", userID),
+			zap.Error(err))
+	}
+}
+
+func rejectMessage(msg, reason string) string {
+	if reason != "" {
+		msg += " Причина: " + reason
+	}
+	return msg
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestCreatePost_Premoderation(t *testing.T) {
+	post := models.Post{Content: "Привет", CreateAt: time.Now(), ThreadID: 2, UserID: 5}
+	thread := models.Thread{ID: 2, UserID: 1, CategoryID: 3}
+
+	t.Run("new user goes to queue", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 5).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CountUserContent", mock.Anything, 5).Return(1, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p models.Post) bool { return p.Pending })).
+			Return(models.Post{ID: 7, ThreadID: 2, UserID: 5, Pending: true}, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetTrustThreshold(3)
+		created, err := u.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		assert.True(t, created.Pending)
+		assert.True(t, created.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("category moderator is exempt", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActiveBans", mock.Anything, 5).Return([]models.Ban(nil), nil).Once()
+		mockRepo.On("CountUserContent", mock.Anything, 5).Return(0, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+		mockRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p models.Post) bool { return !p.Pending })).
+			Return(models.Post{ID: 7, ThreadID: 2, UserID: 5}, nil).Once()
+		mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		u.SetTrustThreshold(3)
+		created, err := u.CreatePost(context.Background(), post)
+
+		assert.NoError(t, err)
+		assert.False(t, created.Hidden)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("pending thread of another user", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(models.Thread{ID: 2, UserID: 1, Pending: true}, nil).Once()
+
+		u := NewPostUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreatePost(context.Background(), post)
+
+		assert.ErrorIs(t, err, models.ErrorNotFoundThread)
+		mockRepo.AssertNotCalled(t, "CreatePost", mock.Anything, mock.Anything)
+	})
+}
+
+func TestApprovePost(t *testing.T) {
+	pending := models.Post{ID: 7, ThreadID: 2, UserID: 5, Pending: true}
+	thread := models.Thread{ID: 2, CategoryID: 3}
+
+	t.Run("category moderator", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetPostByID", mock.Anything, 7).Return(pending, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+		mockRepo.On("ApprovePost", mock.Anything, 7, mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.Action == models.AuditPostApprove && e.ActorID == 2
+		})).Return(nil).Once()
+		mockRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n models.Notification) bool {
+			return n.UserID == 5
+		})).Return(nil).Once()
+
+		u := NewPremodUseCase(mockRepo, zap.NewNop())
+		post, err := u.ApprovePost(context.Background(), 7, 2)
+
+		assert.NoError(t, err)
+		assert.False(t, post.Pending)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("moderator of other category", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetPostByID", mock.Anything, 7).Return(pending, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{4}}, nil).Once()
+
+		u := NewPremodUseCase(mockRepo, zap.NewNop())
+		_, err := u.ApprovePost(context.Background(), 7, 2)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNotCalled(t, "ApprovePost", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already published", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetPostByID", mock.Anything, 7).Return(models.Post{ID: 7, ThreadID: 2}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+
+		u := NewPremodUseCase(mockRepo, zap.NewNop())
+		_, err := u.ApprovePost(context.Background(), 7, 1)
+
+		assert.ErrorIs(t, err, models.ErrorNotPending)
+	})
+}
+
+func TestRejectThread(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetThreadByID", mock.Anything, 2).Return(models.Thread{ID: 2, UserID: 5, CategoryID: 3, Title: "Тред", Pending: true}, nil).Once()
+	mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+	mockRepo.On("RejectThread", mock.Anything, 2, mock.MatchedBy(func(e models.AuditEntry) bool {
+		return e.Action == models.AuditThreadReject && e.Reason == "реклама"
+	})).Return(nil).Once()
+	mockRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n models.Notification) bool {
+		return n.UserID == 5
+	})).Return(nil).Once()
+
+	u := NewPremodUseCase(mockRepo, zap.NewNop())
+	err := u.RejectThread(context.Background(), 2, "реклама", 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetQueue(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+	mockRepo.On("GetPendingThreads", mock.Anything).Return([]models.Thread{{ID: 1, CategoryID: 3}, {ID: 2, CategoryID: 4}}, nil).Once()
+	mockRepo.On("GetPendingPosts", mock.Anything).Return([]models.PendingPost{{Post: models.Post{ID: 5}, CategoryID: 4}}, nil).Once()
+
+	u := NewPremodUseCase(mockRepo, zap.NewNop())
+	queue, err := u.GetQueue(context.Background(), 2)
+
+	assert.NoError(t, err)
+	assert.Len(t, queue.Threads, 1)
+	assert.Empty(t, queue.Posts)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/spam"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+)
+
+type ReportUseCase interface {
+	CreateReport(ctx context.Context, report models.Report) (models.Report, error)
+	GetReports(ctx context.Context, filter models.ReportFilter, actorID int) ([]models.Report, error)
+	ResolveReport(ctx context.Context, id int, status, resolution string, actorID int) (models.Report, error)
+	BulkAction(ctx context.Context, ids []int, action, resolution string, actorID int) ([]models.BulkReportResult, error)
+	GetNotifications(ctx context.Context, userID int) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, id, userID int) error
+}
+
+type RUseCase struct {
+	repo   repository.ForumRepository
+	logger *zap.Logger
+	spam   *spam.Classifier
+}
+
+func NewReportUseCase(repo repository.ForumRepository, logger *zap.Logger) *RUseCase {
+	return &RUseCase{repo: repo, logger: logger}
+}
+
+// SetSpamClassifier включает обучение классификатора спама на решениях
+// по жалобам на посты.
+func (f *RUseCase) SetSpamClassifier(classifier *spam.Classifier) {
+	f.spam = classifier
+}
+
+func (f *RUseCase) CreateReport(ctx context.Context, report models.Report) (models.Report, error) {
+	if report.Reason == "" || len(report.Reason) > 1000 {
+		return models.Report{}, models.ErrorInvalidReport.WithField("reason", "пустая или длиннее 1000 символов")
+	}
+
+	var err error
+	switch report.TargetType {
+	case models.TargetPost:
+		_, err = f.repo.GetPostByID(ctx, report.TargetID)
+		if err != nil {
+			err = models.ErrorNotFoundPost
+		}
+	case models.TargetThread:
+		_, err = f.repo.GetThreadByID(ctx, report.TargetID)
+	default:
+		return models.Report{}, models.ErrorInvalidReport.WithField("target_type", fmt.Sprintf("неизвестный тип объекта %q", report.TargetType))
+	}
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	created, err := f.repo.CreateReport(ctx, report)
+	if err != nil {
+		return models.Report{}, err
+	}
+
+	logger.From(ctx, f.logger).Info("Новая жалоба",
+		zap.Int("id", created.ID),
+		zap.Int("reporterID", created.ReporterID),
+		zap.String("targetType", created.TargetType),
+		zap.Int("targetID", created.TargetID))
+	return created, nil
+}
+
+func (f *RUseCase) GetReports(ctx context.Context, filter models.ReportFilter, actorID int) ([]models.Report, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ReportManage, authz.Resource{}); err != nil {
+		return nil, err
+	}
+	return f.repo.GetReports(ctx, filter)
+}
+
+func (f *RUseCase) ResolveReport(ctx context.Context, id int, status, resolution string, actorID int) (models.Report, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ReportManage, authz.Resource{}); err != nil {
+		return models.Report{}, err
+	}
+	if status != models.ReportActioned && status != models.ReportDismissed {
+		return models.Report{}, models.ErrorInvalidReportAction.WithField("status", fmt.Sprintf("неизвестный статус %q", status))
+	}
+
+	report, err := f.repo.GetReportByID(ctx, id)
+	if err != nil {
+		return models.Report{}, err
+	}
+	post, hasPost := f.reportedPost(ctx, report)
+	resolved, err := f.resolve(ctx, f.repo, report, status, resolution, actorID)
+	if err == nil && hasPost {
+		f.learnSpam(ctx, post, status, actorID)
+	}
+	return resolved, err
+}
+
+// resolve закрывает жалобу через repo и уведомляет автора жалобы о решении.
+func (f *RUseCase) resolve(ctx context.Context, repo repository.ForumRepository, report models.Report, status, resolution string, actorID int) (models.Report, error) {
+	if report.Status != models.ReportOpen {
+		return models.Report{}, models.ErrorReportClosed
+	}
+
+	before := models.Snapshot(report)
+	report.Status = status
+	report.ModeratorID = actorID
+	report.Resolution = resolution
+	if err := repo.ResolveReport(ctx, report, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditReportResolve,
+		TargetType: models.TargetReport,
+		TargetID:   report.ID,
+		Reason:     resolution,
+		Before:     before,
+		After:      models.Snapshot(report),
+	}); err != nil {
+		return models.Report{}, err
+	}
+
+	// Жалобы автомодерации созданы системой, уведомлять по ним некого.
+	if report.ReporterID != models.AutomodReporterID {
+		if err := repo.CreateNotification(ctx, models.Notification{
+			UserID:  report.ReporterID,
+			Message: reportFeedback(report),
+		}); err != nil {
+			// Жалоба уже закрыта, поэтому ошибку уведомления только логируем.
+			logger.From(ctx, f.logger).Error("Ошибка уведомления автора жалобы",
+				zap.Int("reportID", report.ID),
+				zap.Int("reporterID", report.ReporterID),
+				zap.Error(err))
+		}
+	}
+
+	logger.From(ctx, f.logger).Info("Жалоба рассмотрена",
+		zap.Int("id", report.ID),
+		zap.String("status", status),
+		zap.Int("moderatorID", actorID))
+	return report, nil
+}
+
+func reportFeedback(report models.Report) string {
+	var msg string
+	if report.Status == models.ReportActioned {
+		msg = fmt.Sprintf("Ваша жалоба #%d рассмотрена: меры приняты.", report.ID)
+	} else {
+		msg = fmt.Sprintf("Ваша жалоба #%d рассмотрена: нарушений не найдено.", report.ID)
+	}
+	if report.Resolution != "" {
+		msg += " Комментарий модератора: " + report.Resolution
+	}
+	return msg
+}
+
+func (f *RUseCase) BulkAction(ctx context.Context, ids []int, action, resolution string, actorID int) ([]models.BulkReportResult, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ReportManage, authz.Resource{}); err != nil {
+		return nil, err
+	}
+	if action != models.ReportActionDelete && action != models.ReportActionDismiss {
+		return nil, models.ErrorInvalidReportAction.WithField("action", fmt.Sprintf("неизвестное действие %q", action))
+	}
+
+	logger.From(ctx, f.logger).Info("Массовое действие над жалобами",
+		zap.String("action", action),
+		zap.Ints("ids", ids),
+		zap.Int("actorID", actorID))
+
+	results := make([]models.BulkReportResult, 0, len(ids))
+	for _, id := range ids {
+		report, err := f.bulkOne(ctx, id, action, resolution, actorID)
+		if err != nil {
+			results = append(results, models.BulkReportResult{ReportID: id, Error: err.Error()})
+			continue
+		}
+		results = append(results, models.BulkReportResult{ReportID: id, Status: report.Status})
+	}
+	return results, nil
+}
+
+func (f *RUseCase) bulkOne(ctx context.Context, id int, action, resolution string, actorID int) (models.Report, error) {
+	report, err := f.repo.GetReportByID(ctx, id)
+	if err != nil {
+		return models.Report{}, err
+	}
+	if report.Status != models.ReportOpen {
+		return models.Report{}, models.ErrorReportClosed
+	}
+
+	post, hasPost := f.reportedPost(ctx, report)
+	if action == models.ReportActionDismiss {
+		resolved, err := f.resolve(ctx, f.repo, report, models.ReportDismissed, resolution, actorID)
+		if err == nil && hasPost {
+			f.learnSpam(ctx, post, models.ReportDismissed, actorID)
+		}
+		return resolved, err
+	}
+
+	entry := models.AuditEntry{
+		ActorID:    actorID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     resolution,
+		Details:    fmt.Sprintf("по жалобе #%d", report.ID),
+	}
+	if entry.TargetType == models.TargetPost && hasPost {
+		entry.Before = models.Snapshot(post)
+	}
+
+	// Удаление и закрытие жалобы фиксируются вместе: иначе при сбое
+	// закрытия объект был бы удален, а жалоба осталась открытой.
+	var resolved models.Report
+	err = f.repo.WithTx(ctx, func(repo repository.ForumRepository) error {
+		var err error
+		switch report.TargetType {
+		case models.TargetPost:
+			entry.Action = models.AuditPostDelete
+			err = repo.DeletePostByID(ctx, report.TargetID, entry)
+		case models.TargetThread:
+			entry.Action = models.AuditThreadDelete
+			err = repo.DeleteThreadByID(ctx, report.TargetID, entry)
+		}
+		// Объект мог быть удален по другой жалобе на него же.
+		if err != nil && !errors.Is(err, models.ErrorNotFoundPost) && !errors.Is(err, models.ErrorNotFoundThread) {
+			return err
+		}
+		resolved, err = f.resolve(ctx, repo, report, models.ReportActioned, resolution, actorID)
+		return err
+	})
+	if err != nil {
+		return models.Report{}, err
+	}
+	if hasPost {
+		f.learnSpam(ctx, post, models.ReportActioned, actorID)
+	}
+	return resolved, nil
+}
+
+// reportedPost загружает пост, на который подана жалоба, пока его не удалили.
+// Нужен только для обучения классификатора спама.
+func (f *RUseCase) reportedPost(ctx context.Context, report models.Report) (models.Post, bool) {
+	if f.spam == nil || report.TargetType != models.TargetPost || report.Status != models.ReportOpen {
+		return models.Post{}, false
+	}
+	post, err := f.repo.GetPostByID(ctx, report.TargetID)
+	if err != nil {
+		return models.Post{}, false
+	}
+	return post, true
+}
+
+// learnSpam дообучает классификатор на решении по жалобе: пост, по которому
+// приняты меры, считается спамом, пост с отклоненной жалобой - нет.
+// Ошибка только логируется: жалоба уже закрыта.
+func (f *RUseCase) learnSpam(ctx context.Context, post models.Post, status string, actorID int) {
+	label := models.SpamLabelHam
+	if status == models.ReportActioned {
+		label = models.SpamLabelSpam
+	}
+	if err := trainSpam(ctx, f.logger, f.repo, f.spam, post, label, actorID); err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка обучения классификатора спама по жалобе",
+			zap.Int("postID", post.ID),
+			zap.Error(err))
+	}
+}
+
+func (f *RUseCase) GetNotifications(ctx context.Context, userID int) ([]models.Notification, error) {
+	return f.repo.GetNotifications(ctx, userID)
+}
+
+func (f *RUseCase) MarkNotificationRead(ctx context.Context, id, userID int) error {
+	return f.repo.MarkNotificationRead(ctx, id, userID)
+}
@@ -0,0 +1,146 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestCreateReport(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		report := models.Report{ReporterID: 2, TargetType: models.TargetPost, TargetID: 5, Reason: "spam"}
+		created := report
+		created.ID = 1
+		created.Status = models.ReportOpen
+
+		mockRepo.On("GetPostByID", mock.Anything, 5).Return(models.Post{ID: 5}, nil).Once()
+		mockRepo.On("CreateReport", mock.Anything, report).Return(created, nil).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		result, err := u.CreateReport(context.Background(), report)
+
+		assert.NoError(t, err)
+		assert.Equal(t, created, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("unknown target type", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreateReport(context.Background(), models.Report{TargetType: "user", TargetID: 1, Reason: "spam"})
+
+		assert.ErrorIs(t, err, models.ErrorInvalidReport)
+		mockRepo.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+	})
+
+	t.Run("missing target", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetThreadByID", mock.Anything, 9).Return(models.Thread{}, models.ErrorNotFoundThread).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		_, err := u.CreateReport(context.Background(), models.Report{TargetType: models.TargetThread, TargetID: 9, Reason: "spam"})
+
+		assert.ErrorIs(t, err, models.ErrorNotFoundThread)
+		mockRepo.AssertNotCalled(t, "CreateReport", mock.Anything, mock.Anything)
+	})
+}
+
+func TestResolveReport(t *testing.T) {
+	report := models.Report{ID: 1, ReporterID: 2, TargetType: models.TargetPost, TargetID: 5, Status: models.ReportOpen}
+
+	t.Run("dismiss notifies reporter", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetReportByID", mock.Anything, 1).Return(report, nil).Once()
+		mockRepo.On("ResolveReport", mock.Anything, mock.MatchedBy(func(r models.Report) bool {
+			return r.Status == models.ReportDismissed && r.ModeratorID == 1
+		}), mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.Action == models.AuditReportResolve && e.TargetID == 1 && e.ActorID == 1
+		})).Return(nil).Once()
+		mockRepo.On("CreateNotification", mock.Anything, mock.MatchedBy(func(n models.Notification) bool {
+			return n.UserID == 2 && n.Message != ""
+		})).Return(nil).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		result, err := u.ResolveReport(context.Background(), 1, models.ReportDismissed, "", 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.ReportDismissed, result.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("automod report has no one to notify", func(t *testing.T) {
+		automodReport := report
+		automodReport.ReporterID = models.AutomodReporterID
+
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetReportByID", mock.Anything, 1).Return(automodReport, nil).Once()
+		mockRepo.On("ResolveReport", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		_, err := u.ResolveReport(context.Background(), 1, models.ReportActioned, "", 1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertNotCalled(t, "CreateNotification", mock.Anything, mock.Anything)
+	})
+
+	t.Run("already closed", func(t *testing.T) {
+		closed := report
+		closed.Status = models.ReportActioned
+
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+		mockRepo.On("GetReportByID", mock.Anything, 1).Return(closed, nil).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		_, err := u.ResolveReport(context.Background(), 1, models.ReportDismissed, "", 1)
+
+		assert.ErrorIs(t, err, models.ErrorReportClosed)
+		mockRepo.AssertNotCalled(t, "ResolveReport", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not moderator", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 3).Return(models.Actor{ID: 3, Role: models.RoleUser}, nil).Once()
+
+		u := NewReportUseCase(mockRepo, zap.NewNop())
+		_, err := u.ResolveReport(context.Background(), 1, models.ReportDismissed, "", 3)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+	})
+}
+
+func TestBulkAction(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	postReport := models.Report{ID: 1, ReporterID: 2, TargetType: models.TargetPost, TargetID: 5, Status: models.ReportOpen}
+	threadReport := models.Report{ID: 2, ReporterID: 3, TargetType: models.TargetThread, TargetID: 7, Status: models.ReportOpen}
+
+	mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleAdmin}, nil).Once()
+	mockRepo.On("GetReportByID", mock.Anything, 1).Return(postReport, nil).Once()
+	mockRepo.On("DeletePostByID", mock.Anything, 5, mock.MatchedBy(func(e models.AuditEntry) bool {
+		return e.Action == models.AuditPostDelete && e.Reason == "spam" && e.Details == "по жалобе #1"
+	})).Return(nil).Once()
+	mockRepo.On("GetReportByID", mock.Anything, 2).Return(threadReport, nil).Once()
+	mockRepo.On("DeleteThreadByID", mock.Anything, 7, mock.Anything).Return(nil).Once()
+	mockRepo.On("GetReportByID", mock.Anything, 3).Return(models.Report{}, models.ErrorNotFoundReport).Once()
+	mockRepo.On("ResolveReport", mock.Anything, mock.Anything, mock.Anything).Return(nil).Twice()
+	mockRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil).Twice()
+
+	u := NewReportUseCase(mockRepo, zap.NewNop())
+	results, err := u.BulkAction(context.Background(), []int{1, 2, 3}, models.ReportActionDelete, "spam", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []models.BulkReportResult{
+		{ReportID: 1, Status: models.ReportActioned},
+		{ReportID: 2, Status: models.ReportActioned},
+		{ReportID: 3, Error: models.ErrorNotFoundReport.Error()},
+	}, results)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/spam"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+// spamScoresLimit - сколько оценок отдается за один запрос.
+const spamScoresLimit = 100
+
+type SpamUseCase interface {
+	MarkPost(ctx context.Context, postID int, label string, actorID int) error
+	GetScores(ctx context.Context, min float64, actorID int) ([]models.SpamScore, error)
+}
+
+type SUseCase struct {
+	repo       repository.ForumRepository
+	logger     *zap.Logger
+	classifier *spam.Classifier
+}
+
+func NewSpamUseCase(repo repository.ForumRepository, classifier *spam.Classifier, logger *zap.Logger) SpamUseCase {
+	return &SUseCase{repo: repo, logger: logger, classifier: classifier}
+}
+
+// MarkPost размечает пост как спам или не спам и дообучает классификатор.
+func (f *SUseCase) MarkPost(ctx context.Context, postID int, label string, actorID int) error {
+	if label != models.SpamLabelSpam && label != models.SpamLabelHam {
+		return models.ErrorInvalidSpamLabel.WithField("label", fmt.Sprintf("неизвестная метка %q", label))
+	}
+
+	post, err := f.repo.GetPostByID(ctx, postID)
+	if err != nil {
+		return err
+	}
+	thread, err := f.repo.GetThreadByID(ctx, post.ThreadID)
+	if err != nil {
+		return err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.SpamTrain, authz.Resource{CategoryID: thread.CategoryID}); err != nil {
+		return err
+	}
+
+	return trainSpam(ctx, f.logger, f.repo, f.classifier, post, label, actorID)
+}
+
+func (f *SUseCase) GetScores(ctx context.Context, min float64, actorID int) ([]models.SpamScore, error) {
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.SpamTrain, authz.Resource{}); err != nil {
+		return nil, err
+	}
+	return f.repo.GetSpamScores(ctx, min, spamScoresLimit)
+}
+
+// trainSpam сохраняет разметку поста и дообучает классификатор в памяти.
+func trainSpam(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, classifier *spam.Classifier, post models.Post, label string, moderatorID int) error {
+	tokens := spam.Tokens(post.Content)
+	previous, err := repo.TrainSpam(ctx, models.SpamFeedback{
+		PostID:      post.ID,
+		Label:       label,
+		ModeratorID: moderatorID,
+		CreateAt:    time.Now(),
+	}, tokens, models.AuditEntry{
+		ActorID:    moderatorID,
+		Action:     models.AuditSpamLabel,
+		TargetType: models.TargetPost,
+		TargetID:   post.ID,
+		After:      models.Snapshot(map[string]string{"label": label}),
+	})
+	if err != nil {
+		return err
+	}
+	if previous == label {
+		return nil
+	}
+	classifier.Learn(tokens, label, previous)
+
+	logger.From(ctx, log).Info("Классификатор спама дообучен",
+		zap.Int("postID", post.ID),
+		zap.String("label", label),
+		zap.String("previous", previous),
+		zap.Int("moderatorID", moderatorID))
+	return nil
+}
+
+// spamVerdict - оценка поста классификатором. Scored ложно, если
+// классификатор выключен или еще не обучен.
+type spamVerdict struct {
+	Score   float64
+	Scored  bool
+	Action  automod.Action
+	Matches []automod.Match
+}
+
+// scoreSpam оценивает текст поста классификатором и подбирает действие
+// по его порогам.
+func scoreSpam(ctx context.Context, log *zap.Logger, classifier *spam.Classifier, userID int, text string) spamVerdict {
+	if classifier == nil {
+		return spamVerdict{}
+	}
+	score, ok := classifier.Score(text)
+	if !ok {
+		return spamVerdict{}
+	}
+
+	verdict := spamVerdict{Score: score, Scored: true, Action: classifier.Action(score)}
+	if verdict.Action == "" {
+		return verdict
+	}
+	verdict.Matches = []automod.Match{{Rule: spam.Rule, Action: verdict.Action}}
+	logger.From(ctx, log).Info("Пост похож на спам",
+		zap.Int("userID", userID),
+		zap.Float64("score", score),
+		zap.String("action", string(verdict.Action)))
+	return verdict
+}
+
+// saveSpamScore сохраняет оценку созданного поста. Ошибка только логируется.
+func saveSpamScore(ctx context.Context, log *zap.Logger, repo repository.ForumRepository, postID int, score float64) {
+	if err := repo.SaveSpamScore(ctx, models.SpamScore{PostID: postID, Score: score, CreateAt: time.Now()}); err != nil {
+		logger.From(ctx, log).Error("Ошибка сохранения оценки спама",
+			zap.Int("postID", postID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/spam"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func newTestClassifier() *spam.Classifier {
+	c := spam.NewClassifier(spam.Config{FlagThreshold: 0.6, HoldThreshold: 0.9, MinSamples: 1}, models.SpamModel{})
+	c.Learn(spam.Tokens("ставки бонус казино депозит"), models.SpamLabelSpam, "")
+	c.Learn(spam.Tokens("роутер драйвер настройка сети"), models.SpamLabelHam, "")
+	return c
+}
+
+func TestMarkPost(t *testing.T) {
+	post := models.Post{ID: 7, ThreadID: 2, Content: "бонус на ставки"}
+
+	t.Run("category moderator", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetPostByID", mock.Anything, 7).Return(post, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(models.Thread{ID: 2, CategoryID: 3}, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 2).Return(models.Actor{ID: 2, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+		mockRepo.On("TrainSpam", mock.Anything, mock.MatchedBy(func(f models.SpamFeedback) bool {
+			return f.PostID == 7 && f.Label == models.SpamLabelSpam && f.ModeratorID == 2
+		}), map[string]int{"бонус": 1, "на": 1, "ставки": 1}, mock.Anything).Return("", nil).Once()
+
+		classifier := newTestClassifier()
+		u := NewSpamUseCase(mockRepo, classifier, zap.NewNop())
+		err := u.MarkPost(context.Background(), 7, models.SpamLabelSpam, 2)
+
+		assert.NoError(t, err)
+		assert.Equal(t, models.SpamCounts{Spam: 2, Ham: 1}, classifier.Samples())
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("invalid label", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+
+		u := NewSpamUseCase(mockRepo, newTestClassifier(), zap.NewNop())
+		err := u.MarkPost(context.Background(), 7, "maybe", 2)
+
+		assert.ErrorIs(t, err, models.ErrorInvalidSpamLabel)
+	})
+
+	t.Run("user forbidden", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetPostByID", mock.Anything, 7).Return(post, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(models.Thread{ID: 2, CategoryID: 3}, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser}, nil).Once()
+
+		u := NewSpamUseCase(mockRepo, newTestClassifier(), zap.NewNop())
+		err := u.MarkPost(context.Background(), 7, models.SpamLabelHam, 5)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNotCalled(t, "TrainSpam", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestCreatePostSpamScore(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	mockRepo.On("GetThreadByID", mock.Anything, 1).Return(models.Thread{ID: 1}, nil).Once()
+	mockRepo.On("GetActiveBans", mock.Anything, 1).Return([]models.Ban(nil), nil).Once()
+	mockRepo.On("CreatePost", mock.Anything, mock.MatchedBy(func(p models.Post) bool {
+		return p.Pending
+	})).Return(models.Post{ID: 5, ThreadID: 1, UserID: 1, Pending: true}, nil).Once()
+	mockRepo.On("SaveSpamScore", mock.Anything, mock.MatchedBy(func(s models.SpamScore) bool {
+		return s.PostID == 5 && s.Score >= 0.9
+	})).Return(nil).Once()
+	mockRepo.On("CreateReport", mock.Anything, mock.MatchedBy(func(r models.Report) bool {
+		return r.TargetID == 5 && r.ReporterID == models.AutomodReporterID
+	})).Return(models.Report{ID: 1}, nil).Once()
+	mockRepo.On("LinkPostToChat", mock.Anything, mock.Anything).Return(nil).Once()
+
+	u := NewPostUseCase(mockRepo, zap.NewNop())
+	u.SetSpamClassifier(newTestClassifier())
+	created, err := u.CreatePost(context.Background(), models.Post{Content: "казино бонус депозит ставки", ThreadID: 1, UserID: 1})
+
+	assert.NoError(t, err)
+	assert.True(t, created.Hidden)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResolveReportTrainsSpam(t *testing.T) {
+	mockRepo := new(mocks.ForumRepository)
+	report := models.Report{ID: 3, ReporterID: 8, TargetType: models.TargetPost, TargetID: 7, Status: models.ReportOpen}
+	mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleModerator}, nil).Once()
+	mockRepo.On("GetReportByID", mock.Anything, 3).Return(report, nil).Once()
+	mockRepo.On("GetPostByID", mock.Anything, 7).Return(models.Post{ID: 7, Content: "роутер"}, nil).Once()
+	mockRepo.On("ResolveReport", mock.Anything, mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("CreateNotification", mock.Anything, mock.Anything).Return(nil).Once()
+	mockRepo.On("TrainSpam", mock.Anything, mock.MatchedBy(func(f models.SpamFeedback) bool {
+		return f.PostID == 7 && f.Label == models.SpamLabelHam
+	}), mock.Anything, mock.Anything).Return("", nil).Once()
+
+	u := NewReportUseCase(mockRepo, zap.NewNop())
+	u.SetSpamClassifier(newTestClassifier())
+	_, err := u.ResolveReport(context.Background(), 3, models.ReportDismissed, "", 1)
+
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+}
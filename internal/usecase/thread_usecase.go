@@ -1,7 +1,11 @@
 package usecase
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/dedup"
 	"github.com/fire9900/forum/internal/models"
 	"github.com/fire9900/forum/internal/repository"
 	"github.com/fire9900/forum/pkg/logger"
@@ -9,123 +13,256 @@ import (
 )
 
 type ThreadUseCase interface {
-	GetUserThreads(userId int) ([]models.Thread, error)
-	GetAllThreads() ([]models.Thread, error)
-	GetThreadByID(id int) (models.Thread, error)
-	CreateThread(thread models.Thread) (models.Thread, error)
-	DeleteThreadByID(id int, userID int) error
-	EditThread(thread models.Thread, userID int) error
-	CheckUserByID(any models.User, id int) (bool, error)
+	GetUserThreads(ctx context.Context, userId, viewerID int) ([]models.Thread, error)
+	GetAllThreads(ctx context.Context, viewerID int) ([]models.Thread, error)
+	GetThreadByID(ctx context.Context, id, viewerID int) (models.Thread, error)
+	CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error)
+	DeleteThreadByID(ctx context.Context, id int, userID int) error
+	EditThread(ctx context.Context, thread models.Thread, userID int) error
+	SetThreadState(ctx context.Context, id int, state models.ThreadState, userID int) (models.Thread, error)
 }
 
 type TUseCase struct {
-	repo repository.ForumRepository
+	repo           repository.ForumRepository
+	logger         *zap.Logger
+	automod        *automod.Engine
+	dedup          *dedup.Detector
+	trustThreshold int
 }
 
-func NewThreadUseCase(repo repository.ForumRepository) ThreadUseCase {
-	return &TUseCase{repo: repo}
+func NewThreadUseCase(repo repository.ForumRepository, logger *zap.Logger) *TUseCase {
+	return &TUseCase{repo: repo, logger: logger}
 }
 
-func (f *TUseCase) CheckUserByID(any models.User, id int) (bool, error) {
-	return f.repo.CheckUserByID(any, id)
+// SetAutomod включает проверку новых тредов правилами автомодерации.
+func (f *TUseCase) SetAutomod(engine *automod.Engine) {
+	f.automod = engine
 }
 
-func (f *TUseCase) GetUserThreads(userId int) ([]models.Thread, error) {
-	return f.repo.GetThreadsByUserID(userId)
+// SetDedup включает поиск повторов среди новых тредов.
+func (f *TUseCase) SetDedup(detector *dedup.Detector) {
+	f.dedup = detector
 }
 
-func (f *TUseCase) EditThread(thread models.Thread, userID int) error {
-	return f.repo.EditThread(thread, userID)
+// SetTrustThreshold включает премодерацию тредов пользователей, у которых
+// меньше threshold опубликованных сообщений.
+func (f *TUseCase) SetTrustThreshold(threshold int) {
+	f.trustThreshold = threshold
 }
 
-func (f *TUseCase) GetAllThreads() ([]models.Thread, error) {
-	logger.Logger.Debug("Получение всех тредов")
-	threads, err := f.repo.GetAllThreads()
+func (f *TUseCase) GetUserThreads(ctx context.Context, userId, viewerID int) ([]models.Thread, error) {
+	return f.repo.GetThreadsByUserID(ctx, userId, viewerFor(ctx, f.repo, viewerID, 0))
+}
+
+func (f *TUseCase) EditThread(ctx context.Context, thread models.Thread, userID int) error {
+	current, err := f.repo.GetThreadByID(ctx, thread.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := authorize(ctx, f.logger, f.repo, userID, authz.ThreadEditAny, authz.Resource{
+		OwnerID:    current.UserID,
+		CategoryID: current.CategoryID,
+	}); err != nil {
+		return err
+	}
+	if _, err := checkBan(ctx, f.logger, f.repo, userID, current.CategoryID); err != nil {
+		return err
+	}
+	return f.repo.EditThread(ctx, thread, models.AuditEntry{
+		ActorID:    userID,
+		Action:     models.AuditThreadEdit,
+		TargetType: models.TargetThread,
+		TargetID:   thread.ID,
+		Before:     models.Snapshot(current),
+		After:      models.Snapshot(thread),
+	})
+}
+
+func (f *TUseCase) GetAllThreads(ctx context.Context, viewerID int) ([]models.Thread, error) {
+	logger.From(ctx, f.logger).Debug("Получение всех тредов")
+	threads, err := f.repo.GetAllThreads(ctx, viewerFor(ctx, f.repo, viewerID, 0))
 	if err != nil {
-		logger.Logger.Error("Ошибка при получении всех тредов",
+		logger.From(ctx, f.logger).Error("Ошибка при получении всех тредов",
 			zap.Error(err))
 		return nil, err
 	}
-	logger.Logger.Debug("Успешно получены все треды",
+	logger.From(ctx, f.logger).Debug("Успешно получены все треды",
 		zap.Int("count", len(threads)))
 	return threads, nil
 }
 
-func (f *TUseCase) GetThreadByID(id int) (models.Thread, error) {
-	logger.Logger.Debug("Получение треда по ID", zap.Int("id", id))
-	thread, err := f.repo.GetThreadByID(id)
+// GetThreadByID возвращает тред, каким его видит читатель viewerID
+// (0 - аноним). Скрытый от читателя тред не находится.
+func (f *TUseCase) GetThreadByID(ctx context.Context, id, viewerID int) (models.Thread, error) {
+	logger.From(ctx, f.logger).Debug("Получение треда по ID", zap.Int("id", id))
+	thread, err := f.repo.GetThreadForViewer(ctx, id, viewerFor(ctx, f.repo, viewerID, id))
+	if errors.Is(err, models.ErrorNotFoundThread) {
+		// Тред мог быть объединен с другим: отдаем тред, в который он влит.
+		if newID, redirectErr := f.repo.GetThreadRedirect(ctx, id); redirectErr == nil {
+			logger.From(ctx, f.logger).Debug("Тред перенаправлен",
+				zap.Int("id", id),
+				zap.Int("newID", newID))
+			thread, err = f.repo.GetThreadForViewer(ctx, newID, viewerFor(ctx, f.repo, viewerID, newID))
+		}
+	}
 	if err != nil {
-		logger.Logger.Error("Ошибка при получении треда",
+		logger.From(ctx, f.logger).Error("Ошибка при получении треда",
 			zap.Int("id", id),
 			zap.Error(err))
 		return models.Thread{}, err
 	}
-	logger.Logger.Debug("Тред успешно получен",
+	logger.From(ctx, f.logger).Debug("Тред успешно получен",
 		zap.Int("id", id),
 		zap.String("title", thread.Title))
 	return thread, nil
 }
 
-func (f *TUseCase) CreateThread(thread models.Thread) (models.Thread, error) {
-	logger.Logger.Debug("Проверка валидности данных треда",
+func (f *TUseCase) CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	logger.From(ctx, f.logger).Debug("Проверка валидности данных треда",
 		zap.Int("userID", thread.UserID),
 		zap.String("title", thread.Title))
 
-	if thread.Content == "" || len(thread.Content) > 5000 {
-		err := fmt.Errorf("Недопустимый размер описания! Описание == 0 || > 5000")
-		logger.Logger.Error("Невалидное содержание треда",
-			zap.Error(err),
-			zap.Int("contentLength", len(thread.Content)))
+	if err := validateThread(ctx, f.logger, thread); err != nil {
 		return models.Thread{}, err
 	}
-	if thread.Title == "" || len(thread.Title) > 500 {
-		err := fmt.Errorf("Недопустимый размер заголовка! Заголовк == 0 || > 1000")
-		logger.Logger.Error("Невалидный заголовок треда",
-			zap.Error(err),
-			zap.Int("titleLength", len(thread.Title)))
+	// Тред пользователя под теневой блокировкой создается как обычно:
+	// его скрывают от остальных запросы чтения.
+	if _, err := checkBan(ctx, f.logger, f.repo, thread.UserID, thread.CategoryID); err != nil {
+		return models.Thread{}, err
+	}
+	action, matches, err := moderate(ctx, f.logger, f.repo, f.automod, thread.UserID, &thread.Title, &thread.Content)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	dup, err := findDuplicates(ctx, f.logger, f.repo, f.dedup, thread.UserID, thread.Title+"\n"+thread.Content)
+	if err != nil {
 		return models.Thread{}, err
 	}
+	action = automod.Stronger(action, dup.Action)
+	matches = append(matches, dup.Matches...)
+	thread.Pending = action == automod.ActionHold
+	if !thread.Pending {
+		thread.Pending, err = requiresApproval(ctx, f.logger, f.repo, f.trustThreshold, thread.UserID, thread.CategoryID)
+		if err != nil {
+			return models.Thread{}, err
+		}
+	}
 
-	logger.Logger.Info("Создание нового треда",
+	logger.From(ctx, f.logger).Info("Создание нового треда",
 		zap.Int("userID", thread.UserID),
 		zap.String("title", thread.Title))
 
-	createdThread, err := f.repo.CreateThread(thread)
+	createdThread, err := f.repo.CreateThread(ctx, thread)
 	if err != nil {
-		logger.Logger.Error("Ошибка при создании треда",
+		logger.From(ctx, f.logger).Error("Ошибка при создании треда",
 			zap.Any("thread", thread),
 			zap.Error(err))
 		return models.Thread{}, err
 	}
+	saveFingerprint(ctx, f.logger, f.repo, dup, models.TargetThread, createdThread.ID, thread.UserID)
+	reportAutomod(ctx, f.logger, f.repo, action, matches, models.TargetThread, createdThread.ID)
 
-	logger.Logger.Info("Тред успешно создан",
+	logger.From(ctx, f.logger).Info("Тред успешно создан",
 		zap.Int("id", createdThread.ID),
 		zap.String("title", createdThread.Title))
 	return createdThread, nil
 }
 
-func (f *TUseCase) DeleteThreadByID(id int, userID int) error {
-	logger.Logger.Info("Удаление треда", zap.Int("id", id))
+// validateThread проверяет размеры заголовка и описания и возвращает
+// models.ErrorInvalidThread с описанием каждого неверного поля.
+func validateThread(ctx context.Context, log *zap.Logger, thread models.Thread) error {
+	err := models.ErrorInvalidThread
+	if thread.Content == "" || len(thread.Content) > 5000 {
+		err = err.WithField("content", "пустое или длиннее 5000 символов")
+	}
+	if thread.Title == "" || len(thread.Title) > 500 {
+		err = err.WithField("title", "пустой или длиннее 500 символов")
+	}
+	if len(err.Fields) == 0 {
+		return nil
+	}
+
+	logger.From(ctx, log).Warn("Невалидный тред",
+		zap.Error(err),
+		zap.Int("contentLength", len(thread.Content)),
+		zap.Int("titleLength", len(thread.Title)))
+	return err
+}
+
+func (f *TUseCase) DeleteThreadByID(ctx context.Context, id int, userID int) error {
+	logger.From(ctx, f.logger).Info("Удаление треда", zap.Int("id", id))
 
-	thread, err := f.repo.GetThreadByID(id)
+	thread, err := f.repo.GetThreadByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	valid, err := f.CheckUserByID(thread, userID)
-	if !valid || err != nil {
-		if !valid {
-			return fmt.Errorf("Нет прав доступа")
-		}
-		return fmt.Errorf("Нет прав доступа, тк ошибка")
+	if err := authorize(ctx, f.logger, f.repo, userID, authz.ThreadDeleteAny, authz.Resource{
+		OwnerID:    thread.UserID,
+		CategoryID: thread.CategoryID,
+	}); err != nil {
+		return err
 	}
 
-	if err := f.repo.DeleteThreadByID(id); err != nil {
-		logger.Logger.Error("Ошибка при удалении треда",
+	if err := f.repo.DeleteThreadByID(ctx, id, models.AuditEntry{
+		ActorID:    userID,
+		Action:     models.AuditThreadDelete,
+		TargetType: models.TargetThread,
+		TargetID:   id,
+		Before:     models.Snapshot(thread),
+	}); err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка при удалении треда",
 			zap.Int("id", id),
 			zap.Error(err))
 		return err
 	}
-	logger.Logger.Info("Тред успешно удален", zap.Int("id", id))
+	logger.From(ctx, f.logger).Info("Тред успешно удален", zap.Int("id", id))
 	return nil
 }
+
+func (f *TUseCase) SetThreadState(ctx context.Context, id int, state models.ThreadState, userID int) (models.Thread, error) {
+	logger.From(ctx, f.logger).Info("Изменение состояния треда",
+		zap.Int("id", id),
+		zap.Int("userID", userID))
+
+	if err := state.Validate(); err != nil {
+		return models.Thread{}, err
+	}
+
+	thread, err := f.repo.GetThreadByID(ctx, id)
+	if err != nil {
+		return models.Thread{}, err
+	}
+
+	if err := authorize(ctx, f.logger, f.repo, userID, authz.ThreadLock, authz.Resource{
+		OwnerID:    thread.UserID,
+		CategoryID: thread.CategoryID,
+	}); err != nil {
+		return models.Thread{}, err
+	}
+
+	before := models.Snapshot(thread)
+	thread.Apply(state)
+	if err := f.repo.UpdateThreadState(ctx, thread, models.AuditEntry{
+		ActorID:    userID,
+		Action:     models.AuditThreadState,
+		TargetType: models.TargetThread,
+		TargetID:   id,
+		Before:     before,
+		After:      models.Snapshot(thread),
+	}); err != nil {
+		logger.From(ctx, f.logger).Error("Ошибка при изменении состояния треда",
+			zap.Int("id", id),
+			zap.Error(err))
+		return models.Thread{}, err
+	}
+
+	logger.From(ctx, f.logger).Info("Состояние треда изменено",
+		zap.Int("id", id),
+		zap.Bool("pinned", thread.Pinned),
+		zap.Bool("locked", thread.Locked),
+		zap.Bool("archived", thread.Archived),
+		zap.Int("slowMode", thread.SlowMode))
+	return thread, nil
+}
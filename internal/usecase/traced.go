@@ -0,0 +1,404 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/automod"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"time"
+)
+
+var tracer = otel.Tracer("github.com/fire9900/forum/internal/usecase")
+
+// tracedAuditUseCase открывает спан AuditUseCase.<метод> на каждый вызов.
+type tracedAuditUseCase struct {
+	next AuditUseCase
+}
+
+// TraceAuditUseCase оборачивает u спанами трассировки.
+func TraceAuditUseCase(u AuditUseCase) AuditUseCase {
+	return &tracedAuditUseCase{next: u}
+}
+
+func (u *tracedAuditUseCase) GetAuditLog(ctx context.Context, filter models.AuditFilter, actorID int) (_ []models.AuditEntry, err error) {
+	ctx, span := tracer.Start(ctx, "AuditUseCase.GetAuditLog")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetAuditLog(ctx, filter, actorID)
+}
+
+// tracedAutomodUseCase открывает спан AutomodUseCase.<метод> на каждый вызов.
+type tracedAutomodUseCase struct {
+	next AutomodUseCase
+}
+
+// TraceAutomodUseCase оборачивает u спанами трассировки.
+func TraceAutomodUseCase(u AutomodUseCase) AutomodUseCase {
+	return &tracedAutomodUseCase{next: u}
+}
+
+func (u *tracedAutomodUseCase) GetConfig(ctx context.Context, actorID int) (_ automod.Config, err error) {
+	ctx, span := tracer.Start(ctx, "AutomodUseCase.GetConfig")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetConfig(ctx, actorID)
+}
+
+func (u *tracedAutomodUseCase) UpdateConfig(ctx context.Context, cfg automod.Config, actorID int) (_ automod.Config, err error) {
+	ctx, span := tracer.Start(ctx, "AutomodUseCase.UpdateConfig")
+	defer func() { tracing.End(span, err) }()
+	return u.next.UpdateConfig(ctx, cfg, actorID)
+}
+
+func (u *tracedAutomodUseCase) GetDuplicates(ctx context.Context, window time.Duration, actorID int) (_ []models.DuplicateCluster, err error) {
+	ctx, span := tracer.Start(ctx, "AutomodUseCase.GetDuplicates")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetDuplicates(ctx, window, actorID)
+}
+
+func (u *tracedAutomodUseCase) ExpireFingerprints(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "AutomodUseCase.ExpireFingerprints")
+	defer func() { tracing.End(span, err) }()
+	return u.next.ExpireFingerprints(ctx)
+}
+
+// tracedBanUseCase открывает спан BanUseCase.<метод> на каждый вызов.
+type tracedBanUseCase struct {
+	next BanUseCase
+}
+
+// TraceBanUseCase оборачивает u спанами трассировки.
+func TraceBanUseCase(u BanUseCase) BanUseCase {
+	return &tracedBanUseCase{next: u}
+}
+
+func (u *tracedBanUseCase) BanUser(ctx context.Context, ban models.Ban, actorID int) (_ models.Ban, err error) {
+	ctx, span := tracer.Start(ctx, "BanUseCase.BanUser")
+	defer func() { tracing.End(span, err) }()
+	return u.next.BanUser(ctx, ban, actorID)
+}
+
+func (u *tracedBanUseCase) GetBans(ctx context.Context, userID, actorID int) (_ []models.Ban, err error) {
+	ctx, span := tracer.Start(ctx, "BanUseCase.GetBans")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetBans(ctx, userID, actorID)
+}
+
+func (u *tracedBanUseCase) LiftBan(ctx context.Context, id, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "BanUseCase.LiftBan")
+	defer func() { tracing.End(span, err) }()
+	return u.next.LiftBan(ctx, id, actorID)
+}
+
+func (u *tracedBanUseCase) ExpireBans(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "BanUseCase.ExpireBans")
+	defer func() { tracing.End(span, err) }()
+	return u.next.ExpireBans(ctx)
+}
+
+// tracedModerationUseCase открывает спан ModerationUseCase.<метод> на каждый вызов.
+type tracedModerationUseCase struct {
+	next ModerationUseCase
+}
+
+// TraceModerationUseCase оборачивает u спанами трассировки.
+func TraceModerationUseCase(u ModerationUseCase) ModerationUseCase {
+	return &tracedModerationUseCase{next: u}
+}
+
+func (u *tracedModerationUseCase) GetCategories(ctx context.Context) (_ []models.Category, err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.GetCategories")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetCategories(ctx)
+}
+
+func (u *tracedModerationUseCase) CreateCategory(ctx context.Context, category models.Category, actorID int) (_ models.Category, err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.CreateCategory")
+	defer func() { tracing.End(span, err) }()
+	return u.next.CreateCategory(ctx, category, actorID)
+}
+
+func (u *tracedModerationUseCase) AddCategoryModerator(ctx context.Context, categoryID, userID, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.AddCategoryModerator")
+	defer func() { tracing.End(span, err) }()
+	return u.next.AddCategoryModerator(ctx, categoryID, userID, actorID)
+}
+
+func (u *tracedModerationUseCase) RemoveCategoryModerator(ctx context.Context, categoryID, userID, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.RemoveCategoryModerator")
+	defer func() { tracing.End(span, err) }()
+	return u.next.RemoveCategoryModerator(ctx, categoryID, userID, actorID)
+}
+
+func (u *tracedModerationUseCase) MergeThreads(ctx context.Context, fromID, toID, actorID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.MergeThreads")
+	defer func() { tracing.End(span, err) }()
+	return u.next.MergeThreads(ctx, fromID, toID, actorID)
+}
+
+func (u *tracedModerationUseCase) SplitThread(ctx context.Context, req models.SplitRequest, actorID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.SplitThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.SplitThread(ctx, req, actorID)
+}
+
+func (u *tracedModerationUseCase) MoveThread(ctx context.Context, threadID, categoryID, actorID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ModerationUseCase.MoveThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.MoveThread(ctx, threadID, categoryID, actorID)
+}
+
+// tracedPostUseCase открывает спан PostUseCase.<метод> на каждый вызов.
+type tracedPostUseCase struct {
+	next PostUseCase
+}
+
+// TracePostUseCase оборачивает u спанами трассировки.
+func TracePostUseCase(u PostUseCase) PostUseCase {
+	return &tracedPostUseCase{next: u}
+}
+
+func (u *tracedPostUseCase) CreatePost(ctx context.Context, post models.Post) (_ models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "PostUseCase.CreatePost")
+	defer func() { tracing.End(span, err) }()
+	return u.next.CreatePost(ctx, post)
+}
+
+func (u *tracedPostUseCase) GetChatPosts(ctx context.Context, threadID, viewerID int) (_ []models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "PostUseCase.GetChatPosts")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetChatPosts(ctx, threadID, viewerID)
+}
+
+func (u *tracedPostUseCase) GetPostByThreadID(ctx context.Context, threadID, viewerID int) (_ []models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "PostUseCase.GetPostByThreadID")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetPostByThreadID(ctx, threadID, viewerID)
+}
+
+func (u *tracedPostUseCase) DeletePostByID(ctx context.Context, id int, userID int) (err error) {
+	ctx, span := tracer.Start(ctx, "PostUseCase.DeletePostByID")
+	defer func() { tracing.End(span, err) }()
+	return u.next.DeletePostByID(ctx, id, userID)
+}
+
+func (u *tracedPostUseCase) GetPostsByUserID(ctx context.Context, id, viewerID int) (_ []models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "PostUseCase.GetPostsByUserID")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetPostsByUserID(ctx, id, viewerID)
+}
+
+// tracedPreferenceUseCase открывает спан PreferenceUseCase.<метод> на каждый вызов.
+type tracedPreferenceUseCase struct {
+	next PreferenceUseCase
+}
+
+// TracePreferenceUseCase оборачивает u спанами трассировки.
+func TracePreferenceUseCase(u PreferenceUseCase) PreferenceUseCase {
+	return &tracedPreferenceUseCase{next: u}
+}
+
+func (u *tracedPreferenceUseCase) GetLanguage(ctx context.Context, userID int) (_ string, err error) {
+	ctx, span := tracer.Start(ctx, "PreferenceUseCase.GetLanguage")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetLanguage(ctx, userID)
+}
+
+func (u *tracedPreferenceUseCase) SetLanguage(ctx context.Context, userID int, language string) (err error) {
+	ctx, span := tracer.Start(ctx, "PreferenceUseCase.SetLanguage")
+	defer func() { tracing.End(span, err) }()
+	return u.next.SetLanguage(ctx, userID, language)
+}
+
+// tracedPremodUseCase открывает спан PremodUseCase.<метод> на каждый вызов.
+type tracedPremodUseCase struct {
+	next PremodUseCase
+}
+
+// TracePremodUseCase оборачивает u спанами трассировки.
+func TracePremodUseCase(u PremodUseCase) PremodUseCase {
+	return &tracedPremodUseCase{next: u}
+}
+
+func (u *tracedPremodUseCase) GetQueue(ctx context.Context, actorID int) (_ models.PendingQueue, err error) {
+	ctx, span := tracer.Start(ctx, "PremodUseCase.GetQueue")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetQueue(ctx, actorID)
+}
+
+func (u *tracedPremodUseCase) ApprovePost(ctx context.Context, id, actorID int) (_ models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "PremodUseCase.ApprovePost")
+	defer func() { tracing.End(span, err) }()
+	return u.next.ApprovePost(ctx, id, actorID)
+}
+
+func (u *tracedPremodUseCase) RejectPost(ctx context.Context, id int, reason string, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "PremodUseCase.RejectPost")
+	defer func() { tracing.End(span, err) }()
+	return u.next.RejectPost(ctx, id, reason, actorID)
+}
+
+func (u *tracedPremodUseCase) ApproveThread(ctx context.Context, id, actorID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "PremodUseCase.ApproveThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.ApproveThread(ctx, id, actorID)
+}
+
+func (u *tracedPremodUseCase) RejectThread(ctx context.Context, id int, reason string, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "PremodUseCase.RejectThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.RejectThread(ctx, id, reason, actorID)
+}
+
+// tracedReportUseCase открывает спан ReportUseCase.<метод> на каждый вызов.
+type tracedReportUseCase struct {
+	next ReportUseCase
+}
+
+// TraceReportUseCase оборачивает u спанами трассировки.
+func TraceReportUseCase(u ReportUseCase) ReportUseCase {
+	return &tracedReportUseCase{next: u}
+}
+
+func (u *tracedReportUseCase) CreateReport(ctx context.Context, report models.Report) (_ models.Report, err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.CreateReport")
+	defer func() { tracing.End(span, err) }()
+	return u.next.CreateReport(ctx, report)
+}
+
+func (u *tracedReportUseCase) GetReports(ctx context.Context, filter models.ReportFilter, actorID int) (_ []models.Report, err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.GetReports")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetReports(ctx, filter, actorID)
+}
+
+func (u *tracedReportUseCase) ResolveReport(ctx context.Context, id int, status, resolution string, actorID int) (_ models.Report, err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.ResolveReport")
+	defer func() { tracing.End(span, err) }()
+	return u.next.ResolveReport(ctx, id, status, resolution, actorID)
+}
+
+func (u *tracedReportUseCase) BulkAction(ctx context.Context, ids []int, action, resolution string, actorID int) (_ []models.BulkReportResult, err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.BulkAction")
+	defer func() { tracing.End(span, err) }()
+	return u.next.BulkAction(ctx, ids, action, resolution, actorID)
+}
+
+func (u *tracedReportUseCase) GetNotifications(ctx context.Context, userID int) (_ []models.Notification, err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.GetNotifications")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetNotifications(ctx, userID)
+}
+
+func (u *tracedReportUseCase) MarkNotificationRead(ctx context.Context, id, userID int) (err error) {
+	ctx, span := tracer.Start(ctx, "ReportUseCase.MarkNotificationRead")
+	defer func() { tracing.End(span, err) }()
+	return u.next.MarkNotificationRead(ctx, id, userID)
+}
+
+// tracedSpamUseCase открывает спан SpamUseCase.<метод> на каждый вызов.
+type tracedSpamUseCase struct {
+	next SpamUseCase
+}
+
+// TraceSpamUseCase оборачивает u спанами трассировки.
+func TraceSpamUseCase(u SpamUseCase) SpamUseCase {
+	return &tracedSpamUseCase{next: u}
+}
+
+func (u *tracedSpamUseCase) MarkPost(ctx context.Context, postID int, label string, actorID int) (err error) {
+	ctx, span := tracer.Start(ctx, "SpamUseCase.MarkPost")
+	defer func() { tracing.End(span, err) }()
+	return u.next.MarkPost(ctx, postID, label, actorID)
+}
+
+func (u *tracedSpamUseCase) GetScores(ctx context.Context, min float64, actorID int) (_ []models.SpamScore, err error) {
+	ctx, span := tracer.Start(ctx, "SpamUseCase.GetScores")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetScores(ctx, min, actorID)
+}
+
+// tracedThreadUseCase открывает спан ThreadUseCase.<метод> на каждый вызов.
+type tracedThreadUseCase struct {
+	next ThreadUseCase
+}
+
+// TraceThreadUseCase оборачивает u спанами трассировки.
+func TraceThreadUseCase(u ThreadUseCase) ThreadUseCase {
+	return &tracedThreadUseCase{next: u}
+}
+
+func (u *tracedThreadUseCase) GetUserThreads(ctx context.Context, userId, viewerID int) (_ []models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.GetUserThreads")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetUserThreads(ctx, userId, viewerID)
+}
+
+func (u *tracedThreadUseCase) GetAllThreads(ctx context.Context, viewerID int) (_ []models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.GetAllThreads")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetAllThreads(ctx, viewerID)
+}
+
+func (u *tracedThreadUseCase) GetThreadByID(ctx context.Context, id, viewerID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.GetThreadByID")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetThreadByID(ctx, id, viewerID)
+}
+
+func (u *tracedThreadUseCase) CreateThread(ctx context.Context, thread models.Thread) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.CreateThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.CreateThread(ctx, thread)
+}
+
+func (u *tracedThreadUseCase) DeleteThreadByID(ctx context.Context, id int, userID int) (err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.DeleteThreadByID")
+	defer func() { tracing.End(span, err) }()
+	return u.next.DeleteThreadByID(ctx, id, userID)
+}
+
+func (u *tracedThreadUseCase) EditThread(ctx context.Context, thread models.Thread, userID int) (err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.EditThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.EditThread(ctx, thread, userID)
+}
+
+func (u *tracedThreadUseCase) SetThreadState(ctx context.Context, id int, state models.ThreadState, userID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "ThreadUseCase.SetThreadState")
+	defer func() { tracing.End(span, err) }()
+	return u.next.SetThreadState(ctx, id, state, userID)
+}
+
+// tracedTrashUseCase открывает спан TrashUseCase.<метод> на каждый вызов.
+type tracedTrashUseCase struct {
+	next TrashUseCase
+}
+
+// TraceTrashUseCase оборачивает u спанами трассировки.
+func TraceTrashUseCase(u TrashUseCase) TrashUseCase {
+	return &tracedTrashUseCase{next: u}
+}
+
+func (u *tracedTrashUseCase) GetTrash(ctx context.Context, actorID int) (_ models.Trash, err error) {
+	ctx, span := tracer.Start(ctx, "TrashUseCase.GetTrash")
+	defer func() { tracing.End(span, err) }()
+	return u.next.GetTrash(ctx, actorID)
+}
+
+func (u *tracedTrashUseCase) RestoreThread(ctx context.Context, id, actorID int) (_ models.Thread, err error) {
+	ctx, span := tracer.Start(ctx, "TrashUseCase.RestoreThread")
+	defer func() { tracing.End(span, err) }()
+	return u.next.RestoreThread(ctx, id, actorID)
+}
+
+func (u *tracedTrashUseCase) RestorePost(ctx context.Context, id, actorID int) (_ models.Post, err error) {
+	ctx, span := tracer.Start(ctx, "TrashUseCase.RestorePost")
+	defer func() { tracing.End(span, err) }()
+	return u.next.RestorePost(ctx, id, actorID)
+}
+
+func (u *tracedTrashUseCase) PurgeTrash(ctx context.Context) (_ int64, err error) {
+	ctx, span := tracer.Start(ctx, "TrashUseCase.PurgeTrash")
+	defer func() { tracing.End(span, err) }()
+	return u.next.PurgeTrash(ctx)
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/internal/authz"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/pkg/logger"
+	"go.uber.org/zap"
+	"time"
+)
+
+// trashLimit - сколько тредов и постов корзины отдается за один запрос.
+const trashLimit = 100
+
+type TrashUseCase interface {
+	GetTrash(ctx context.Context, actorID int) (models.Trash, error)
+	RestoreThread(ctx context.Context, id, actorID int) (models.Thread, error)
+	RestorePost(ctx context.Context, id, actorID int) (models.Post, error)
+	PurgeTrash(ctx context.Context) (int64, error)
+}
+
+type DUseCase struct {
+	repo      repository.ForumRepository
+	logger    *zap.Logger
+	retention time.Duration
+}
+
+// NewTrashUseCase создает корзину, из которой удаленные сообщения
+// окончательно стираются через retention.
+func NewTrashUseCase(repo repository.ForumRepository, retention time.Duration, logger *zap.Logger) TrashUseCase {
+	return &DUseCase{repo: repo, logger: logger, retention: retention}
+}
+
+// GetTrash возвращает корзину, доступную пользователю: модераторам форума -
+// целиком, остальным - собственные сообщения и сообщения в категориях,
+// которые они модерируют.
+func (f *DUseCase) GetTrash(ctx context.Context, actorID int) (models.Trash, error) {
+	actor, err := f.repo.GetActor(ctx, actorID)
+	if err != nil {
+		return models.Trash{}, fmt.Errorf("%w: %v", models.ErrorForbidden, err)
+	}
+
+	return f.repo.GetTrash(ctx, models.TrashFilter{
+		All:        authz.HasPermission(actor.Role, authz.PostDeleteAny),
+		OwnerID:    actor.ID,
+		Categories: actor.Categories,
+		Limit:      trashLimit,
+	})
+}
+
+// RestoreThread возвращает тред из корзины. Автор может восстановить тред,
+// только если сам его удалил.
+func (f *DUseCase) RestoreThread(ctx context.Context, id, actorID int) (models.Thread, error) {
+	thread, err := f.repo.GetDeletedThread(ctx, id)
+	if err != nil {
+		return models.Thread{}, err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.ThreadDeleteAny,
+		restoreResource(thread.UserID, thread.DeletedBy, thread.CategoryID, actorID)); err != nil {
+		return models.Thread{}, err
+	}
+
+	before := models.Snapshot(thread)
+	thread.DeletedAt, thread.DeletedBy = nil, 0
+	if err := f.repo.RestoreThread(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditThreadRestore,
+		TargetType: models.TargetThread,
+		TargetID:   id,
+		Before:     before,
+		After:      models.Snapshot(thread),
+	}); err != nil {
+		return models.Thread{}, err
+	}
+
+	logger.From(ctx, f.logger).Info("Тред восстановлен", zap.Int("id", id), zap.Int("actorID", actorID))
+	return thread, nil
+}
+
+// RestorePost возвращает пост из корзины. Тред поста должен существовать:
+// пост удаленного треда восстанавливается вместе с тредом.
+func (f *DUseCase) RestorePost(ctx context.Context, id, actorID int) (models.Post, error) {
+	post, err := f.repo.GetDeletedPost(ctx, id)
+	if err != nil {
+		return models.Post{}, err
+	}
+	thread, err := f.repo.GetThreadByID(ctx, post.ThreadID)
+	if err != nil {
+		return models.Post{}, err
+	}
+	if err := authorize(ctx, f.logger, f.repo, actorID, authz.PostDeleteAny,
+		restoreResource(post.UserID, post.DeletedBy, thread.CategoryID, actorID)); err != nil {
+		return models.Post{}, err
+	}
+
+	before := models.Snapshot(post)
+	post.DeletedAt, post.DeletedBy = nil, 0
+	if err := f.repo.RestorePost(ctx, id, models.AuditEntry{
+		ActorID:    actorID,
+		Action:     models.AuditPostRestore,
+		TargetType: models.TargetPost,
+		TargetID:   id,
+		Before:     before,
+		After:      models.Snapshot(post),
+	}); err != nil {
+		return models.Post{}, err
+	}
+
+	logger.From(ctx, f.logger).Info("Пост восстановлен", zap.Int("id", id), zap.Int("actorID", actorID))
+	return post, nil
+}
+
+// restoreResource описывает удаленное сообщение для проверки прав. Право
+// автора учитывается, только если сообщение удалил он сам: удаленное
+// модератором может вернуть лишь модератор.
+func restoreResource(ownerID, deletedBy, categoryID, actorID int) authz.Resource {
+	resource := authz.Resource{CategoryID: categoryID}
+	if deletedBy == actorID {
+		resource.OwnerID = ownerID
+	}
+	return resource
+}
+
+// PurgeTrash окончательно удаляет сообщения, пролежавшие в корзине дольше
+// срока хранения.
+func (f *DUseCase) PurgeTrash(ctx context.Context) (int64, error) {
+	purged, err := f.repo.PurgeDeleted(ctx, time.Now().Add(-f.retention))
+	if err != nil {
+		return 0, err
+	}
+	if purged > 0 {
+		logger.From(ctx, f.logger).Info("Корзина очищена", zap.Int64("count", purged))
+	}
+	return purged, nil
+}
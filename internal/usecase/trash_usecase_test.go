@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRestorePost(t *testing.T) {
+	deletedAt := time.Now()
+	thread := models.Thread{ID: 2, CategoryID: 3}
+
+	t.Run("author restores own deletion", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetDeletedPost", mock.Anything, 7).
+			Return(models.Post{ID: 7, ThreadID: 2, UserID: 5, DeletedAt: &deletedAt, DeletedBy: 5}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser}, nil).Once()
+		mockRepo.On("RestorePost", mock.Anything, 7, mock.MatchedBy(func(e models.AuditEntry) bool {
+			return e.Action == models.AuditPostRestore && e.ActorID == 5 && len(e.Before) > 0
+		})).Return(nil).Once()
+
+		u := NewTrashUseCase(mockRepo, time.Hour, zap.NewNop())
+		post, err := u.RestorePost(context.Background(), 7, 5)
+
+		assert.NoError(t, err)
+		assert.Nil(t, post.DeletedAt)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("author cannot restore moderator deletion", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetDeletedPost", mock.Anything, 7).
+			Return(models.Post{ID: 7, ThreadID: 2, UserID: 5, DeletedAt: &deletedAt, DeletedBy: 1}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(thread, nil).Once()
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser}, nil).Once()
+
+		u := NewTrashUseCase(mockRepo, time.Hour, zap.NewNop())
+		_, err := u.RestorePost(context.Background(), 7, 5)
+
+		assert.ErrorIs(t, err, models.ErrorForbidden)
+		mockRepo.AssertNumberOfCalls(t, "RestorePost", 0)
+	})
+
+	t.Run("thread is deleted", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetDeletedPost", mock.Anything, 7).
+			Return(models.Post{ID: 7, ThreadID: 2, UserID: 5, DeletedAt: &deletedAt, DeletedBy: 5}, nil).Once()
+		mockRepo.On("GetThreadByID", mock.Anything, 2).Return(models.Thread{}, models.ErrorNotFoundThread).Once()
+
+		u := NewTrashUseCase(mockRepo, time.Hour, zap.NewNop())
+		_, err := u.RestorePost(context.Background(), 7, 5)
+
+		assert.ErrorIs(t, err, models.ErrorNotFoundThread)
+		mockRepo.AssertNumberOfCalls(t, "RestorePost", 0)
+	})
+}
+
+func TestGetTrash(t *testing.T) {
+	t.Run("moderator sees everything", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 1).Return(models.Actor{ID: 1, Role: models.RoleModerator}, nil).Once()
+		mockRepo.On("GetTrash", mock.Anything, mock.MatchedBy(func(f models.TrashFilter) bool { return f.All })).
+			Return(models.Trash{}, nil).Once()
+
+		_, err := NewTrashUseCase(mockRepo, time.Hour, zap.NewNop()).GetTrash(context.Background(), 1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("user sees own and moderated categories", func(t *testing.T) {
+		mockRepo := new(mocks.ForumRepository)
+		mockRepo.On("GetActor", mock.Anything, 5).Return(models.Actor{ID: 5, Role: models.RoleUser, Categories: []int{3}}, nil).Once()
+		mockRepo.On("GetTrash", mock.Anything, models.TrashFilter{OwnerID: 5, Categories: []int{3}, Limit: trashLimit}).
+			Return(models.Trash{}, nil).Once()
+
+		_, err := NewTrashUseCase(mockRepo, time.Hour, zap.NewNop()).GetTrash(context.Background(), 5)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
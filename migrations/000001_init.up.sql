@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS threads
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    title     TEXT     NOT NULL,
+    content   TEXT     NOT NULL,
+    create_at DATETIME NOT NULL,
+    user_id   INTEGER  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS posts
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    content   TEXT     NOT NULL,
+    create_at DATETIME NOT NULL,
+    thread_id INTEGER  NOT NULL,
+    user_id   INTEGER  NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS chat
+(
+    thread_id INTEGER NOT NULL,
+    user_id   INTEGER NOT NULL,
+    post_id   INTEGER NOT NULL
+);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_threads_pinned_create_at;
+
+ALTER TABLE threads DROP COLUMN archived;
+ALTER TABLE threads DROP COLUMN locked;
+ALTER TABLE threads DROP COLUMN pinned;
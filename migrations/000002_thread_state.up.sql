@@ -0,0 +1,5 @@
+ALTER TABLE threads ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE threads ADD COLUMN locked INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE threads ADD COLUMN archived INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_threads_pinned_create_at ON threads (pinned DESC, create_at DESC);
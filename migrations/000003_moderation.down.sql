@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_audit_log_target;
+DROP TABLE IF EXISTS audit_log;
+
+DROP TABLE IF EXISTS thread_redirects;
+
+DROP INDEX IF EXISTS idx_threads_category_id;
+ALTER TABLE threads DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS categories
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    name        TEXT     NOT NULL UNIQUE,
+    description TEXT     NOT NULL DEFAULT '',
+    create_at   DATETIME NOT NULL
+);
+
+ALTER TABLE threads ADD COLUMN category_id INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_threads_category_id ON threads (category_id);
+
+CREATE TABLE IF NOT EXISTS thread_redirects
+(
+    old_id    INTEGER PRIMARY KEY,
+    new_id    INTEGER  NOT NULL,
+    create_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS audit_log
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id    INTEGER  NOT NULL,
+    action      TEXT     NOT NULL,
+    target_type TEXT     NOT NULL,
+    target_id   INTEGER  NOT NULL,
+    details     TEXT     NOT NULL DEFAULT '',
+    create_at   DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_type, target_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_notifications_user_id;
+DROP TABLE IF EXISTS notifications;
+
+DROP INDEX IF EXISTS idx_reports_target;
+DROP INDEX IF EXISTS idx_reports_status;
+DROP TABLE IF EXISTS reports;
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS reports
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    reporter_id  INTEGER  NOT NULL,
+    target_type  TEXT     NOT NULL,
+    target_id    INTEGER  NOT NULL,
+    reason       TEXT     NOT NULL,
+    status       TEXT     NOT NULL DEFAULT 'open',
+    moderator_id INTEGER  NOT NULL DEFAULT 0,
+    resolution   TEXT     NOT NULL DEFAULT '',
+    create_at    DATETIME NOT NULL,
+    resolved_at  DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_reports_status ON reports (status, create_at);
+CREATE INDEX IF NOT EXISTS idx_reports_target ON reports (target_type, target_id);
+
+CREATE TABLE IF NOT EXISTS notifications
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id   INTEGER  NOT NULL,
+    message   TEXT     NOT NULL,
+    is_read   INTEGER  NOT NULL DEFAULT 0,
+    create_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, create_at);
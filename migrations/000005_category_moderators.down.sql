@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_category_moderators_user_id;
+DROP TABLE IF EXISTS category_moderators;
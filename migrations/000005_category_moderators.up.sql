@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS category_moderators
+(
+    category_id INTEGER  NOT NULL,
+    user_id     INTEGER  NOT NULL,
+    create_at   DATETIME NOT NULL,
+    PRIMARY KEY (category_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_category_moderators_user_id ON category_moderators (user_id);
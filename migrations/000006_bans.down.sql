@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_bans_expires_at;
+DROP INDEX IF EXISTS idx_bans_user_id;
+DROP TABLE IF EXISTS bans;
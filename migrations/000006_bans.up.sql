@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS bans
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER  NOT NULL,
+    category_id  INTEGER  NOT NULL DEFAULT 0,
+    shadow       INTEGER  NOT NULL DEFAULT 0,
+    reason       TEXT     NOT NULL DEFAULT '',
+    moderator_id INTEGER  NOT NULL,
+    create_at    DATETIME NOT NULL,
+    expires_at   DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_bans_user_id ON bans (user_id);
+CREATE INDEX IF NOT EXISTS idx_bans_expires_at ON bans (expires_at);
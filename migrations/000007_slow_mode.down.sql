@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_thread_user_create_at;
+
+ALTER TABLE threads DROP COLUMN slow_mode;
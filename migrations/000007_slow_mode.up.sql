@@ -0,0 +1,3 @@
+ALTER TABLE threads ADD COLUMN slow_mode INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_thread_user_create_at ON posts (thread_id, user_id, create_at DESC);
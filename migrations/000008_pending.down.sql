@@ -0,0 +1,2 @@
+ALTER TABLE posts DROP COLUMN pending;
+ALTER TABLE threads DROP COLUMN pending;
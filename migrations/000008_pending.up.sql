@@ -0,0 +1,2 @@
+ALTER TABLE threads ADD COLUMN pending INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN pending INTEGER NOT NULL DEFAULT 0;
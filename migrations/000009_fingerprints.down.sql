@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_fingerprints_create_at;
+DROP TABLE IF EXISTS fingerprints;
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS fingerprints
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    target_type TEXT     NOT NULL,
+    target_id   INTEGER  NOT NULL,
+    user_id     INTEGER  NOT NULL,
+    hash        INTEGER  NOT NULL,
+    create_at   DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_fingerprints_create_at ON fingerprints (create_at);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_spam_scores_score;
+DROP TABLE IF EXISTS spam_scores;
+DROP TABLE IF EXISTS spam_tokens;
+DROP TABLE IF EXISTS spam_feedback;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS spam_feedback
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    post_id      INTEGER  NOT NULL UNIQUE,
+    label        TEXT     NOT NULL,
+    moderator_id INTEGER  NOT NULL,
+    create_at    DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS spam_tokens
+(
+    token TEXT PRIMARY KEY,
+    spam  INTEGER NOT NULL DEFAULT 0,
+    ham   INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE TABLE IF NOT EXISTS spam_scores
+(
+    post_id   INTEGER PRIMARY KEY,
+    score     REAL     NOT NULL,
+    create_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_spam_scores_score ON spam_scores (score);
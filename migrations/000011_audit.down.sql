@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS audit_log_no_delete;
+DROP TRIGGER IF EXISTS audit_log_no_update;
+DROP INDEX IF EXISTS idx_audit_log_actor_id;
+DROP INDEX IF EXISTS idx_audit_log_create_at;
+ALTER TABLE audit_log DROP COLUMN snapshot_after;
+ALTER TABLE audit_log DROP COLUMN snapshot_before;
+ALTER TABLE audit_log DROP COLUMN reason;
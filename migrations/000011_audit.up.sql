@@ -0,0 +1,20 @@
+ALTER TABLE audit_log ADD COLUMN reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN snapshot_before TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_log ADD COLUMN snapshot_after TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_create_at ON audit_log (create_at);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor_id ON audit_log (actor_id);
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update
+    BEFORE UPDATE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete
+    BEFORE DELETE
+    ON audit_log
+BEGIN
+    SELECT RAISE(ABORT, 'audit_log is append-only');
+END;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_posts_deleted_at;
+DROP INDEX IF EXISTS idx_threads_deleted_at;
+ALTER TABLE posts DROP COLUMN deleted_by;
+ALTER TABLE posts DROP COLUMN deleted_at;
+ALTER TABLE threads DROP COLUMN deleted_by;
+ALTER TABLE threads DROP COLUMN deleted_at;
@@ -0,0 +1,7 @@
+ALTER TABLE threads ADD COLUMN deleted_at DATETIME;
+ALTER TABLE threads ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN deleted_at DATETIME;
+ALTER TABLE posts ADD COLUMN deleted_by INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_threads_deleted_at ON threads (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
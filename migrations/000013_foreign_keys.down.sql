@@ -0,0 +1,37 @@
+CREATE TABLE chat_old
+(
+    thread_id INTEGER NOT NULL,
+    user_id   INTEGER NOT NULL,
+    post_id   INTEGER NOT NULL
+);
+
+INSERT INTO chat_old (thread_id, user_id, post_id)
+SELECT thread_id, user_id, post_id
+FROM chat;
+
+DROP TABLE chat;
+ALTER TABLE chat_old RENAME TO chat;
+
+CREATE TABLE posts_old
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    content    TEXT     NOT NULL,
+    create_at  DATETIME NOT NULL,
+    thread_id  INTEGER  NOT NULL,
+    user_id    INTEGER  NOT NULL,
+    pending    INTEGER  NOT NULL DEFAULT 0,
+    deleted_at DATETIME,
+    deleted_by INTEGER  NOT NULL DEFAULT 0
+);
+
+INSERT INTO posts_old (id, content, create_at, thread_id, user_id, pending, deleted_at, deleted_by)
+SELECT id, content, create_at, thread_id, user_id, pending, deleted_at, deleted_by
+FROM posts;
+
+DELETE FROM sqlite_sequence WHERE name = 'posts_old';
+UPDATE sqlite_sequence SET name = 'posts_old' WHERE name = 'posts';
+DROP TABLE posts;
+ALTER TABLE posts_old RENAME TO posts;
+
+CREATE INDEX IF NOT EXISTS idx_posts_thread_user_create_at ON posts (thread_id, user_id, create_at DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
@@ -0,0 +1,40 @@
+CREATE TABLE posts_new
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    content    TEXT     NOT NULL,
+    create_at  DATETIME NOT NULL,
+    thread_id  INTEGER  NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    user_id    INTEGER  NOT NULL,
+    pending    INTEGER  NOT NULL DEFAULT 0,
+    deleted_at DATETIME,
+    deleted_by INTEGER  NOT NULL DEFAULT 0
+);
+
+INSERT INTO posts_new (id, content, create_at, thread_id, user_id, pending, deleted_at, deleted_by)
+SELECT id, content, create_at, thread_id, user_id, pending, deleted_at, deleted_by
+FROM posts;
+
+DELETE FROM sqlite_sequence WHERE name = 'posts_new';
+UPDATE sqlite_sequence SET name = 'posts_new' WHERE name = 'posts';
+DROP TABLE posts;
+ALTER TABLE posts_new RENAME TO posts;
+
+CREATE INDEX IF NOT EXISTS idx_posts_thread_user_create_at ON posts (thread_id, user_id, create_at DESC);
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts (deleted_at);
+
+CREATE TABLE chat_new
+(
+    thread_id INTEGER NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    user_id   INTEGER NOT NULL,
+    post_id   INTEGER NOT NULL REFERENCES posts (id) ON DELETE CASCADE
+);
+
+INSERT INTO chat_new (thread_id, user_id, post_id)
+SELECT thread_id, user_id, post_id
+FROM chat;
+
+DROP TABLE chat;
+ALTER TABLE chat_new RENAME TO chat;
+
+CREATE INDEX IF NOT EXISTS idx_chat_thread_id ON chat (thread_id);
+CREATE INDEX IF NOT EXISTS idx_chat_post_id ON chat (post_id);
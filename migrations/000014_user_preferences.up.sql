@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS user_preferences
+(
+    user_id  INTEGER PRIMARY KEY,
+    language TEXT NOT NULL
+);
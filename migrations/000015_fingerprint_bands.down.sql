@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_fingerprints_user_hash;
+DROP INDEX IF EXISTS idx_fingerprints_band3;
+DROP INDEX IF EXISTS idx_fingerprints_band2;
+DROP INDEX IF EXISTS idx_fingerprints_band1;
+DROP INDEX IF EXISTS idx_fingerprints_band0;
+ALTER TABLE fingerprints DROP COLUMN band3;
+ALTER TABLE fingerprints DROP COLUMN band2;
+ALTER TABLE fingerprints DROP COLUMN band1;
+ALTER TABLE fingerprints DROP COLUMN band0;
@@ -0,0 +1,16 @@
+ALTER TABLE fingerprints ADD COLUMN band0 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE fingerprints ADD COLUMN band1 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE fingerprints ADD COLUMN band2 INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE fingerprints ADD COLUMN band3 INTEGER NOT NULL DEFAULT 0;
+
+UPDATE fingerprints
+SET band0 = hash & 65535,
+    band1 = (hash >> 16) & 65535,
+    band2 = (hash >> 32) & 65535,
+    band3 = (hash >> 48) & 65535;
+
+CREATE INDEX IF NOT EXISTS idx_fingerprints_band0 ON fingerprints (band0, create_at);
+CREATE INDEX IF NOT EXISTS idx_fingerprints_band1 ON fingerprints (band1, create_at);
+CREATE INDEX IF NOT EXISTS idx_fingerprints_band2 ON fingerprints (band2, create_at);
+CREATE INDEX IF NOT EXISTS idx_fingerprints_band3 ON fingerprints (band3, create_at);
+CREATE INDEX IF NOT EXISTS idx_fingerprints_user_hash ON fingerprints (user_id, hash, create_at);
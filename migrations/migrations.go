@@ -0,0 +1,10 @@
+// Package migrations встраивает SQL-миграции базы форума в бинарник,
+// чтобы они не зависели от рабочего каталога процесса.
+package migrations
+
+import "embed"
+
+// FS содержит файлы миграций в формате golang-migrate.
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/fire9900/forum/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"io/fs"
+)
+
+// openMigrations открывает встроенные в бинарник миграции, поэтому сервис
+// и его команды работают из любого рабочего каталога.
+func openMigrations() (source.Driver, error) {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, fmt.Errorf("(Forum) ошибка чтения миграций: %w", err)
+	}
+	return src, nil
+}
+
+// RunMigrations накатывает на базу все миграции из пакета migrations.
+// Соединение db не закрывается: оно продолжает использоваться приложением.
+//
+// Миграции, пересобирающие таблицы, нельзя выполнять при включенных внешних
+// ключах, а PRAGMA foreign_keys не действует внутри транзакции миграции.
+// Поэтому на время миграций пул сжимается до одного соединения, на котором
+// внешние ключи выключены.
+func RunMigrations(db *sql.DB) error {
+	maxOpen := db.Stats().MaxOpenConnections
+	db.SetMaxOpenConns(1)
+	defer db.SetMaxOpenConns(maxOpen)
+
+	if _, err := db.Exec(`PRAGMA foreign_keys = OFF`); err != nil {
+		return fmt.Errorf("(Forum) ошибка отключения внешних ключей: %w", err)
+	}
+
+	if err := migrateUp(db); err != nil {
+		db.Exec(`PRAGMA foreign_keys = ON`)
+		return err
+	}
+
+	if _, err := db.Exec(`PRAGMA foreign_keys = ON`); err != nil {
+		return fmt.Errorf("(Forum) ошибка включения внешних ключей: %w", err)
+	}
+	return nil
+}
+
+func migrateUp(db *sql.DB) error {
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{})
+	if err != nil {
+		return fmt.Errorf("(Forum) ошибка инициализации драйвера миграций: %w", err)
+	}
+
+	src, err := openMigrations()
+	if err != nil {
+		return err
+	}
+	m, err := migrate.NewWithInstance("iofs", src, "sqlite", driver)
+	if err != nil {
+		return fmt.Errorf("(Forum) ошибка чтения миграций: %w", err)
+	}
+
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("(Forum) ошибка применения миграций: %w", err)
+	}
+	return nil
+}
+
+// LatestMigration возвращает номер последней миграции в пакете migrations.
+func LatestMigration() (uint, error) {
+	src, err := openMigrations()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("(Forum) ошибка чтения миграций: %w", err)
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("(Forum) ошибка чтения миграций: %w", err)
+		}
+		version = next
+	}
+}
+
+// CheckMigrations проверяет, что база находится на версии latest и последняя
+// миграция не прервалась.
+func CheckMigrations(ctx context.Context, db *sql.DB, latest uint) error {
+	var version uint
+	var dirty bool
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("миграции не применены, ожидается версия %d", latest)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка чтения версии схемы: %w", err)
+	}
+	if dirty {
+		return fmt.Errorf("миграция %d прервана", version)
+	}
+	if version != latest {
+		return fmt.Errorf("версия схемы %d, ожидается %d", version, latest)
+	}
+	return nil
+}
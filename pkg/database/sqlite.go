@@ -3,17 +3,19 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/mattn/go-sqlite3"
 	_ "modernc.org/sqlite"
 )
 
-func NewSQLiteConnection() (*sql.DB, error) {
-	db, err := sql.Open("sqlite", "../data.db")
+// NewSQLiteConnection открывает файл базы форума path. Внешние ключи в SQLite
+// выключены по умолчанию и включаются для каждого соединения отдельно,
+// поэтому прагма передается в строке подключения.
+func NewSQLiteConnection(path string, maxOpenConns int) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, fmt.Errorf("(Forum) ошибка подключения к SQLite: %w", err)
 	}
-	db.SetMaxOpenConns(1000)
+	db.SetMaxOpenConns(maxOpenConns)
 
 	if err = db.Ping(); err != nil {
 		db.Close()
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReady(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("hub", func(context.Context) error { return nil })
+
+	report := c.Ready(context.Background())
+	if !report.Ready() || len(report.Checks) != 2 {
+		t.Fatalf("все проверки прошли, ожидалась готовность: %+v", report)
+	}
+	if report.Checks["database"].Status != StatusOK {
+		t.Errorf("ожидался статус ok, получено %+v", report.Checks["database"])
+	}
+}
+
+func TestNotReady(t *testing.T) {
+	c := NewChecker(10 * time.Millisecond)
+	c.Add("database", func(context.Context) error { return nil })
+	c.Add("auth", func(context.Context) error { return errors.New("connection refused") })
+	c.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	report := c.Ready(context.Background())
+	if report.Status != StatusNotReady {
+		t.Fatalf("ожидался статус not_ready, получено %q", report.Status)
+	}
+	if got := report.Checks["auth"]; got.Status != StatusFail || got.Error != "connection refused" {
+		t.Errorf("ошибка зависимости не попала в отчет: %+v", got)
+	}
+	if got := report.Checks["slow"]; got.Status != StatusFail {
+		t.Errorf("зависшая проверка должна прерываться по таймауту: %+v", got)
+	}
+	if report.Checks["database"].Status != StatusOK {
+		t.Errorf("исправная зависимость должна оставаться ok: %+v", report.Checks["database"])
+	}
+}
+
+func TestShuttingDown(t *testing.T) {
+	c := NewChecker(time.Second)
+	c.Add("database", func(context.Context) error { return nil })
+	c.SetShuttingDown()
+
+	report := c.Ready(context.Background())
+	if report.Ready() || report.Status != StatusShuttingDown {
+		t.Errorf("во время остановки экземпляр не должен быть готов: %+v", report)
+	}
+	if report.Checks["database"].Status != StatusOK {
+		t.Errorf("детали проверок должны сохраняться: %+v", report.Checks)
+	}
+}
@@ -0,0 +1,190 @@
+// Package i18n переводит сообщения сервиса по каталогам locales/<язык>.json.
+// Каталог разбит на разделы: errors - тексты ошибок по их кодам, details -
+// пояснения к полям по кодам ошибок, messages - прочие ответы клиенту,
+// log - записи лога, где ключом служит исходный текст записи. Исходные
+// тексты написаны по-русски, поэтому каталог ru не содержит details и log.
+// Если перевода нет, используется исходный текст.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// catalogs - переводы по языкам: ключ вида раздел.сообщение.
+var catalogs = mustLoad()
+
+func mustLoad() map[string]map[string]string {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+
+	result := make(map[string]map[string]string, len(files))
+	for _, file := range files {
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		var sections map[string]any
+		if err := json.Unmarshal(data, &sections); err != nil {
+			panic(fmt.Sprintf("каталог %s: %v", file.Name(), err))
+		}
+
+		catalog := make(map[string]string)
+		if err := flatten(catalog, "", sections); err != nil {
+			panic(fmt.Sprintf("каталог %s: %v", file.Name(), err))
+		}
+		result[strings.TrimSuffix(file.Name(), ".json")] = catalog
+	}
+	return result
+}
+
+// flatten раскладывает вложенные разделы каталога в ключи вида
+// раздел.подраздел.сообщение.
+func flatten(catalog map[string]string, prefix string, node map[string]any) error {
+	for key, value := range node {
+		switch value := value.(type) {
+		case string:
+			catalog[prefix+key] = value
+		case map[string]any:
+			if err := flatten(catalog, prefix+key+".", value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("ключ %s%s: ожидается строка или раздел", prefix, key)
+		}
+	}
+	return nil
+}
+
+// Languages возвращает коды языков, для которых есть каталог.
+func Languages() []string {
+	langs := make([]string, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Supported сообщает, есть ли каталог для языка lang.
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Translate возвращает перевод key на язык lang или fallback, если
+// перевода нет.
+func Translate(lang, key, fallback string) string {
+	if text, ok := catalogs[lang][key]; ok {
+		return text
+	}
+	return fallback
+}
+
+// Match выбирает язык по заголовку Accept-Language: из поддерживаемых
+// языков берется язык с наибольшим весом q. Регион не учитывается: en-US
+// подходит под en. Если ни один язык не подходит, возвращается def.
+func Match(acceptLanguage, def string) string {
+	type candidate struct {
+		lang string
+		q    float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		lang, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if q > 0 && Supported(lang) {
+			candidates = append(candidates, candidate{lang: lang, q: q})
+		}
+	}
+	if len(candidates) == 0 {
+		return def
+	}
+
+	// При равных весах MaxFunc берет первый язык, как он указан в заголовке.
+	best := slices.MaxFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.q < b.q:
+			return -1
+		case a.q > b.q:
+			return 1
+		}
+		return 0
+	})
+	return best.lang
+}
+
+// Localizer переводит сообщения на язык одного запроса. Нулевое значение
+// возвращает исходные тексты.
+type Localizer struct {
+	lang string
+}
+
+// For возвращает Localizer для языка lang.
+func For(lang string) Localizer {
+	return Localizer{lang: lang}
+}
+
+// Lang возвращает язык перевода.
+func (l Localizer) Lang() string {
+	return l.lang
+}
+
+// T возвращает перевод key или fallback.
+func (l Localizer) T(key, fallback string) string {
+	return Translate(l.lang, key, fallback)
+}
+
+// Error переводит текст ошибки с кодом code.
+func (l Localizer) Error(code, fallback string) string {
+	return l.T("errors."+code, fallback)
+}
+
+// Details переводит пояснения к полям ошибки с кодом code. Для полей без
+// собственного перевода используется общий перевод details.<код>.*, если он
+// есть, иначе текст остается как есть.
+func (l Localizer) Details(code string, fields map[string]string) map[string]string {
+	if len(fields) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(fields))
+	for field, text := range fields {
+		text = l.T("details."+code+".*", text)
+		result[field] = l.T("details."+code+"."+field, text)
+	}
+	return result
+}
+
+type ctxKey struct{}
+
+// NewContext возвращает копию ctx с языком запроса l.
+func NewContext(ctx context.Context, l Localizer) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// From возвращает Localizer запроса из ctx. Вне запроса возвращается
+// нулевой Localizer.
+func From(ctx context.Context) Localizer {
+	l, _ := ctx.Value(ctxKey{}).(Localizer)
+	return l
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", "ru"},
+		{"en", "en"},
+		{"en-US,en;q=0.9", "en"},
+		{"de-DE,de;q=0.9,en;q=0.5,ru;q=0.7", "ru"},
+		{"fr, en;q=0", "ru"},
+		{"en;q=0.8, ru;q=0.8", "en"},
+		{"en;q=abc", "ru"},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.header, "ru"); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	en := For("en")
+	if got := en.Error("thread_not_found", "Тред не найден"); got != "Thread not found" {
+		t.Errorf("Error() = %q, want перевод", got)
+	}
+	if got := en.Error("unknown_code", "Исходный текст"); got != "Исходный текст" {
+		t.Errorf("Error() без перевода = %q, want исходный текст", got)
+	}
+
+	details := en.Details("invalid_query", map[string]string{"limit": "неверное значение"})
+	if details["limit"] != "invalid value" {
+		t.Errorf("Details() с общим переводом = %v", details)
+	}
+	details = For("ru").Details("invalid_body", map[string]string{"user_id": "ожидается int64"})
+	if details["user_id"] != "ожидается int64" {
+		t.Errorf("Details() на исходном языке = %v", details)
+	}
+
+	if got := From(context.Background()).Error("thread_not_found", "Тред не найден"); got != "Тред не найден" {
+		t.Errorf("нулевой Localizer перевел текст: %q", got)
+	}
+	ctx := NewContext(context.Background(), en)
+	if From(ctx).Lang() != "en" {
+		t.Errorf("From(ctx).Lang() = %q, want en", From(ctx).Lang())
+	}
+}
+
+// TestCatalogsComplete проверяет, что у каждого языка есть все ошибки
+// и ответы, которые есть в каталоге ru.
+func TestCatalogsComplete(t *testing.T) {
+	for _, lang := range Languages() {
+		for key := range catalogs["ru"] {
+			if _, ok := catalogs[lang][key]; !ok {
+				t.Errorf("в каталоге %s нет ключа %s", lang, key)
+			}
+		}
+	}
+}
@@ -1,33 +1,144 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"github.com/fire9900/forum/pkg/i18n"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
 )
 
-var Logger *zap.Logger
+// Config - настройки логгера. Записи всегда пишутся в stdout, а внутренние
+// ошибки zap - в stderr; File и ErrorFile дублируют их в файлы, которые
+// ротируются по размеру MaxSizeMB. Пустой путь отключает запись в файл.
+// Language - язык текста записей; пустое значение оставляет исходный текст.
+type Config struct {
+	Level      string
+	Format     string
+	Language   string
+	File       string
+	ErrorFile  string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	Compress   bool
+}
+
+// InitLogger создает логгер по cfg. Level - debug, info, warn или error,
+// Format - json или console.
+func InitLogger(cfg Config) (*zap.Logger, error) {
+	level, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный уровень логирования %q", cfg.Level)
+	}
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	var encoder zapcore.Encoder
+	switch cfg.Format {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(encoderConfig)
+	case "console":
+		encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+	default:
+		return nil, fmt.Errorf("неизвестный формат логов %q", cfg.Format)
+	}
+
+	out := zapcore.Lock(os.Stdout)
+	if cfg.File != "" {
+		out = zapcore.NewMultiWriteSyncer(out, cfg.rotate(cfg.File))
+	}
+	errorOut := zapcore.Lock(os.Stderr)
+	if cfg.ErrorFile != "" {
+		errorOut = zapcore.NewMultiWriteSyncer(errorOut, cfg.rotate(cfg.ErrorFile))
+	}
+
+	var core zapcore.Core = zapcore.NewCore(encoder, out, level)
+	if cfg.Language != "" {
+		core = translateCore{Core: core, lang: cfg.Language}
+	}
+	return zap.New(core,
+		zap.AddCaller(),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+		zap.ErrorOutput(errorOut),
+	), nil
+}
+
+func (c Config) rotate(path string) zapcore.WriteSyncer {
+	return zapcore.AddSync(&lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    c.MaxSizeMB,
+		MaxBackups: c.MaxBackups,
+		MaxAge:     c.MaxAgeDays,
+		Compress:   c.Compress,
+	})
+}
 
-func TestLoggerInit() {
-	logger, _ := zap.NewDevelopment()
-	zap.ReplaceGlobals(logger)
+// translateCore переводит текст записей на язык lang по разделу log
+// каталога i18n, чтобы журнал велся на одном языке.
+type translateCore struct {
+	zapcore.Core
+	lang string
 }
 
-func InitLogger(main bool) error {
-	config := zap.NewProductionConfig()
-	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+func (c translateCore) With(fields []zapcore.Field) zapcore.Core {
+	return translateCore{Core: c.Core.With(fields), lang: c.lang}
+}
 
-	if main {
-		config.OutputPaths = []string{"stdout", "./forum.log"}
-		config.ErrorOutputPaths = []string{"stderr", "./forum-error.log"}
-	} else {
-		config.OutputPaths = []string{"stdout", "./test.log"}
-		config.ErrorOutputPaths = []string{"stderr", "./test-error.log"}
+func (c translateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
 	}
+	return ce
+}
 
-	var err error
-	Logger, err = config.Build()
-	if err != nil {
-		return err
+func (c translateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = i18n.Translate(c.lang, "log."+ent.Message, ent.Message)
+	return c.Core.Write(ent, fields)
+}
+
+type ctxKey struct{}
+
+// NewContext возвращает копию ctx, в которой хранится логгер запроса l.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// With добавляет поля к логгеру запроса из ctx, например ID пользователя,
+// когда он становится известен после авторизации. Если логгера запроса
+// в ctx нет, ctx возвращается без изменений.
+func With(ctx context.Context, fields ...zap.Field) context.Context {
+	l, ok := ctx.Value(ctxKey{}).(*zap.Logger)
+	if !ok {
+		return ctx
+	}
+	return NewContext(ctx, l.With(fields...))
+}
+
+// From возвращает логгер запроса из ctx, а вне запроса - fallback. Логгер
+// дополнен идентификаторами трассировки и спана, чтобы строки лога можно
+// было сопоставить с трассировкой.
+func From(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	l, ok := ctx.Value(ctxKey{}).(*zap.Logger)
+	if !ok {
+		l = fallback
+	}
+	return withTrace(ctx, l)
+}
+
+// withTrace дополняет l идентификаторами трассировки из ctx. Если в ctx нет
+// трассировки, l возвращается без изменений.
+func withTrace(ctx context.Context, l *zap.Logger) *zap.Logger {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return l
 	}
-	return nil
+	return l.With(
+		zap.String("trace_id", sc.TraceID().String()),
+		zap.String("span_id", sc.SpanID().String()),
+	)
 }
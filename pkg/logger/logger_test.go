@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"context"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInitLogger(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "forum.log")
+	log, err := InitLogger(Config{Level: "warn", Format: "json", File: file, MaxSizeMB: 1})
+	if err != nil {
+		t.Fatalf("ошибка создания логгера: %v", err)
+	}
+	log.Info("не попадет в журнал")
+	log.Warn("попадет в журнал")
+	log.Sync()
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("ошибка чтения журнала: %v", err)
+	}
+	if strings.Contains(string(data), "не попадет") || !strings.Contains(string(data), "попадет в журнал") {
+		t.Errorf("уровень warn не применен, журнал: %s", data)
+	}
+
+	for _, cfg := range []Config{{Level: "trace", Format: "json"}, {Level: "info", Format: "xml"}} {
+		if _, err := InitLogger(cfg); err == nil {
+			t.Errorf("ожидалась ошибка для %+v", cfg)
+		}
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	fallback := zap.New(core)
+
+	From(context.Background(), fallback).Info("вне запроса")
+	if With(context.Background(), zap.Int("user_id", 7)) != context.Background() {
+		t.Error("без логгера запроса контекст не должен меняться")
+	}
+
+	ctx := NewContext(context.Background(), fallback.With(zap.String("request_id", "req-1")))
+	ctx = With(ctx, zap.Int("user_id", 7))
+	ctx = trace.ContextWithSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: trace.TraceID{1},
+		SpanID:  trace.SpanID{2},
+	}))
+	From(ctx, zap.NewNop()).Info("в запросе")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("ожидалось 2 записи, получено %d", len(entries))
+	}
+	if len(entries[0].Context) != 0 {
+		t.Errorf("вне запроса полей быть не должно: %v", entries[0].ContextMap())
+	}
+	fields := entries[1].ContextMap()
+	for _, key := range []string{"request_id", "user_id", "trace_id", "span_id"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("нет поля %q в %v", key, fields)
+		}
+	}
+}
+
+func TestTranslateCore(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	log := zap.New(translateCore{Core: core, lang: "en"}).With(zap.Int("user_id", 7))
+
+	log.Info("Сервер стартует")
+	log.Info("Запись без перевода")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("ожидалось 2 записи, получено %d", len(entries))
+	}
+	if entries[0].Message != "Server starting" {
+		t.Errorf("запись не переведена: %q", entries[0].Message)
+	}
+	if entries[1].Message != "Запись без перевода" {
+		t.Errorf("запись без перевода изменилась: %q", entries[1].Message)
+	}
+	if len(entries[0].Context) != 1 {
+		t.Errorf("поля логгера потеряны: %v", entries[0].Context)
+	}
+}
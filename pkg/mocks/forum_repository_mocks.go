@@ -1,80 +1,349 @@
 package mocks
 
 import (
+	"context"
 	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/internal/repository"
 	"github.com/stretchr/testify/mock"
+	"time"
 )
 
 type ForumRepository struct {
 	mock.Mock
 }
 
-func (m *ForumRepository) GetAllThreads() ([]models.Thread, error) {
-	args := m.Called()
+func (m *ForumRepository) GetAllThreads(ctx context.Context, viewer models.Viewer) ([]models.Thread, error) {
+	args := m.Called(ctx, viewer)
 	return args.Get(0).([]models.Thread), args.Error(1)
 }
 
-func (m *ForumRepository) GetThreadByID(id int) (models.Thread, error) {
-	args := m.Called(id)
+func (m *ForumRepository) GetThreadByID(ctx context.Context, id int) (models.Thread, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(models.Thread), args.Error(1)
 }
 
-func (m *ForumRepository) CreateThread(thread models.Thread) (models.Thread, error) {
-	args := m.Called(thread)
+func (m *ForumRepository) GetThreadForViewer(ctx context.Context, id int, viewer models.Viewer) (models.Thread, error) {
+	args := m.Called(ctx, id, viewer)
 	return args.Get(0).(models.Thread), args.Error(1)
 }
 
-func (m *ForumRepository) DeleteThreadByID(id int) error {
-	args := m.Called(id)
+func (m *ForumRepository) CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	args := m.Called(ctx, thread)
+	return args.Get(0).(models.Thread), args.Error(1)
+}
+
+func (m *ForumRepository) DeleteThreadByID(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
 	return args.Error(0)
 }
 
-func (m *ForumRepository) GetThreadsByUserID(userId int) ([]models.Thread, error) {
-	args := m.Called(userId)
+func (m *ForumRepository) GetThreadsByUserID(ctx context.Context, userId int, viewer models.Viewer) ([]models.Thread, error) {
+	args := m.Called(ctx, userId, viewer)
 	return args.Get(0).([]models.Thread), args.Error(1)
 }
 
-func (m *ForumRepository) CreatePost(post models.Post) (models.Post, error) {
-	args := m.Called(post)
+func (m *ForumRepository) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	args := m.Called(ctx, post)
 	return args.Get(0).(models.Post), args.Error(1)
 }
 
-func (m *ForumRepository) GetPostsByThreadID(threadID int) ([]models.Post, error) {
-	args := m.Called(threadID)
+func (m *ForumRepository) GetPostsByThreadID(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error) {
+	args := m.Called(ctx, threadID, viewer)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumRepository) DeletePostByID(id int) error {
-	args := m.Called(id)
+func (m *ForumRepository) DeletePostByID(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
 	return args.Error(0)
 }
 
-func (m *ForumRepository) GetPostsByUserID(id int) ([]models.Post, error) {
-	args := m.Called(id)
+func (m *ForumRepository) GetPostsByUserID(ctx context.Context, id int, viewer models.Viewer) ([]models.Post, error) {
+	args := m.Called(ctx, id, viewer)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumRepository) GetChatPosts(threadID int) ([]models.Post, error) {
-	args := m.Called(threadID)
+func (m *ForumRepository) GetChatPosts(ctx context.Context, threadID int, viewer models.Viewer) ([]models.Post, error) {
+	args := m.Called(ctx, threadID, viewer)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumRepository) LinkPostToChat(chat models.Chat) error {
-	args := m.Called(chat)
+func (m *ForumRepository) LinkPostToChat(ctx context.Context, chat models.Chat) error {
+	args := m.Called(ctx, chat)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetPostByID(ctx context.Context, id int) (models.Post, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Post), args.Error(1)
+}
+
+func (m *ForumRepository) GetLastPostTime(ctx context.Context, threadID, userID int) (time.Time, error) {
+	args := m.Called(ctx, threadID, userID)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *ForumRepository) CountUserContent(ctx context.Context, userID int) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *ForumRepository) EditThread(ctx context.Context, thread models.Thread, entry models.AuditEntry) error {
+	args := m.Called(ctx, thread, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) UpdateThreadState(ctx context.Context, thread models.Thread, entry models.AuditEntry) error {
+	args := m.Called(ctx, thread, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetActor(ctx context.Context, userID int) (models.Actor, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Actor), args.Error(1)
+}
+
+func (m *ForumRepository) GetAllCategories(ctx context.Context) ([]models.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Category), args.Error(1)
+}
+
+func (m *ForumRepository) GetCategoryByID(ctx context.Context, id int) (models.Category, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func (m *ForumRepository) CreateCategory(ctx context.Context, category models.Category, entry models.AuditEntry) (models.Category, error) {
+	args := m.Called(ctx, category, entry)
+	return args.Get(0).(models.Category), args.Error(1)
+}
+
+func (m *ForumRepository) AddCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error {
+	args := m.Called(ctx, categoryID, userID, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) RemoveCategoryModerator(ctx context.Context, categoryID, userID int, entry models.AuditEntry) error {
+	args := m.Called(ctx, categoryID, userID, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetThreadRedirect(ctx context.Context, oldID int) (int, error) {
+	args := m.Called(ctx, oldID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *ForumRepository) MergeThreads(ctx context.Context, fromID, toID int, entry models.AuditEntry) error {
+	args := m.Called(ctx, fromID, toID, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) SplitThread(ctx context.Context, req models.SplitRequest, thread models.Thread, entry models.AuditEntry) (models.Thread, error) {
+	args := m.Called(ctx, req, thread, entry)
+	return args.Get(0).(models.Thread), args.Error(1)
+}
+
+func (m *ForumRepository) MoveThread(ctx context.Context, threadID, categoryID int, entry models.AuditEntry) error {
+	args := m.Called(ctx, threadID, categoryID, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) CreateReport(ctx context.Context, report models.Report) (models.Report, error) {
+	args := m.Called(ctx, report)
+	return args.Get(0).(models.Report), args.Error(1)
+}
+
+func (m *ForumRepository) GetReports(ctx context.Context, filter models.ReportFilter) ([]models.Report, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.Report), args.Error(1)
+}
+
+func (m *ForumRepository) GetReportByID(ctx context.Context, id int) (models.Report, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Report), args.Error(1)
+}
+
+func (m *ForumRepository) ResolveReport(ctx context.Context, report models.Report, entry models.AuditEntry) error {
+	args := m.Called(ctx, report, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) CreateNotification(ctx context.Context, notification models.Notification) error {
+	args := m.Called(ctx, notification)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetNotifications(ctx context.Context, userID int) ([]models.Notification, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Notification), args.Error(1)
+}
+
+func (m *ForumRepository) MarkNotificationRead(ctx context.Context, id, userID int) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) CreateBan(ctx context.Context, ban models.Ban, entry models.AuditEntry) (models.Ban, error) {
+	args := m.Called(ctx, ban, entry)
+	return args.Get(0).(models.Ban), args.Error(1)
+}
+
+func (m *ForumRepository) GetActiveBans(ctx context.Context, userID int) ([]models.Ban, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]models.Ban), args.Error(1)
+}
+
+func (m *ForumRepository) GetBanByID(ctx context.Context, id int) (models.Ban, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Ban), args.Error(1)
+}
+
+func (m *ForumRepository) DeleteBan(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
 	return args.Error(0)
 }
 
-func (m *ForumRepository) CheckUserByID(user models.User, id int) (bool, error) {
-	args := m.Called(user, id)
-	return args.Bool(0), args.Error(1)
+func (m *ForumRepository) DeleteExpiredBans(ctx context.Context) (int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *ForumRepository) GetPendingThreads(ctx context.Context) ([]models.Thread, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Thread), args.Error(1)
 }
 
-func (m *ForumRepository) GetPostByID(id int) (models.Post, error) {
-	args := m.Called(id)
+func (m *ForumRepository) GetPendingPosts(ctx context.Context) ([]models.PendingPost, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.PendingPost), args.Error(1)
+}
+
+func (m *ForumRepository) ApproveThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) ApprovePost(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) RejectThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) RejectPost(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) SaveFingerprint(ctx context.Context, fp models.Fingerprint) error {
+	args := m.Called(ctx, fp)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetFingerprints(ctx context.Context, since time.Time, limit int) ([]models.Fingerprint, error) {
+	args := m.Called(ctx, since, limit)
+	return args.Get(0).([]models.Fingerprint), args.Error(1)
+}
+
+func (m *ForumRepository) GetUserFingerprints(ctx context.Context, userID int, hash uint64, since time.Time) ([]models.Fingerprint, error) {
+	args := m.Called(ctx, userID, hash, since)
+	return args.Get(0).([]models.Fingerprint), args.Error(1)
+}
+
+func (m *ForumRepository) GetSimilarFingerprints(ctx context.Context, hash uint64, since time.Time, limit int) ([]models.Fingerprint, error) {
+	args := m.Called(ctx, hash, since, limit)
+	return args.Get(0).([]models.Fingerprint), args.Error(1)
+}
+
+func (m *ForumRepository) DeleteFingerprintsBefore(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *ForumRepository) GetSpamModel(ctx context.Context) (models.SpamModel, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.SpamModel), args.Error(1)
+}
+
+func (m *ForumRepository) TrainSpam(ctx context.Context, feedback models.SpamFeedback, tokens map[string]int, entry models.AuditEntry) (string, error) {
+	args := m.Called(ctx, feedback, tokens, entry)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ForumRepository) SaveSpamScore(ctx context.Context, score models.SpamScore) error {
+	args := m.Called(ctx, score)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetSpamScores(ctx context.Context, min float64, limit int) ([]models.SpamScore, error) {
+	args := m.Called(ctx, min, limit)
+	return args.Get(0).([]models.SpamScore), args.Error(1)
+}
+
+func (m *ForumRepository) AddAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	args := m.Called(ctx, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) GetAuditLog(ctx context.Context, filter models.AuditFilter) ([]models.AuditEntry, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]models.AuditEntry), args.Error(1)
+}
+
+func (m *ForumRepository) GetTrash(ctx context.Context, filter models.TrashFilter) (models.Trash, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(models.Trash), args.Error(1)
+}
+
+func (m *ForumRepository) GetDeletedThread(ctx context.Context, id int) (models.Thread, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(models.Thread), args.Error(1)
+}
+
+func (m *ForumRepository) GetDeletedPost(ctx context.Context, id int) (models.Post, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(models.Post), args.Error(1)
 }
 
-func (m *ForumRepository) EditThread(thread models.Thread, userID int) error {
-	args := m.Called(thread, userID)
+func (m *ForumRepository) RestoreThread(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
 	return args.Error(0)
 }
+
+func (m *ForumRepository) RestorePost(ctx context.Context, id int, entry models.AuditEntry) error {
+	args := m.Called(ctx, id, entry)
+	return args.Error(0)
+}
+
+func (m *ForumRepository) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *ForumRepository) CheckIntegrity(ctx context.Context) ([]models.Orphans, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Orphans), args.Error(1)
+}
+
+func (m *ForumRepository) RepairIntegrity(ctx context.Context) ([]models.Orphans, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Orphans), args.Error(1)
+}
+
+func (m *ForumRepository) GetUserLanguage(ctx context.Context, userID int) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *ForumRepository) SetUserLanguage(ctx context.Context, userID int, language string) error {
+	args := m.Called(ctx, userID, language)
+	return args.Error(0)
+}
+
+// WithTx вызывает fn с самой заглушкой: транзакции в тестах usecase не нужны,
+// атомарность проверяется тестами репозитория.
+func (m *ForumRepository) WithTx(ctx context.Context, fn func(repo repository.ForumRepository) error) error {
+	return fn(m)
+}
@@ -2,6 +2,7 @@ package mocks
 
 
 import (
+	"context"
 	"github.com/fire9900/forum/internal/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -10,62 +11,62 @@ type ForumUseCase struct {
 	mock.Mock
 }
 
-func (m *ForumUseCase) GetAllThreads() ([]models.Thread, error) {
-	args := m.Called()
+func (m *ForumUseCase) GetAllThreads(ctx context.Context, viewerID int) ([]models.Thread, error) {
+	args := m.Called(ctx, viewerID)
 	return args.Get(0).([]models.Thread), args.Error(1)
 }
 
-func (m *ForumUseCase) GetThreadByID(id int) (models.Thread, error) {
-	args := m.Called(id)
+func (m *ForumUseCase) GetThreadByID(ctx context.Context, id, viewerID int) (models.Thread, error) {
+	args := m.Called(ctx, id, viewerID)
 	return args.Get(0).(models.Thread), args.Error(1)
 }
 
-func (m *ForumUseCase) CreateThread(thread models.Thread) (models.Thread, error) {
-	args := m.Called(thread)
+func (m *ForumUseCase) CreateThread(ctx context.Context, thread models.Thread) (models.Thread, error) {
+	args := m.Called(ctx, thread)
 	return args.Get(0).(models.Thread), args.Error(1)
 }
 
-func (m *ForumUseCase) DeleteThreadByID(id int, userID int) error {
-	args := m.Called(id, userID)
+func (m *ForumUseCase) DeleteThreadByID(ctx context.Context, id int, userID int) error {
+	args := m.Called(ctx, id, userID)
 	return args.Error(0)
 }
 
-func (m *ForumUseCase) CreatePost(post models.Post) (models.Post, error) {
-	args := m.Called(post)
+func (m *ForumUseCase) CreatePost(ctx context.Context, post models.Post) (models.Post, error) {
+	args := m.Called(ctx, post)
 	return args.Get(0).(models.Post), args.Error(1)
 }
 
-func (m *ForumUseCase) GetChatPosts(threadID int) ([]models.Post, error) {
-	args := m.Called(threadID)
+func (m *ForumUseCase) GetChatPosts(ctx context.Context, threadID, viewerID int) ([]models.Post, error) {
+	args := m.Called(ctx, threadID, viewerID)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumUseCase) GetPostByThreadID(threadID int) ([]models.Post, error) {
-	args := m.Called(threadID)
+func (m *ForumUseCase) GetPostByThreadID(ctx context.Context, threadID, viewerID int) ([]models.Post, error) {
+	args := m.Called(ctx, threadID, viewerID)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumUseCase) DeletePostByID(id int, userID int) error {
-	args := m.Called(id, userID)
+func (m *ForumUseCase) DeletePostByID(ctx context.Context, id int, userID int) error {
+	args := m.Called(ctx, id, userID)
 	return args.Error(0)
 }
 
-func (m *ForumUseCase) GetPostsByUserID(id int) ([]models.Post, error) {
-	args := m.Called(id)
+func (m *ForumUseCase) GetPostsByUserID(ctx context.Context, id, viewerID int) ([]models.Post, error) {
+	args := m.Called(ctx, id, viewerID)
 	return args.Get(0).([]models.Post), args.Error(1)
 }
 
-func (m *ForumUseCase) CheckUserByID(any models.User, id int) (bool, error) {
-	args := m.Called(any, id)
-	return args.Bool(0), args.Error(1)
-}
-
-func (m *ForumUseCase) GetUserThreads(userId int) ([]models.Thread, error) {
-	args := m.Called(userId)
+func (m *ForumUseCase) GetUserThreads(ctx context.Context, userId, viewerID int) ([]models.Thread, error) {
+	args := m.Called(ctx, userId, viewerID)
 	return args.Get(0).([]models.Thread), args.Error(1)
 }
 
-func (m *ForumUseCase) EditThread(thread models.Thread, userID int) error {
-	args := m.Called(thread, userID)
+func (m *ForumUseCase) EditThread(ctx context.Context, thread models.Thread, userID int) error {
+	args := m.Called(ctx, thread, userID)
 	return args.Error(0)
 }
+
+func (m *ForumUseCase) SetThreadState(ctx context.Context, id int, state models.ThreadState, userID int) (models.Thread, error) {
+	args := m.Called(ctx, id, state, userID)
+	return args.Get(0).(models.Thread), args.Error(1)
+}
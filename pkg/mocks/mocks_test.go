@@ -0,0 +1,15 @@
+package mocks
+
+import (
+	"github.com/fire9900/forum/internal/repository"
+	"github.com/fire9900/forum/internal/usecase"
+)
+
+// Заглушки проверяются на соответствие интерфейсам при сборке тестов:
+// пакет usecase сам импортирует mocks в тестах, поэтому в коде заглушек
+// такой проверки быть не может.
+var (
+	_ repository.ForumRepository = (*ForumRepository)(nil)
+	_ usecase.ThreadUseCase      = (*ForumUseCase)(nil)
+	_ usecase.PostUseCase        = (*ForumUseCase)(nil)
+)
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// MemoryStore хранит корзины в памяти процесса. Подходит для одного экземпляра сервиса.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Allow(_ context.Context, buckets []Bucket) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	result := Result{Allowed: true}
+	states := make([]*bucket, len(buckets))
+	for i, bk := range buckets {
+		b := s.refill(bk.Key, bk.Limit, now)
+		states[i] = b
+		if b.tokens >= 1 {
+			continue
+		}
+
+		result.Allowed = false
+		wait := time.Duration((1 - b.tokens) / bk.Limit.Rate * float64(time.Second))
+		if wait > result.RetryAfter {
+			result.RetryAfter = wait
+		}
+	}
+
+	if result.Allowed {
+		for _, b := range states {
+			b.tokens--
+		}
+	}
+	return result, nil
+}
+
+// refill возвращает корзину key, пополненную к моменту now. Вызывается под s.mu.
+func (s *MemoryStore) refill(key string, limit Limit, now time.Time) *bucket {
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), last: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * limit.Rate
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.last = now
+	return b
+}
+
+// Cleanup удаляет корзины, к которым не обращались дольше idle.
+func (s *MemoryStore) Cleanup(idle time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, b := range s.buckets {
+		if now.Sub(b.last) > idle {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// RunCleanup периодически вызывает Cleanup, пока не отменен ctx.
+func (s *MemoryStore) RunCleanup(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Cleanup(idle)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Limit задает корзину токенов: Rate токенов в секунду, не больше Burst подряд.
+type Limit struct {
+	Rate  float64 `json:"rate" yaml:"rate"`
+	Burst int     `json:"burst" yaml:"burst"`
+}
+
+// PerMinute возвращает лимит в n запросов в минуту с запасом burst.
+func PerMinute(n, burst int) Limit {
+	return Limit{Rate: float64(n) / 60, Burst: burst}
+}
+
+// Result - итог проверки лимита. RetryAfter заполняется при отказе.
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// RetryAfterSeconds округляет RetryAfter вверх до целых секунд для заголовка Retry-After.
+func (r Result) RetryAfterSeconds() int {
+	return int(math.Ceil(r.RetryAfter.Seconds()))
+}
+
+// Bucket - корзина токенов key с лимитом Limit.
+type Bucket struct {
+	Key   string
+	Limit Limit
+}
+
+// Store хранит корзины токенов. Allow списывает по токену из каждой корзины,
+// только если токен есть во всех; при отказе ни одна корзина не списывается,
+// а RetryAfter - наибольшее время ожидания среди пустых корзин.
+type Store interface {
+	Allow(ctx context.Context, buckets []Bucket) (Result, error)
+}
+
+// Scope - измерение, по которому считается лимит.
+type Scope string
+
+const (
+	ScopeUser   Scope = "user"
+	ScopeIP     Scope = "ip"
+	ScopeThread Scope = "thread"
+)
+
+// Конечные точки, для которых настраиваются лимиты.
+const (
+	EndpointPostCreate   = "post.create"
+	EndpointThreadCreate = "thread.create"
+	EndpointReportCreate = "report.create"
+	EndpointWSPost       = "ws.post"
+)
+
+// Endpoints возвращает конечные точки, для которых настраиваются лимиты.
+func Endpoints() []string {
+	return []string{EndpointPostCreate, EndpointThreadCreate, EndpointReportCreate, EndpointWSPost}
+}
+
+// Scopes возвращает измерения, по которым считаются лимиты.
+func Scopes() []Scope {
+	return []Scope{ScopeUser, ScopeIP, ScopeThread}
+}
+
+// Rules - лимиты конечной точки по измерениям. Отсутствующее измерение не ограничивается.
+type Rules map[Scope]Limit
+
+// Config сопоставляет конечным точкам их лимиты.
+type Config struct {
+	Endpoints map[string]Rules `json:"endpoints" yaml:"endpoints"`
+}
+
+func DefaultConfig() Config {
+	posting := Rules{
+		ScopeUser:   PerMinute(20, 5),
+		ScopeIP:     PerMinute(60, 20),
+		ScopeThread: PerMinute(120, 30),
+	}
+	return Config{Endpoints: map[string]Rules{
+		EndpointPostCreate: posting,
+		EndpointWSPost:     posting,
+		EndpointThreadCreate: {
+			ScopeUser: PerMinute(3, 2),
+			ScopeIP:   PerMinute(10, 5),
+		},
+		EndpointReportCreate: {
+			ScopeUser: PerMinute(10, 5),
+		},
+	}}
+}
+
+// Keys - идентификаторы клиента по измерениям. Пустые значения не проверяются.
+type Keys struct {
+	UserID   int
+	IP       string
+	ThreadID int
+}
+
+func (k Keys) value(scope Scope) (string, bool) {
+	switch scope {
+	case ScopeUser:
+		return strconv.Itoa(k.UserID), k.UserID != 0
+	case ScopeIP:
+		return k.IP, k.IP != ""
+	case ScopeThread:
+		return strconv.Itoa(k.ThreadID), k.ThreadID != 0
+	}
+	return "", false
+}
+
+type Limiter struct {
+	store  Store
+	config Config
+}
+
+func NewLimiter(store Store, config Config) *Limiter {
+	return &Limiter{store: store, config: config}
+}
+
+// Allow проверяет все лимиты конечной точки. Запрос отклоняется, если превышен
+// хотя бы один из них; RetryAfter - наибольшее время ожидания среди отказов.
+// Отклоненный запрос не расходует лимиты остальных измерений.
+func (l *Limiter) Allow(ctx context.Context, endpoint string, keys Keys) (Result, error) {
+	var buckets []Bucket
+	for scope, limit := range l.config.Endpoints[endpoint] {
+		value, ok := keys.value(scope)
+		if !ok {
+			continue
+		}
+		buckets = append(buckets, Bucket{Key: endpoint + ":" + string(scope) + ":" + value, Limit: limit})
+	}
+	if len(buckets) == 0 {
+		return Result{Allowed: true}, nil
+	}
+	return l.store.Allow(ctx, buckets)
+}
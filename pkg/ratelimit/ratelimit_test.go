@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (f *fakeClock) Now() time.Time {
+	return f.now
+}
+
+func newTestStore() (*MemoryStore, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+	return store, clock
+}
+
+func TestMemoryStore_Allow(t *testing.T) {
+	store, clock := newTestStore()
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if r, _ := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}}); !r.Allowed {
+			t.Fatalf("запрос %d в пределах burst должен быть разрешен", i+1)
+		}
+	}
+
+	r, _ := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}})
+	if r.Allowed {
+		t.Fatal("запрос сверх burst должен быть отклонен")
+	}
+	if r.RetryAfter != time.Second || r.RetryAfterSeconds() != 1 {
+		t.Errorf("ожидалось ожидание 1s, получено %v", r.RetryAfter)
+	}
+
+	clock.now = clock.now.Add(500 * time.Millisecond)
+	if r, _ := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}}); r.Allowed {
+		t.Error("за полсекунды токен не должен восстановиться")
+	}
+
+	clock.now = clock.now.Add(600 * time.Millisecond)
+	if r, _ := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}}); !r.Allowed {
+		t.Error("после восстановления токена запрос должен быть разрешен")
+	}
+
+	if r, _ := store.Allow(ctx, []Bucket{{Key: "other", Limit: limit}}); !r.Allowed {
+		t.Error("корзины разных ключей не должны влиять друг на друга")
+	}
+}
+
+func TestMemoryStore_Cleanup(t *testing.T) {
+	store, clock := newTestStore()
+	store.Allow(context.Background(), []Bucket{{Key: "k", Limit: Limit{Rate: 1, Burst: 1}}})
+
+	clock.now = clock.now.Add(time.Hour)
+	store.Cleanup(time.Minute)
+
+	if len(store.buckets) != 0 {
+		t.Errorf("ожидалось удаление простаивающей корзины, осталось %d", len(store.buckets))
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Config{Endpoints: map[string]Rules{
+		EndpointPostCreate: {
+			ScopeUser:   Limit{Rate: 1, Burst: 1},
+			ScopeThread: Limit{Rate: 0.5, Burst: 2},
+		},
+	}})
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		keys    Keys
+		allowed bool
+	}{
+		{"first post of user 1", Keys{UserID: 1, ThreadID: 7}, true},
+		{"user 1 exceeds own limit", Keys{UserID: 1, ThreadID: 8}, false},
+		{"user 2 in same thread", Keys{UserID: 2, ThreadID: 7}, true},
+		{"thread 7 is exhausted", Keys{UserID: 3, ThreadID: 7}, false},
+		{"anonymous without keys is not limited", Keys{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := limiter.Allow(ctx, EndpointPostCreate, tt.keys)
+			if err != nil {
+				t.Fatalf("ошибка не ожидалась: %s", err)
+			}
+			if r.Allowed != tt.allowed {
+				t.Errorf("ожидалось allowed=%v, получено %v", tt.allowed, r.Allowed)
+			}
+			if !r.Allowed && r.RetryAfter <= 0 {
+				t.Error("при отказе ожидалось положительное время ожидания")
+			}
+		})
+	}
+
+	if r, _ := limiter.Allow(ctx, "unknown", Keys{UserID: 1}); !r.Allowed {
+		t.Error("конечная точка без правил не должна ограничиваться")
+	}
+}
+
+func TestLimiter_AllowDeniedKeepsOtherScopes(t *testing.T) {
+	store, _ := newTestStore()
+	limiter := NewLimiter(store, Config{Endpoints: map[string]Rules{
+		EndpointPostCreate: {
+			ScopeUser:   Limit{Rate: 1, Burst: 1},
+			ScopeThread: Limit{Rate: 1, Burst: 2},
+		},
+	}})
+	ctx := context.Background()
+
+	limiter.Allow(ctx, EndpointPostCreate, Keys{UserID: 1, ThreadID: 7})
+	for i := 0; i < 3; i++ {
+		if r, _ := limiter.Allow(ctx, EndpointPostCreate, Keys{UserID: 1, ThreadID: 8}); r.Allowed {
+			t.Fatal("пользователь 1 исчерпал свой лимит, запрос должен быть отклонен")
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if r, _ := limiter.Allow(ctx, EndpointPostCreate, Keys{UserID: 2 + i, ThreadID: 8}); !r.Allowed {
+			t.Fatalf("отклоненные запросы не должны расходовать лимит треда, запрос %d отклонен", i+1)
+		}
+	}
+}
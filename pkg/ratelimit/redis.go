@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"time"
+)
+
+// tokenBucket атомарно на стороне Redis проверяет корзины KEYS и списывает
+// по токену из каждой, только если токен есть во всех. Лимит корзины KEYS[i]
+// передается в ARGV[2i-1] (rate) и ARGV[2i] (burst). Время берется из Redis,
+// чтобы экземпляры сервиса с расходящимися часами считали одинаково.
+var tokenBucket = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tokens = {}
+local allowed = 1
+local retry = 0
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[2 * i - 1])
+	local burst = tonumber(ARGV[2 * i])
+	local state = redis.call('HMGET', key, 'tokens', 'ts')
+	local n = tonumber(state[1]) or burst
+	local ts = tonumber(state[2]) or now
+
+	n = math.min(burst, n + (now - ts) / 1000 * rate)
+	if n < 1 then
+		allowed = 0
+		retry = math.max(retry, math.ceil((1 - n) / rate * 1000))
+	end
+	tokens[i] = n
+end
+
+for i, key in ipairs(KEYS) do
+	local rate = tonumber(ARGV[2 * i - 1])
+	local burst = tonumber(ARGV[2 * i])
+	if allowed == 1 then
+		tokens[i] = tokens[i] - 1
+	end
+	redis.call('HSET', key, 'tokens', tostring(tokens[i]), 'ts', tostring(now))
+	redis.call('PEXPIRE', key, math.ceil(burst / rate * 1000) + 1000)
+end
+return {allowed, retry}
+`)
+
+// RedisStore хранит корзины в Redis и позволяет делить лимиты между экземплярами сервиса.
+type RedisStore struct {
+	client redis.Scripter
+	prefix string
+}
+
+func NewRedisStore(client redis.Scripter, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+func (s *RedisStore) Allow(ctx context.Context, buckets []Bucket) (Result, error) {
+	keys := make([]string, 0, len(buckets))
+	args := make([]any, 0, 2*len(buckets))
+	for _, b := range buckets {
+		keys = append(keys, s.prefix+b.Key)
+		args = append(args, b.Limit.Rate, b.Limit.Burst)
+	}
+
+	values, err := tokenBucket.Run(ctx, s.client, keys, args...).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("ошибка проверки лимита в Redis: %w", err)
+	}
+	if values[0] == 1 {
+		return Result{Allowed: true}, nil
+	}
+	return Result{RetryAfter: time.Duration(values[1]) * time.Millisecond}, nil
+}
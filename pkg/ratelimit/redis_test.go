@@ -0,0 +1,66 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"testing"
+)
+
+func TestRedisStore_Allow(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisStore(client, "test:")
+	limit := Limit{Rate: 1, Burst: 2}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		r, err := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}})
+		if err != nil {
+			t.Fatalf("ошибка не ожидалась: %s", err)
+		}
+		if !r.Allowed {
+			t.Fatalf("запрос %d в пределах burst должен быть разрешен", i+1)
+		}
+	}
+
+	r, err := store.Allow(ctx, []Bucket{{Key: "k", Limit: limit}})
+	if err != nil {
+		t.Fatalf("ошибка не ожидалась: %s", err)
+	}
+	if r.Allowed || r.RetryAfter <= 0 {
+		t.Errorf("запрос сверх burst должен быть отклонен с ожиданием, получено %+v", r)
+	}
+
+	if !server.Exists("test:k") {
+		t.Error("ожидалась корзина с префиксом ключа")
+	}
+}
+
+func TestRedisStore_AllowDebitsOnlyWhenAllAllow(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	store := NewRedisStore(client, "test:")
+	ctx := context.Background()
+	empty := Bucket{Key: "empty", Limit: Limit{Rate: 1, Burst: 1}}
+	full := Bucket{Key: "full", Limit: Limit{Rate: 1, Burst: 1}}
+
+	if r, err := store.Allow(ctx, []Bucket{empty}); err != nil || !r.Allowed {
+		t.Fatalf("первый запрос должен быть разрешен, получено %+v, %v", r, err)
+	}
+	r, err := store.Allow(ctx, []Bucket{empty, full})
+	if err != nil {
+		t.Fatalf("ошибка не ожидалась: %s", err)
+	}
+	if r.Allowed || r.RetryAfter <= 0 {
+		t.Errorf("запрос с пустой корзиной должен быть отклонен с ожиданием, получено %+v", r)
+	}
+
+	if r, _ := store.Allow(ctx, []Bucket{full}); !r.Allowed {
+		t.Error("отклоненный запрос не должен списывать токен из остальных корзин")
+	}
+}
@@ -1,20 +1,110 @@
 package wsserver
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"github.com/fire9900/forum/internal/models"
+	"github.com/fire9900/forum/pkg/i18n"
 	"github.com/fire9900/forum/pkg/logger"
+	"github.com/fire9900/forum/pkg/ratelimit"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"net/http"
 	"strconv"
+	"time"
 )
 
+// messageTimeout ограничивает обработку одного сообщения WebSocket:
+// соединение живет дольше дедлайна HTTP-запроса, на котором оно открыто.
+const messageTimeout = 10 * time.Second
+
+// writeWait - сколько ждать записи одного кадра клиенту. Без дедлайна
+// зависший клиент задержал бы остановку хаба.
+const writeWait = 10 * time.Second
+
+var tracer = otel.Tracer("github.com/fire9900/forum/pkg/wsserver")
+
+// closeConn отправляет close-кадр с кодом code и закрывает соединение.
+func closeConn(conn *websocket.Conn, code int, reason string) {
+	conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(writeWait))
+	conn.Close()
+}
+
+// errorFrame отправляется клиенту, если его сообщение не удалось обработать.
+// Поля совпадают с телом HTTP-ответа с ошибкой.
+type errorFrame struct {
+	Error      string            `json:"error"`
+	Code       string            `json:"code"`
+	Details    map[string]string `json:"details,omitempty"`
+	RetryAfter int               `json:"retry_after,omitempty"`
+	RequestID  string            `json:"request_id,omitempty"`
+}
+
+// postErrorFrame описывает ошибку создания поста кодом доменной ошибки.
+// Текст ошибок вне доменной модели клиенту не отдается.
+func postErrorFrame(err error) errorFrame {
+	var domain *models.Error
+	if !errors.As(err, &domain) {
+		return errorFrame{Error: "Внутренняя ошибка сервера", Code: "internal"}
+	}
+
+	frame := errorFrame{Error: domain.Message, Code: domain.Code, Details: domain.Fields}
+	var slow *models.SlowModeError
+	if errors.As(err, &slow) {
+		frame.RetryAfter = slow.RetryAfterSeconds()
+	}
+	return frame
+}
+
+// rateLimited проверяет лимит сообщений клиента. При ошибке хранилища
+// лимитов сообщение пропускается.
+func (hub *Hub) rateLimited(ctx context.Context, ip string, post models.Post) (errorFrame, bool) {
+	if hub.limiter == nil {
+		return errorFrame{}, false
+	}
+
+	result, err := hub.limiter.Allow(ctx, ratelimit.EndpointWSPost, ratelimit.Keys{
+		UserID:   post.UserID,
+		IP:       ip,
+		ThreadID: post.ThreadID,
+	})
+	if err != nil {
+		logger.From(ctx, hub.logger).Error("Ошибка проверки лимита сообщений",
+			zap.Int("threadID", post.ThreadID),
+			zap.Error(err))
+		return errorFrame{}, false
+	}
+	if result.Allowed {
+		return errorFrame{}, false
+	}
+
+	logger.From(ctx, hub.logger).Warn("Превышен лимит сообщений WebSocket",
+		zap.Int("threadID", post.ThreadID),
+		zap.Int("userID", post.UserID),
+		zap.String("ip", ip))
+	return errorFrame{
+		Error:      "Слишком много сообщений",
+		Code:       "rate_limited",
+		RetryAfter: result.RetryAfterSeconds(),
+	}, true
+}
+
+// ThreadChat открывает WebSocket-чат треда. Маршрут стоит за AuthMiddleware:
+// автором всех сообщений соединения считается авторизованный пользователь,
+// user_id из сообщения игнорируется.
 func (hub *Hub) ThreadChat(c *gin.Context) {
+	userID := c.GetInt("userID")
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
-		logger.Logger.Error("Ошибка при переходе на WebSocket соединение",
+		logger.From(c.Request.Context(), hub.logger).Error("Ошибка при переходе на WebSocket соединение",
 			zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -22,46 +112,73 @@ func (hub *Hub) ThreadChat(c *gin.Context) {
 
 	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		logger.Logger.Error("Некорректный ID треда в WebSocket запросе",
+		logger.From(c.Request.Context(), hub.logger).Error("Некорректный ID треда в WebSocket запросе",
 			zap.Error(err),
 			zap.String("параметр", c.Param("id")))
-		conn.WriteMessage(websocket.CloseMessage, []byte("Invalid thread ID"))
-		conn.Close()
+		closeConn(conn, websocket.ClosePolicyViolation, "invalid thread id")
 		return
 	}
 
-	logger.Logger.Info("Новое WebSocket соединение",
-		zap.Int("threadID", id))
+	logger.From(c.Request.Context(), hub.logger).Info("Новое WebSocket соединение",
+		zap.Int("threadID", id),
+		zap.Int("userID", userID))
 
 	client := &Client{
 		conn:     conn,
-		send:     make(chan models.Post, 256),
+		send:     make(chan any, 256),
 		threadID: id,
 	}
 
-	hub.register <- client
+	// gin.Context переиспользуется после возврата из обработчика, а контекст
+	// запроса отменяется вместе с ним, поэтому у соединения свой контекст.
+	// Он отменяется, когда клиент отключается.
+	ip := c.ClientIP()
+	// Ошибки сообщений помечаются ID запроса, открывшего соединение,
+	// и переводятся на язык, выбранный для этого запроса.
+	requestID := c.GetString("requestID")
+	loc := i18n.From(c.Request.Context())
+	writeError := func(frame errorFrame) {
+		frame.Error = loc.Error(frame.Code, frame.Error)
+		frame.Details = loc.Details(frame.Code, frame.Details)
+		frame.RequestID = requestID
+		conn.WriteJSON(frame)
+	}
+	ctx, cancel := context.WithCancel(context.WithoutCancel(c.Request.Context()))
+
+	if !hub.registerClient(client) {
+		cancel()
+		closeConn(conn, websocket.CloseGoingAway, "server shutdown")
+		return
+	}
 
 	go func() {
-		posts, err := hub.UseCase.GetChatPosts(id)
+		historyCtx, cancelHistory := context.WithTimeout(ctx, messageTimeout)
+		defer cancelHistory()
+
+		// История отдается такой, какой ее видит пользователь соединения:
+		// с его собственными скрытыми сообщениями.
+		posts, err := hub.UseCase.GetChatPosts(historyCtx, id, userID)
+		if errors.Is(err, models.ErrorNotFoundThread) {
+			// Тред не существует или скрыт от пользователя (на проверке или
+			// под теневой блокировкой): чат такого треда не открывается.
+			logger.From(ctx, hub.logger).Warn("Чат недоступного треда",
+				zap.Int("threadID", id))
+			closeConn(conn, websocket.ClosePolicyViolation, "thread not found")
+			return
+		}
 		if err != nil {
-			logger.Logger.Error("Ошибка при получении сообщений чата",
+			logger.From(ctx, hub.logger).Error("Ошибка при получении сообщений чата",
 				zap.Int("threadID", id),
 				zap.Error(err))
 			return
 		}
 
-		logger.Logger.Debug("Отправка истории сообщений новому клиенту",
+		logger.From(ctx, hub.logger).Debug("Отправка истории сообщений новому клиенту",
 			zap.Int("threadID", id),
 			zap.Int("количество сообщений", len(posts)))
 
 		for _, post := range posts {
-			select {
-			case client.send <- post:
-			default:
-				logger.Logger.Warn("Канал отправки переполнен, отключаем клиента",
-					zap.Int("threadID", id))
-				hub.unregister <- client
-				conn.Close()
+			if !hub.sendTo(client, post) {
 				return
 			}
 		}
@@ -69,16 +186,17 @@ func (hub *Hub) ThreadChat(c *gin.Context) {
 
 	go func() {
 		defer func() {
-			hub.unregister <- client
+			cancel()
+			hub.unregisterClient(client)
 			conn.Close()
-			logger.Logger.Info("WebSocket соединение закрыто",
+			logger.From(ctx, hub.logger).Info("WebSocket соединение закрыто",
 				zap.Int("threadID", id))
 		}()
 
 		for {
 			_, message, err := conn.ReadMessage()
 			if err != nil {
-				logger.Logger.Debug("Ошибка чтения сообщения из WebSocket",
+				logger.From(ctx, hub.logger).Debug("Ошибка чтения сообщения из WebSocket",
 					zap.Int("threadID", id),
 					zap.Error(err))
 				break
@@ -86,49 +204,82 @@ func (hub *Hub) ThreadChat(c *gin.Context) {
 
 			var post models.Post
 			if err := json.Unmarshal(message, &post); err != nil {
-				logger.Logger.Warn("Некорректный формат сообщения",
+				logger.From(ctx, hub.logger).Warn("Некорректный формат сообщения",
 					zap.Int("threadID", id),
 					zap.Error(err))
-				conn.WriteJSON(map[string]string{"error": "invalid message format"})
+				writeError(errorFrame{Error: "Неверный формат сообщения", Code: "invalid_body"})
 				continue
 			}
 
 			post.ThreadID = id
-			createdPost, err := hub.UseCase.CreatePost(post)
+			post.UserID = userID
+			// Время поста задает сервер: по нему считается медленный режим.
+			post.CreateAt = time.Now()
+			msgCtx, cancelMsg := context.WithTimeout(logger.With(ctx, zap.Int("user_id", post.UserID)), messageTimeout)
+			// Каждое сообщение - отдельный спан в трассировке запроса,
+			// открывшего соединение.
+			msgCtx, span := tracer.Start(msgCtx, "WebSocket message",
+				trace.WithAttributes(attribute.Int("thread.id", id)))
+			if frame, limited := hub.rateLimited(msgCtx, ip, post); limited {
+				span.End()
+				cancelMsg()
+				writeError(frame)
+				continue
+			}
+
+			createdPost, err := hub.UseCase.CreatePost(msgCtx, post)
+			cancelMsg()
 			if err != nil {
-				logger.Logger.Error("Ошибка при создании сообщения",
+				logger.From(msgCtx, hub.logger).Error("Ошибка при создании сообщения",
 					zap.Int("threadID", id),
 					zap.Error(err))
-				conn.WriteJSON(map[string]string{"error": "failed to create post"})
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.End()
+				writeError(postErrorFrame(err))
 				continue
 			}
+			span.End()
 
-			logger.Logger.Debug("Новое сообщение создано",
+			logger.From(msgCtx, hub.logger).Debug("Новое сообщение создано",
 				zap.Int("threadID", id),
 				zap.Int("userID", post.UserID),
 				zap.String("content", post.Content))
 
-			hub.chat <- createdPost
+			if createdPost.Hidden {
+				// Пост под теневой блокировкой или на проверке видит только автор.
+				hub.sendTo(client, createdPost)
+				continue
+			}
+			hub.BroadcastPost(createdPost)
 		}
 	}()
 
 	go func() {
+		defer hub.conns.Done()
 		defer conn.Close()
 
 		for message := range client.send {
 			postBytes, err := json.Marshal(message)
 			if err != nil {
-				logger.Logger.Error("Ошибка при сериализации сообщения",
-					zap.Int("threadID", message.ThreadID),
+				logger.From(ctx, hub.logger).Error("Ошибка при сериализации сообщения",
+					zap.Int("threadID", client.threadID),
 					zap.Error(err))
 				continue
 			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if err := conn.WriteMessage(websocket.TextMessage, postBytes); err != nil {
-				logger.Logger.Debug("Ошибка отправки сообщения через WebSocket",
-					zap.Int("threadID", message.ThreadID),
+				logger.From(ctx, hub.logger).Debug("Ошибка отправки сообщения через WebSocket",
+					zap.Int("threadID", client.threadID),
 					zap.Error(err))
-				break
+				return
 			}
 		}
+
+		// Канал закрыт хабом. Если соединение закрывает сервер, клиент
+		// получает причину в close-кадре.
+		if client.closeCode != 0 {
+			closeConn(conn, client.closeCode, client.closeReason)
+		}
 	}()
 }
@@ -1,12 +1,16 @@
 package wsserver
 
 import (
+	"context"
+	"github.com/fire9900/forum/internal/metrics"
 	"github.com/fire9900/forum/internal/models"
 	"github.com/fire9900/forum/internal/usecase"
+	"github.com/fire9900/forum/pkg/ratelimit"
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 	"net/http"
 	"sync"
+	"sync/atomic"
 )
 
 var upgrader = &websocket.Upgrader{
@@ -19,50 +23,150 @@ var upgrader = &websocket.Upgrader{
 
 type Client struct {
 	conn     *websocket.Conn
-	send     chan models.Post
+	send     chan any
 	threadID int
+	// closeCode и closeReason задаются хабом перед закрытием send, если
+	// соединение закрывает сервер. Писатель отправляет их в close-кадре.
+	closeCode   int
+	closeReason string
 }
 
+// ThreadStateEvent рассылается клиентам треда при изменении его состояния.
+type ThreadStateEvent struct {
+	Type   string        `json:"type"`
+	Thread models.Thread `json:"thread"`
+}
+
+const EventThreadState = "thread_state"
+
 type Hub struct {
-	clients    map[*Client]bool
-	chat       chan models.Post
-	register   chan *Client
-	unregister chan *Client
-	mu         sync.Mutex
-	UseCase    usecase.PostUseCase
-	logger     *zap.Logger
+	clients map[*Client]bool
+	// threadClients - число клиентов каждого треда для метрик.
+	threadClients map[int]int
+	chat          chan models.Post
+	state         chan models.Thread
+	unregister    chan *Client
+	// done закрывается при остановке хаба, после чего отправка в его каналы
+	// не блокирует отправителя.
+	done    chan struct{}
+	running atomic.Bool
+	// conns отслеживает горутины записи, чтобы при остановке дождаться
+	// отправки close-кадров.
+	conns   sync.WaitGroup
+	mu      sync.Mutex
+	UseCase usecase.PostUseCase
+	logger  *zap.Logger
+	limiter *ratelimit.Limiter
+	metrics *metrics.Metrics
 }
 
 func NewHub(UseCase usecase.PostUseCase, logger *zap.Logger) *Hub {
 	return &Hub{
-		clients:    make(map[*Client]bool),
-		chat:       make(chan models.Post),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
-		mu:         sync.Mutex{},
-		UseCase:    UseCase,
-		logger:     logger,
+		clients:       make(map[*Client]bool),
+		threadClients: make(map[int]int),
+		chat:          make(chan models.Post),
+		state:         make(chan models.Thread),
+		unregister:    make(chan *Client),
+		done:          make(chan struct{}),
+		mu:            sync.Mutex{},
+		UseCase:       UseCase,
+		logger:        logger,
+	}
+}
+
+// SetRateLimiter включает ограничение частоты сообщений, отправляемых через WebSocket.
+func (h *Hub) SetRateLimiter(limiter *ratelimit.Limiter) {
+	h.limiter = limiter
+}
+
+// SetMetrics включает метрики соединений и очереди рассылки.
+func (h *Hub) SetMetrics(m *metrics.Metrics) {
+	h.metrics = m
+	m.RegisterHubQueue(h.QueueDepth)
+}
+
+// QueueDepth возвращает число сообщений, ожидающих отправки клиентам.
+func (h *Hub) QueueDepth() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	depth := 0
+	for client := range h.clients {
+		depth += len(client.send)
+	}
+	return depth
+}
+
+// BroadcastThreadState оповещает подписчиков треда о смене его состояния.
+// После остановки хаба событие отбрасывается.
+func (h *Hub) BroadcastThreadState(thread models.Thread) {
+	select {
+	case h.state <- thread:
+	case <-h.done:
 	}
 }
 
-func (h *Hub) Run() {
+// BroadcastPost рассылает подписчикам треда новый или одобренный модератором пост.
+// После остановки хаба пост не рассылается.
+func (h *Hub) BroadcastPost(post models.Post) {
+	select {
+	case h.chat <- post:
+	case <-h.done:
+	}
+}
+
+// Running сообщает, обслуживает ли хаб подписки.
+func (h *Hub) Running() bool {
+	return h.running.Load()
+}
+
+// registerClient добавляет клиента в хаб и учитывает его писателя в conns.
+// Клиент добавляется сразу, чтобы ему можно было отправить историю чата.
+// Возвращает false, если хаб остановлен.
+func (h *Hub) registerClient(client *Client) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	select {
+	case <-h.done:
+		return false
+	default:
+	}
+
+	h.logger.Debug("Регистрация нового клиента",
+		zap.Int("threadID", client.threadID))
+	h.conns.Add(1)
+	h.clients[client] = true
+	h.threadClients[client.threadID]++
+	h.metrics.SetWSConnections(client.threadID, h.threadClients[client.threadID])
+	return true
+}
+
+func (h *Hub) unregisterClient(client *Client) {
+	select {
+	case h.unregister <- client:
+	case <-h.done:
+	}
+}
+
+// Run обслуживает подписки и рассылку, пока не отменен ctx. При остановке
+// всем клиентам отправляется close-кадр 1001 (going away), и Run ждет, пока
+// они будут отправлены.
+func (h *Hub) Run(ctx context.Context) {
 	h.logger.Info("Запуск хаба WebSocket")
+	h.running.Store(true)
+	defer h.running.Store(false)
 	for {
 		select {
-		case client := <-h.register:
-			h.logger.Debug("Регистрация нового клиента",
-				zap.Int("threadID", client.threadID))
-			h.mu.Lock()
-			h.clients[client] = true
-			h.mu.Unlock()
+		case <-ctx.Done():
+			h.shutdown()
+			return
 
 		case client := <-h.unregister:
 			h.logger.Debug("Отключение клиента",
 				zap.Int("threadID", client.threadID))
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
-				close(client.send)
-				delete(h.clients, client)
+				h.closeClient(client, 0, "")
 			}
 			h.mu.Unlock()
 
@@ -71,20 +175,86 @@ func (h *Hub) Run() {
 				zap.Int("threadID", message.ThreadID),
 				zap.Int("userID", message.UserID),
 				zap.String("content", message.Content))
-			h.mu.Lock()
-			for client := range h.clients {
-				if client.threadID == message.ThreadID {
-					select {
-					case client.send <- message:
-					default:
-						close(client.send)
-						delete(h.clients, client)
-						h.logger.Warn("Канал клиента переполнен, отключение",
-							zap.Int("threadID", client.threadID))
-					}
-				}
-			}
-			h.mu.Unlock()
+			h.broadcast(message.ThreadID, message)
+
+		case thread := <-h.state:
+			h.logger.Debug("Рассылка нового состояния треда",
+				zap.Int("threadID", thread.ID),
+				zap.Bool("pinned", thread.Pinned),
+				zap.Bool("locked", thread.Locked),
+				zap.Bool("archived", thread.Archived))
+			h.broadcast(thread.ID, ThreadStateEvent{
+				Type:   EventThreadState,
+				Thread: thread,
+			})
+		}
+	}
+}
+
+func (h *Hub) broadcast(threadID int, message any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		if client.threadID == threadID {
+			h.deliver(client, message)
 		}
 	}
 }
+
+// sendTo отправляет сообщение одному клиенту. Канал send закрывает хаб,
+// поэтому отправка идет под h.mu и только зарегистрированному клиенту:
+// после отключения клиента или остановки хаба сообщение отбрасывается.
+func (h *Hub) sendTo(client *Client, message any) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.clients[client] {
+		return false
+	}
+	return h.deliver(client, message)
+}
+
+// deliver кладет сообщение в канал клиента, не блокируясь. Клиент
+// с переполненным каналом отключается. Вызывается под h.mu.
+func (h *Hub) deliver(client *Client, message any) bool {
+	select {
+	case client.send <- message:
+		return true
+	default:
+		h.closeClient(client, websocket.ClosePolicyViolation, "send buffer overflow")
+		h.metrics.ClientDropped()
+		h.logger.Warn("Канал клиента переполнен, отключение",
+			zap.Int("threadID", client.threadID))
+		return false
+	}
+}
+
+// closeClient удаляет клиента из хаба и завершает его писателя. Ненулевой code
+// отправляется клиенту в close-кадре. Вызывается под h.mu.
+func (h *Hub) closeClient(client *Client, code int, reason string) {
+	client.closeCode = code
+	client.closeReason = reason
+	close(client.send)
+	delete(h.clients, client)
+
+	h.threadClients[client.threadID]--
+	count := h.threadClients[client.threadID]
+	if count == 0 {
+		delete(h.threadClients, client.threadID)
+	}
+	h.metrics.SetWSConnections(client.threadID, count)
+}
+
+func (h *Hub) shutdown() {
+	h.mu.Lock()
+	close(h.done)
+	count := len(h.clients)
+	for client := range h.clients {
+		h.closeClient(client, websocket.CloseGoingAway, "server shutdown")
+	}
+	h.mu.Unlock()
+
+	h.conns.Wait()
+	h.logger.Info("Хаб WebSocket остановлен", zap.Int("clients", count))
+}
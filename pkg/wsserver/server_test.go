@@ -0,0 +1,78 @@
+package wsserver
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+)
+
+// newTestClient регистрирует в хабе клиента без соединения. Писатель
+// заменен горутиной, которая вычитывает канал до его закрытия хабом.
+func newTestClient(t *testing.T, hub *Hub) *Client {
+	t.Helper()
+	client := &Client{send: make(chan any, 1), threadID: 1}
+	if !hub.registerClient(client) {
+		t.Fatal("хаб не принял клиента")
+	}
+	go func() {
+		defer hub.conns.Done()
+		for range client.send {
+		}
+	}()
+	return client
+}
+
+// sendConcurrently отправляет клиенту сообщения из нескольких горутин,
+// пока выполняется stop.
+func sendConcurrently(hub *Hub, client *Client, stop func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				hub.sendTo(client, j)
+			}
+		}()
+	}
+	stop()
+	wg.Wait()
+}
+
+func TestHub_SendToDuringUnregister(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	client := newTestClient(t, hub)
+	sendConcurrently(hub, client, func() { hub.unregisterClient(client) })
+
+	if hub.sendTo(client, "после отключения") {
+		t.Error("отключенному клиенту не должно отправляться сообщение")
+	}
+}
+
+func TestHub_SendToDuringShutdown(t *testing.T) {
+	hub := NewHub(nil, zap.NewNop())
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(stopped)
+	}()
+
+	client := newTestClient(t, hub)
+	sendConcurrently(hub, client, func() {
+		cancel()
+		<-stopped
+	})
+
+	if hub.sendTo(client, "после остановки") {
+		t.Error("после остановки хаба сообщение не должно отправляться")
+	}
+	if hub.registerClient(&Client{send: make(chan any, 1)}) {
+		t.Error("остановленный хаб не должен принимать клиентов")
+	}
+}